@@ -3,11 +3,17 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/cli"
+	"github.com/taninchot-work/backend-challenge/internal/core/eventsink"
+	"github.com/taninchot-work/backend-challenge/internal/core/migration"
 	"github.com/taninchot-work/backend-challenge/internal/repository"
 	"github.com/taninchot-work/backend-challenge/internal/service/background"
-	"log"
+	"io"
+	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -15,76 +21,385 @@ import (
 	"github.com/taninchot-work/backend-challenge/internal/controller"
 	"github.com/taninchot-work/backend-challenge/internal/core/config"
 	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/core/idempotency"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
 	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/openapi"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/graphqlserver"
+	"github.com/taninchot-work/backend-challenge/internal/grpcserver"
 	"github.com/taninchot-work/backend-challenge/internal/service"
 )
 
+var log = logger.New("main")
+
 func main() {
-	// initialize configuration
-	config.InitConfig("config.yaml")
+	// administrative commands like migrate up run instead of the server
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.New().Run(os.Args[1:]))
+	}
 
-	// load configuration
-	cfg := config.GetConfig()
+	// load configuration: defaults, config file, APP_* environment variables and flags
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		// the logger is not configured yet, every problem gets its own line
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	config.SetConfig(cfg)
 
-	// initialize db
-	err := db.InitializeMongoDB()
+	// initialize logger
+	if err := configureLogger(nil, cfg); err != nil {
+		fatal("failed to configure logger", err)
+	}
+	config.Subscribe("logger", configureLogger)
+	config.Subscribe("jwt", jwt.OnConfigReload)
+
+	// initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		File:        cfg.Tracing.File,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("failed to initialize database: %v", err)
+		fatal("failed to initialize tracing", err)
 	}
 
-	// initialize mux
-	mux := http.NewServeMux()
+	// initialize db
+	err = db.InitializeMongoDB()
+	if err != nil {
+		fatal("failed to initialize database", err)
+	}
+	if err := db.InitializeSQL(); err != nil {
+		fatal("failed to initialize user store", err)
+	}
 
 	// initialize repository
 	repositories := repository.NewRepository()
 
+	// apply the pending migrations, the other replicas wait for the first one
+	if err := migrate(cfg, repositories.LockRepository); err != nil {
+		fatal("failed to migrate database", err)
+	}
+
+	// initialize mux
+	mux := http.NewServeMux()
+
 	// initialize services
 	svc := service.NewService(repositories)
 
+	// tokens issued before a user's sessions were revoked are rejected, the
+	// revocation times are cached for restServer.jwt.revocationCacheTtl
+	jwt.SetRevocationCheck(svc.UserService.SessionsRevokedAt)
+
+	// initialize idempotency store
+	idempotencyStore, err := newIdempotencyStore(cfg.RestServer.Idempotency)
+	if err != nil {
+		fatal("failed to initialize idempotency store", err)
+	}
+	idempotent := middleware.IdempotencyMiddleware(idempotencyStore, middleware.IdempotencyOptions{
+		TTL:               time.Duration(cfg.RestServer.Idempotency.ExpireIn) * time.Millisecond,
+		LockTTL:           time.Duration(cfg.RestServer.Idempotency.LockTTL) * time.Millisecond,
+		TrustForwardedFor: cfg.RestServer.RateLimit.TrustForwardedFor,
+	})
+
 	// register routes
-	controller.RegisterRoutes(mux, svc)
+	if err := controller.RegisterRoutes(mux, svc, controller.RouteOptions{
+		Idempotent: idempotent,
+		GraphQLLimits: graphqlserver.Limits{
+			MaxDepth:      cfg.RestServer.GraphQL.MaxDepth,
+			MaxComplexity: cfg.RestServer.GraphQL.MaxComplexity,
+		},
+		EventHeartbeat: time.Duration(cfg.RestServer.Events.Heartbeat) * time.Millisecond,
+	}); err != nil {
+		fatal("failed to register routes", err)
+	}
 
 	// middlewares
 	var handler http.Handler = mux
-	handler = middleware.LoggingMiddleware(handler)
+	if cfg.RestServer.Validation.Requests || cfg.RestServer.Validation.Responses {
+		document, err := controller.OpenAPIDocument()
+		if err != nil {
+			fatal("failed to generate OpenAPI document", err)
+		}
+		handler = middleware.OpenAPIValidationMiddleware(openapi.NewValidator(document), middleware.OpenAPIValidationOptions{
+			ValidateRequests:  cfg.RestServer.Validation.Requests,
+			ValidateResponses: cfg.RestServer.Validation.Responses,
+		})(handler)
+	}
+	// the REST and gRPC servers share the limiter, a policy may list routes of both
+	var limiter *ratelimit.Limiter
+	if cfg.RestServer.RateLimit.Enabled {
+		limiter, err = newRateLimiter(cfg.RestServer.RateLimit)
+		if err != nil {
+			fatal("failed to initialize rate limiter", err)
+		}
+		config.Subscribe("rate limiter", func(_ *config.Config, current *config.Config) error {
+			return limiter.SetPolicies(rateLimitPolicies(current.RestServer.RateLimit))
+		})
+		handler = middleware.RateLimitMiddleware(limiter, middleware.RateLimitOptions{
+			TrustForwardedFor: cfg.RestServer.RateLimit.TrustForwardedFor,
+			APIKeys:           cfg.RestServer.RateLimit.APIKeys,
+		})(handler)
+	}
+	handler = middleware.LocaleMiddleware(handler)
 	handler = middleware.RecoveryMiddleware(handler)
+	handler = middleware.MetricsMiddleware(handler)
+	handler = middleware.LoggingMiddleware(handler)
+	handler = middleware.TracingMiddleware(handler)
+	handler = middleware.RequestIDMiddleware(handler)
 
 	// create configure http server
 	server := http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.RestServer.Port),
 		Handler: handler,
 	}
+	// Shutdown waits for open event streams, closing the broker ends them
+	server.RegisterOnShutdown(svc.EventBroker.Close)
 
 	// setup signal handling
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	// counting user
-	go background.StartUserCountLogger(ctx, 10*time.Second)
+	// reloading the configuration on SIGHUP and when the config file changes,
+	// SIGHUP is caught from here on so an early one does not kill the process
+	hangups, stopHangups := config.NotifyHangups()
+	defer stopHangups()
+	go config.Watch(ctx, os.Args[1:], hangups)
+
+	// counting registered users for the metrics
+	go background.StartRegisteredUsersGauge(ctx, repositories.UserRepository, time.Duration(cfg.Metrics.UserCountInterval)*time.Millisecond)
+
+	// a background worker that stops making progress fails liveness
+	workerTimeout := time.Duration(cfg.Health.WorkerTimeout) * time.Millisecond
+	if workerTimeout <= 0 {
+		workerTimeout = 5 * time.Minute
+	}
+
+	// publishing the user events of the outbox
+	var sinks []io.Closer
+	if cfg.Outbox.Enabled {
+		sinks, err = subscribeEventSinks(cfg.Outbox, svc.EventBus)
+		if err != nil {
+			fatal("failed to connect event sinks", err)
+		}
+		heartbeat := health.NewHeartbeat(workerTimeout)
+		svc.Health.Register(health.Liveness, "outbox_relay", heartbeat)
+		go background.StartOutboxRelay(ctx, svc.OutboxRelay, time.Duration(cfg.Outbox.PollInterval)*time.Millisecond, heartbeat)
+	}
+
+	// sending webhook deliveries
+	if cfg.Webhook.Enabled {
+		heartbeat := health.NewHeartbeat(workerTimeout)
+		svc.Health.Register(health.Liveness, "webhook_dispatcher", heartbeat)
+		go background.StartWebhookDispatcher(ctx, svc.WebhookService, time.Duration(cfg.Webhook.PollInterval)*time.Millisecond, heartbeat)
+	}
 
 	// start the server in a goroutine
 	go func() {
-		log.Printf("Starting REST Server on port %d\n", cfg.RestServer.Port)
-		log.Printf("Local : http://localhost:%d\n", cfg.RestServer.Port)
-		log.Println("waiting for request...")
+		log.Info("starting REST server", "port", cfg.RestServer.Port, "url", fmt.Sprintf("http://localhost:%d", cfg.RestServer.Port))
 
 		err := server.ListenAndServe()
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			fmt.Printf("failed to served %s\n", err)
+			log.Error("failed to serve REST", "error", err)
 			stop()
 		}
 	}()
 
+	// start the gRPC server next to the REST server
+	var grpcServer *grpcserver.Server
+	if cfg.GrpcServer.Enabled {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GrpcServer.Port))
+		if err != nil {
+			fatal("failed to listen for gRPC", err)
+		}
+		grpcServer = grpcserver.NewServer(svc, limiter)
+		go func() {
+			log.Info("starting gRPC server", "port", cfg.GrpcServer.Port)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Error("failed to serve gRPC", "error", err)
+				stop()
+			}
+		}()
+	}
+
 	// wait for the context to be canceled (i.e., SIGINT or SIGTERM)
 	<-ctx.Done()
-	log.Println("Shutting down server...")
+	log.Info("shutting down server")
+
+	// fail readiness first so load balancers stop sending traffic before the servers stop
+	svc.Health.Shutdown()
+	time.Sleep(time.Duration(cfg.Health.ShutdownDelay) * time.Millisecond)
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if grpcServer != nil {
+		grpcServer.Shutdown(shutdownCtx)
+	}
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		fmt.Printf("Server shutdown failed: %v\n", err)
+		log.Error("server shutdown failed", "error", err)
+	}
+
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			log.Error("failed to close event sink", "error", err)
+		}
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error("failed to flush traces", "error", err)
+	}
+
+	log.Info("server gracefully stopped")
+}
+
+// fatal logs the error and exits, deferred functions do not run.
+func fatal(msg string, err error) {
+	log.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// subscribeEventSinks subscribes the enabled external brokers to bus and returns
+// them to be closed on shutdown.
+func subscribeEventSinks(cfg config.OutboxConfig, bus *service.EventBus) ([]io.Closer, error) {
+	var sinks []io.Closer
+	if cfg.NATS.Enabled {
+		publisher, err := eventsink.NewNATSPublisher(cfg.NATS.URL, eventsink.NATSOptions{
+			SubjectPrefix: cfg.NATS.SubjectPrefix,
+			JetStream:     cfg.NATS.JetStream,
+			Timeout:       time.Duration(cfg.NATS.Timeout) * time.Millisecond,
+		})
+		if err != nil {
+			return nil, err
+		}
+		bus.Subscribe("nats", publisher)
+		sinks = append(sinks, publisher)
+	}
+	if cfg.Kafka.Enabled {
+		writer := eventsink.NewKafkaWriter(cfg.Kafka.Brokers, cfg.Kafka.Topic, time.Duration(cfg.Kafka.Timeout)*time.Millisecond)
+		publisher := eventsink.NewKafkaPublisher(writer)
+		bus.Subscribe("kafka", publisher)
+		sinks = append(sinks, publisher)
+	}
+	return sinks, nil
+}
+
+// migrate applies the pending migrations of MongoDB and of a SQL user store
+// when they are applied on start, otherwise it warns about them.
+func migrate(cfg *config.Config, locker migration.Locker) error {
+	migrator, err := migration.New(db.GetDatabase(), locker, migration.Options{
+		LockTTL: time.Duration(cfg.Migrations.LockTTL) * time.Millisecond,
+	})
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if !cfg.Migrations.Auto {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			log.Warn("database has pending migrations, run migrate up", "pending", len(pending))
+		}
+	} else {
+		applied, err := migrator.Up(ctx, 0)
+		if len(applied) > 0 {
+			log.Info("applied migrations", "count", len(applied), "version", applied[len(applied)-1].Version)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if db.GetSQL() == nil {
+		return nil
+	}
+	sqlMigrator, err := migration.NewSQLMigrator(db.GetSQL(), cfg.UserStore.Backend, nil)
+	if err != nil {
+		return err
 	}
+	if !cfg.Migrations.Auto {
+		pending, err := sqlMigrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			log.Warn("user store has pending migrations, run migrate up -store users", "pending", len(pending), "backend", cfg.UserStore.Backend)
+		}
+		return nil
+	}
+	applied, err := sqlMigrator.Up(ctx, 0)
+	if len(applied) > 0 {
+		log.Info("applied user store migrations", "count", len(applied), "version", applied[len(applied)-1].Version, "backend", cfg.UserStore.Backend)
+	}
+	return err
+}
+
+func newIdempotencyStore(cfg config.IdempotencyConfig) (idempotency.Store, error) {
+	switch cfg.Store {
+	case "", "memory":
+		return idempotency.NewMemoryStore(), nil
+	case "mongo":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return idempotency.NewMongoStore(ctx, db.GetDatabase().Collection("idempotency_keys"))
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q", cfg.Store)
+	}
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) (*ratelimit.Limiter, error) {
+	var store ratelimit.Store
+	switch cfg.Store {
+	case "", "memory":
+		store = ratelimit.NewMemoryStore()
+	case "mongo":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var err error
+		store, err = ratelimit.NewMongoStore(ctx, db.GetDatabase().Collection("rate_limits"))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.Store)
+	}
+	return ratelimit.NewLimiter(store, rateLimitPolicies(cfg))
+}
+
+func rateLimitPolicies(cfg config.RateLimitConfig) []ratelimit.Policy {
+	policies := make([]ratelimit.Policy, 0, len(cfg.Policies))
+	for _, policy := range cfg.Policies {
+		policies = append(policies, ratelimit.Policy{
+			Name:      policy.Name,
+			Routes:    policy.Routes,
+			Algorithm: ratelimit.Algorithm(policy.Algorithm),
+			Limit:     policy.Limit,
+			Burst:     policy.Burst,
+			Window:    time.Duration(policy.Window) * time.Millisecond,
+			KeyBy:     ratelimit.KeyBy(policy.KeyBy),
+		})
+	}
+	return policies
+}
 
-	log.Println("Server gracefully stopped")
+// configureLogger configures the logger from the log settings, it is also the
+// config.Subscriber of the logger.
+func configureLogger(_ *config.Config, cfg *config.Config) error {
+	return logger.Configure(logger.Options{
+		Format:     cfg.Log.Format,
+		Level:      cfg.Log.Level,
+		Packages:   cfg.Log.Packages,
+		RedactKeys: cfg.Log.Redact,
+	}, os.Stdout)
 }
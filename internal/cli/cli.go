@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+)
+
+// Exit codes of the commands, scripts tell the failures apart by them.
+const (
+	ExitOK       = 0
+	ExitFailure  = 1 // the command failed, e.g. the database is unreachable
+	ExitUsage    = 2 // unknown command or flag, or an invalid configuration
+	ExitInvalid  = 3 // the input was rejected, e.g. a short password
+	ExitNotFound = 4 // the user does not exist
+	ExitConflict = 5 // e.g. the email is already registered
+)
+
+// Output formats of the commands.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// CLI runs the administrative commands.
+type CLI struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Connect opens the database of cfg and returns the repositories and the
+	// services on it.
+	Connect func(cfg *config.Config) (*repository.Repository, *service.Service, error)
+
+	// output is the format of the running command
+	output string
+}
+
+type group struct {
+	name     string
+	summary  string
+	commands []command
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(c *CLI, ctx context.Context, args []string) error
+}
+
+var groups = []group{
+	{name: "user", summary: "manage users", commands: []command{
+		{name: "create-admin", summary: "create a user with the admin role", run: (*CLI).userCreateAdmin},
+		{name: "reset-password", summary: "replace the password of a user and revoke the user's sessions", run: (*CLI).userResetPassword},
+		{name: "revoke-sessions", summary: "invalidate the access tokens of a user", run: (*CLI).userRevokeSessions},
+		{name: "list", summary: "list and search users", run: (*CLI).userList},
+		{name: "export", summary: "write every user as JSON lines", run: (*CLI).userExport},
+		{name: "import", summary: "read users from JSON lines written by export", run: (*CLI).userImport},
+	}},
+	{name: "migrate", summary: "apply and revert database migrations", commands: []command{
+		{name: "up", summary: "apply the pending migrations", run: (*CLI).migrateUp},
+		{name: "down", summary: "revert the last applied migrations", run: (*CLI).migrateDown},
+		{name: "status", summary: "list the migrations and when they were applied", run: (*CLI).migrateStatus},
+	}},
+	{name: "config", summary: "inspect the configuration", commands: []command{
+		{name: "check", summary: "load and validate the configuration", run: (*CLI).configCheck},
+	}},
+	{name: "jwt", summary: "manage the token signing keys", commands: []command{
+		{name: "keygen", summary: "generate a key pair or a secret", run: (*CLI).jwtKeygen},
+	}},
+}
+
+// New returns a CLI on the standard streams that connects to MongoDB and the
+// configured user store.
+func New() *CLI {
+	return &CLI{
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		Stderr:  os.Stderr,
+		Connect: connect,
+	}
+}
+
+// IsCommand tells whether name is a command of the CLI, the server runs
+// without one.
+func IsCommand(name string) bool {
+	return name == "help" || slices.ContainsFunc(groups, func(g group) bool { return g.name == name })
+}
+
+// Run runs the command of args, like user list -output json, and returns its
+// exit code.
+func (c *CLI) Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		c.usage()
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+	index := slices.IndexFunc(groups, func(g group) bool { return g.name == args[0] })
+	if index < 0 {
+		fmt.Fprintf(c.Stderr, "unknown command %q\n", args[0])
+		c.usage()
+		return ExitUsage
+	}
+	g := groups[index]
+	if len(args) < 2 || !slices.ContainsFunc(g.commands, func(cmd command) bool { return cmd.name == args[1] }) {
+		if len(args) >= 2 && args[1] != "help" && args[1] != "-h" && args[1] != "-help" {
+			fmt.Fprintf(c.Stderr, "unknown command %q\n", g.name+" "+args[1])
+		}
+		c.groupUsage(g)
+		return ExitUsage
+	}
+	cmd := g.commands[slices.IndexFunc(g.commands, func(cmd command) bool { return cmd.name == args[1] })]
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	c.output = OutputTable
+	err := cmd.run(c, ctx, args[2:])
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		c.printError(err)
+	}
+	return exitCode(err)
+}
+
+func (c *CLI) usage() {
+	w := tabwriter.NewWriter(c.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "usage: backend-challenge <command> <subcommand> [flags]")
+	fmt.Fprintln(w, "\nwithout a command the server starts, the commands are:")
+	for _, g := range groups {
+		for _, cmd := range g.commands {
+			fmt.Fprintf(w, "  %s %s\t%s\n", g.name, cmd.name, cmd.summary)
+		}
+	}
+	fmt.Fprintln(w, "\nrun a command with -h for its flags")
+	_ = w.Flush()
+}
+
+func (c *CLI) groupUsage(g group) {
+	w := tabwriter.NewWriter(c.Stderr, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "usage: backend-challenge %s <subcommand> [flags]\n\n%s:\n", g.name, g.summary)
+	for _, cmd := range g.commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	_ = w.Flush()
+}
+
+// usageError is a problem with the command line or the configuration.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the exit code of the error of a command.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.As(err, new(usageError)) {
+		return ExitUsage
+	}
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		switch appErr.Kind {
+		case apperror.KindValidation:
+			return ExitInvalid
+		case apperror.KindNotFound:
+			return ExitNotFound
+		case apperror.KindConflict:
+			return ExitConflict
+		}
+	}
+	return ExitFailure
+}
+
+// printError writes err to Stderr in the output format.
+func (c *CLI) printError(err error) {
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		appErr = &apperror.Error{}
+	}
+	// a wrapped error tells where it happened, like the line of an import
+	message := err.Error()
+	if err == error(appErr) {
+		message = appErr.Message
+	}
+	if c.output == OutputJSON {
+		type problem struct {
+			Code    string                `json:"code,omitempty"`
+			Message string                `json:"message"`
+			Fields  []apperror.FieldError `json:"fields,omitempty"`
+		}
+		_ = json.NewEncoder(c.Stderr).Encode(map[string]problem{
+			"error": {Code: appErr.Code, Message: message, Fields: appErr.Fields},
+		})
+		return
+	}
+	fmt.Fprintln(c.Stderr, "error:", message)
+	for _, field := range appErr.Fields {
+		fmt.Fprintf(c.Stderr, "  %s: %s\n", field.Field, field.Message)
+	}
+}
+
+// flags are the flags every command has.
+type flags struct {
+	*flag.FlagSet
+	config *config.Flags
+	output string
+}
+
+// newFlags returns the flags of the command name, with -config and -set when
+// the command loads the configuration.
+func (c *CLI) newFlags(name string, loadsConfig bool) *flags {
+	f := &flags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.SetOutput(c.Stderr)
+	if loadsConfig {
+		f.config = config.AddFlags(f.FlagSet)
+	}
+	f.StringVar(&f.output, "output", OutputTable, "output format, table or json")
+	return f
+}
+
+// parse parses args and sets the output format of c.
+func (c *CLI) parse(f *flags, args []string) error {
+	if err := f.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	if f.output != OutputTable && f.output != OutputJSON {
+		return usageError{fmt.Errorf("-output must be table or json, got %q", f.output)}
+	}
+	c.output = f.output
+	if f.NArg() > 0 {
+		return usageError{fmt.Errorf("unexpected argument %q", f.Arg(0))}
+	}
+	return nil
+}
+
+// load loads the configuration of f and configures the logger to write to
+// Stderr, so Stdout has only the output of the command.
+func (c *CLI) load(f *flags) (*config.Config, error) {
+	cfg, err := config.Load(f.config.Args())
+	if err != nil {
+		return nil, usageError{err}
+	}
+	config.SetConfig(cfg)
+	err = logger.Configure(logger.Options{
+		Format:     cfg.Log.Format,
+		Level:      cfg.Log.Level,
+		Packages:   cfg.Log.Packages,
+		RedactKeys: cfg.Log.Redact,
+	}, c.Stderr)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	return cfg, nil
+}
+
+// connect loads the configuration of f and connects to its database.
+func (c *CLI) connect(f *flags) (*repository.Repository, *service.Service, error) {
+	cfg, err := c.load(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.Connect(cfg)
+}
+
+func connect(*config.Config) (*repository.Repository, *service.Service, error) {
+	if err := db.InitializeMongoDB(); err != nil {
+		return nil, nil, err
+	}
+	if err := db.InitializeSQL(); err != nil {
+		return nil, nil, err
+	}
+	repositories := repository.NewRepository()
+	return repositories, service.NewService(repositories), nil
+}
+
+// print writes value as JSON, or as the table that table writes.
+func (c *CLI) print(value interface{}, table func(w io.Writer)) error {
+	if c.output == OutputJSON {
+		encoder := json.NewEncoder(c.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	w := tabwriter.NewWriter(c.Stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// readLine returns the first line of Stdin, for secrets that must not be
+// flags.
+func (c *CLI) readLine() (string, error) {
+	var line strings.Builder
+	buffer := make([]byte, 1)
+	for {
+		n, err := c.Stdin.Read(buffer)
+		if n > 0 {
+			if buffer[0] == '\n' {
+				break
+			}
+			line.WriteByte(buffer[0])
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(line.String(), "\r"), nil
+}
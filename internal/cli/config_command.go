@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"io"
+	"strings"
+)
+
+var errConfigInvalid = errors.New("configuration is invalid")
+
+func (c *CLI) configCheck(_ context.Context, args []string) error {
+	f := c.newFlags("config check", true)
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	type checkOutput struct {
+		Valid    bool     `json:"valid"`
+		Problems []string `json:"problems,omitempty"`
+	}
+	_, err := config.Load(f.config.Args())
+	if err == nil {
+		return c.print(checkOutput{Valid: true}, func(w io.Writer) {
+			fmt.Fprintln(w, "configuration is valid")
+		})
+	}
+
+	// Load lists every problem on its own line under a title
+	lines := strings.Split(err.Error(), "\n")
+	output := checkOutput{Problems: lines}
+	if len(lines) > 1 {
+		output.Problems = nil
+		for _, line := range lines[1:] {
+			output.Problems = append(output.Problems, strings.TrimPrefix(line, "  - "))
+		}
+	}
+	if printErr := c.print(output, func(w io.Writer) {
+		fmt.Fprintln(w, err)
+	}); printErr != nil {
+		return printErr
+	}
+	// the problems are the output, the exit code tells they were found
+	return usageError{errConfigInvalid}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/keypair"
+	"io"
+	"os"
+	"slices"
+)
+
+// secretLength is the length in bytes of a generated HS256 secret.
+const secretLength = 32
+
+func (c *CLI) jwtKeygen(_ context.Context, args []string) error {
+	f := c.newFlags("jwt keygen", false)
+	algorithm := f.String("algorithm", keypair.EdDSA, fmt.Sprintf("one of %v, or %s for a secret", keypair.Algorithms, jwt.AlgorithmHS256))
+	out := f.String("out", "jwt", "the key pair is written to this path with .key and .pub appended")
+	force := f.Bool("force", false, "overwrite existing key files")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	if *algorithm == jwt.AlgorithmHS256 {
+		secret := make([]byte, secretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		type secretOutput struct {
+			Algorithm string `json:"algorithm"`
+			Secret    string `json:"secret"`
+		}
+		output := secretOutput{Algorithm: *algorithm, Secret: hex.EncodeToString(secret)}
+		return c.print(output, func(w io.Writer) {
+			fmt.Fprintln(w, output.Secret)
+		})
+	}
+	if !slices.Contains(keypair.Algorithms, *algorithm) {
+		return usageError{fmt.Errorf("-algorithm must be one of %v or %s, got %q", keypair.Algorithms, jwt.AlgorithmHS256, *algorithm)}
+	}
+
+	privatePEM, publicPEM, err := keypair.Generate(*algorithm)
+	if err != nil {
+		return err
+	}
+	privatePath, publicPath := *out+".key", *out+".pub"
+	if err := writeKey(privatePath, privatePEM, 0o600, *force); err != nil {
+		return err
+	}
+	if err := writeKey(publicPath, publicPEM, 0o644, *force); err != nil {
+		return err
+	}
+
+	type keyOutput struct {
+		Algorithm  string `json:"algorithm"`
+		PrivateKey string `json:"privateKey"`
+		PublicKey  string `json:"publicKey"`
+	}
+	output := keyOutput{Algorithm: *algorithm, PrivateKey: privatePath, PublicKey: publicPath}
+	return c.print(output, func(w io.Writer) {
+		fmt.Fprintf(w, "wrote %s and %s, sign the tokens with them by setting\n\n", privatePath, publicPath)
+		fmt.Fprintf(w, "  APP_REST_SERVER_JWT_ALGORITHM=%s\n", *algorithm)
+		fmt.Fprintf(w, "  APP_REST_SERVER_JWT_PRIVATE_KEY_FILE=%s\n", privatePath)
+		fmt.Fprintf(w, "  APP_REST_SERVER_JWT_PUBLIC_KEY_FILE=%s\n", publicPath)
+	})
+}
+
+// writeKey writes data to path, an existing file is only replaced when force
+// is set.
+func writeKey(path string, data []byte, perm os.FileMode, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	file, err := os.OpenFile(path, flags, perm)
+	if errors.Is(err, os.ErrExist) {
+		return usageError{fmt.Errorf("%s exists, set -force to replace it", path)}
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/migration"
+	"io"
+	"strings"
+	"time"
+)
+
+// migrationOutput is a migration applied or reverted by a command.
+type migrationOutput struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+// changeOutput is the result of migrate up and down, Plan is what a dry run
+// would change.
+type changeOutput struct {
+	DryRun     bool              `json:"dryRun"`
+	Migrations []migrationOutput `json:"migrations"`
+	Plan       []string          `json:"plan,omitempty"`
+}
+
+func (c *CLI) migrateUp(ctx context.Context, args []string) error {
+	f := c.newFlags("migrate up", true)
+	store := storeFlag(f)
+	dryRun := f.Bool("dry-run", false, "print what would change without changing it")
+	target := f.Int("to", 0, "apply the migrations up to this version, all of them by default")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+	return c.migrate(ctx, f, *store, *dryRun, "applied", func(migrator migrator) ([]migrationOutput, error) {
+		return migrator.up(ctx, *target)
+	})
+}
+
+func (c *CLI) migrateDown(ctx context.Context, args []string) error {
+	f := c.newFlags("migrate down", true)
+	store := storeFlag(f)
+	dryRun := f.Bool("dry-run", false, "print what would change without changing it")
+	steps := f.Int("steps", 1, "the number of migrations to revert")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+	if *steps <= 0 {
+		return usageError{fmt.Errorf("-steps must be positive, got %d", *steps)}
+	}
+	return c.migrate(ctx, f, *store, *dryRun, "reverted", func(migrator migrator) ([]migrationOutput, error) {
+		return migrator.down(ctx, *steps)
+	})
+}
+
+func (c *CLI) migrateStatus(ctx context.Context, args []string) error {
+	f := c.newFlags("migrate status", true)
+	store := storeFlag(f)
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+	migrator, err := c.migrator(f, *store, nil)
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.status(ctx)
+	if err != nil {
+		return err
+	}
+	if statuses == nil {
+		statuses = []migration.Status{}
+	}
+	return c.print(statuses, func(w io.Writer) {
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			if status.Unknown {
+				appliedAt += " (not in this build)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+	})
+}
+
+// migrate runs change, migrate up or down, and prints the migrations it
+// changed as action.
+func (c *CLI) migrate(ctx context.Context, f *flags, store string, dryRun bool, action string, change func(migrator) ([]migrationOutput, error)) error {
+	var plan *bytes.Buffer
+	var out io.Writer
+	if dryRun {
+		plan = &bytes.Buffer{}
+		out = plan
+	}
+	migrator, err := c.migrator(f, store, out)
+	if err != nil {
+		return err
+	}
+	migrations, err := change(migrator)
+	// the migrations applied before a failure are reported with it
+	output := changeOutput{DryRun: dryRun, Migrations: append([]migrationOutput{}, migrations...)}
+	if dryRun {
+		output.Plan = strings.Split(strings.TrimSuffix(plan.String(), "\n"), "\n")
+		if plan.Len() == 0 {
+			output.Plan = nil
+		}
+	}
+	if printErr := c.print(output, func(w io.Writer) {
+		if dryRun {
+			fmt.Fprintln(w, "dry run, the database is not changed")
+			fmt.Fprint(w, plan.String())
+		} else {
+			for _, m := range output.Migrations {
+				fmt.Fprintf(w, "%s %d %s\n", action, m.Version, m.Name)
+			}
+		}
+		if len(output.Migrations) == 0 && err == nil {
+			fmt.Fprintf(w, "no migrations to be %s\n", action)
+		}
+	}); printErr != nil && err == nil {
+		return printErr
+	}
+	return err
+}
+
+// Stores the migrate commands migrate.
+const (
+	storeMongo = "mongo"
+	storeUsers = "users"
+)
+
+func storeFlag(f *flags) *string {
+	return f.String("store", storeMongo, "mongo for the MongoDB collections, users for the tables of a PostgreSQL or SQLite user store")
+}
+
+// migrator is the Migrator of MongoDB or the SQLMigrator of the user store.
+type migrator interface {
+	status(ctx context.Context) ([]migration.Status, error)
+	up(ctx context.Context, target int) ([]migrationOutput, error)
+	down(ctx context.Context, steps int) ([]migrationOutput, error)
+}
+
+// migrator connects to the databases of f and returns the migrator of store,
+// dryRun receives the plan of a dry run.
+func (c *CLI) migrator(f *flags, store string, dryRun io.Writer) (migrator, error) {
+	if store != storeMongo && store != storeUsers {
+		return nil, usageError{fmt.Errorf("-store must be %s or %s, got %q", storeMongo, storeUsers, store)}
+	}
+	repositories, _, err := c.connect(f)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.GetConfig()
+	if store == storeUsers {
+		if db.GetSQL() == nil {
+			return nil, usageError{fmt.Errorf("the users are in MongoDB, -store users needs userStore.backend %s or %s", config.UserStorePostgres, config.UserStoreSQLite)}
+		}
+		sqlMigrator, err := migration.NewSQLMigrator(db.GetSQL(), cfg.UserStore.Backend, dryRun)
+		return sqlMigrations{sqlMigrator}, err
+	}
+	mongoMigrator, err := migration.New(db.GetDatabase(), repositories.LockRepository, migration.Options{
+		LockTTL: time.Duration(cfg.Migrations.LockTTL) * time.Millisecond,
+		DryRun:  dryRun,
+	})
+	return mongoMigrations{mongoMigrator}, err
+}
+
+// mongoMigrations and sqlMigrations are the migrator of MongoDB and of the
+// user store.
+type mongoMigrations struct {
+	*migration.Migrator
+}
+
+func (m mongoMigrations) status(ctx context.Context) ([]migration.Status, error) {
+	return m.Status(ctx)
+}
+
+func (m mongoMigrations) up(ctx context.Context, target int) ([]migrationOutput, error) {
+	migrations, err := m.Up(ctx, target)
+	return outputOf(migrations, func(m migration.Migration) migrationOutput {
+		return migrationOutput{Version: m.Version, Name: m.Name}
+	}), err
+}
+
+func (m mongoMigrations) down(ctx context.Context, steps int) ([]migrationOutput, error) {
+	migrations, err := m.Down(ctx, steps)
+	return outputOf(migrations, func(m migration.Migration) migrationOutput {
+		return migrationOutput{Version: m.Version, Name: m.Name}
+	}), err
+}
+
+type sqlMigrations struct {
+	*migration.SQLMigrator
+}
+
+func (m sqlMigrations) status(ctx context.Context) ([]migration.Status, error) {
+	return m.Status(ctx)
+}
+
+func (m sqlMigrations) up(ctx context.Context, target int) ([]migrationOutput, error) {
+	migrations, err := m.Up(ctx, target)
+	return outputOf(migrations, func(m migration.SQLMigration) migrationOutput {
+		return migrationOutput{Version: m.Version, Name: m.Name}
+	}), err
+}
+
+func (m sqlMigrations) down(ctx context.Context, steps int) ([]migrationOutput, error) {
+	migrations, err := m.Down(ctx, steps)
+	return outputOf(migrations, func(m migration.SQLMigration) migrationOutput {
+		return migrationOutput{Version: m.Version, Name: m.Name}
+	}), err
+}
+
+// outputOf returns the output of each of migrations.
+func outputOf[T any](migrations []T, output func(T) migrationOutput) []migrationOutput {
+	outputs := make([]migrationOutput, 0, len(migrations))
+	for _, m := range migrations {
+		outputs = append(outputs, output(m))
+	}
+	return outputs
+}
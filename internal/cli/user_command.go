@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"io"
+	"os"
+	"strings"
+)
+
+// Password lengths the CLI accepts, the same as the register endpoint.
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 50
+)
+
+// userOutput is a user changed by a command, Password is only set when the
+// command generated it.
+type userOutput struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func (c *CLI) userCreateAdmin(ctx context.Context, args []string) error {
+	f := c.newFlags("user create-admin", true)
+	name := f.String("name", "", "name of the admin")
+	email := f.String("email", "", "email of the admin")
+	locale := f.String("locale", "", "preferred locale of the admin")
+	passwordStdin := f.Bool("password-stdin", false, "read the password from the first line of stdin, a random password is printed otherwise")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+	password, generated, err := c.password(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	req := dto.UserRegisterRequest{Name: *name, Email: strings.ToLower(*email), Password: password, Locale: *locale}
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	admin, err := svc.UserService.CreateAdmin(ctx, req)
+	if err != nil {
+		return err
+	}
+	output := userOutput{ID: admin.ID, Name: admin.Name, Email: admin.Email}
+	if generated {
+		output.Password = password
+	}
+	return c.print(output, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tEMAIL")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", output.ID, output.Name, output.Email)
+		if generated {
+			fmt.Fprintf(w, "\npassword: %s\n", password)
+		}
+	})
+}
+
+func (c *CLI) userResetPassword(ctx context.Context, args []string) error {
+	f := c.newFlags("user reset-password", true)
+	id := f.String("id", "", "id of the user")
+	email := f.String("email", "", "email of the user, instead of -id")
+	passwordStdin := f.Bool("password-stdin", false, "read the password from the first line of stdin, a random password is printed otherwise")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+	password, generated, err := c.password(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	userID, err := findUser(ctx, svc, *id, *email)
+	if err != nil {
+		return err
+	}
+	if err := svc.UserService.ResetPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	output := userOutput{ID: userID}
+	if generated {
+		output.Password = password
+	}
+	return c.print(output, func(w io.Writer) {
+		fmt.Fprintf(w, "password of user %s reset, the user's sessions are revoked\n", userID)
+		if generated {
+			fmt.Fprintf(w, "\npassword: %s\n", password)
+		}
+	})
+}
+
+func (c *CLI) userRevokeSessions(ctx context.Context, args []string) error {
+	f := c.newFlags("user revoke-sessions", true)
+	id := f.String("id", "", "id of the user")
+	email := f.String("email", "", "email of the user, instead of -id")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	userID, err := findUser(ctx, svc, *id, *email)
+	if err != nil {
+		return err
+	}
+	if err := svc.UserService.RevokeSessions(ctx, userID); err != nil {
+		return err
+	}
+	return c.print(userOutput{ID: userID}, func(w io.Writer) {
+		fmt.Fprintf(w, "sessions of user %s revoked\n", userID)
+	})
+}
+
+func (c *CLI) userList(ctx context.Context, args []string) error {
+	f := c.newFlags("user list", true)
+	name := f.String("name", "", "list the users whose name contains this, ignoring case")
+	email := f.String("email", "", "list the user with this email")
+	limit := f.Int("limit", 20, "the most users listed, up to 100")
+	after := f.String("after", "", "list the users after this cursor of the previous page")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	connection, err := svc.UserService.GetUserConnection(ctx, dto.UserConnectionRequest{
+		First:  *limit,
+		After:  *after,
+		Filter: dto.UserListFilter{Name: *name, Email: *email},
+	})
+	if err != nil {
+		return err
+	}
+	type listOutput struct {
+		Users      []dto.UserListGetResponseItem `json:"users"`
+		NextCursor string                        `json:"nextCursor,omitempty"`
+	}
+	output := listOutput{Users: []dto.UserListGetResponseItem{}}
+	for _, edge := range connection.Edges {
+		output.Users = append(output.Users, edge.Node)
+	}
+	if connection.PageInfo.HasNextPage {
+		output.NextCursor = connection.PageInfo.EndCursor
+	}
+	return c.print(output, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tEMAIL")
+		for _, user := range output.Users {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", user.ID, user.Name, user.Email)
+		}
+		if output.NextCursor != "" {
+			fmt.Fprintf(w, "\nmore users with -after %s\n", output.NextCursor)
+		}
+	})
+}
+
+func (c *CLI) userExport(ctx context.Context, args []string) error {
+	f := c.newFlags("user export", true)
+	file := f.String("file", "", "file to write, stdout by default")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	out := c.Stdout
+	if *file != "" {
+		// the records have the password hashes
+		exportFile, err := os.OpenFile(*file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer exportFile.Close()
+		out = exportFile
+	}
+	writer := bufio.NewWriter(out)
+	encoder := json.NewEncoder(writer)
+	exported := 0
+	err = svc.UserService.ExportUsers(ctx, func(record dto.UserRecord) error {
+		exported++
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Stderr, "exported %d users\n", exported)
+	return nil
+}
+
+func (c *CLI) userImport(ctx context.Context, args []string) error {
+	f := c.newFlags("user import", true)
+	file := f.String("file", "", "file to read, stdin by default")
+	skipExisting := f.Bool("skip-existing", false, "skip the users whose id or email exists instead of stopping")
+	if err := c.parse(f, args); err != nil {
+		return err
+	}
+
+	_, svc, err := c.connect(f)
+	if err != nil {
+		return err
+	}
+	in := c.Stdin
+	if *file != "" {
+		importFile, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer importFile.Close()
+		in = importFile
+	}
+
+	type importOutput struct {
+		Imported int `json:"imported"`
+		Skipped  int `json:"skipped"`
+	}
+	var output importOutput
+	scanner := bufio.NewScanner(in)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record dto.UserRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return apperror.Validation(apperror.CodeValidationFailed, fmt.Sprintf("line %d is not a user record: %v", line, err))
+		}
+		err := svc.UserService.ImportUser(ctx, record)
+		var appErr *apperror.Error
+		if *skipExisting && errors.As(err, &appErr) && appErr.Kind == apperror.KindConflict {
+			output.Skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		output.Imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return c.print(output, func(w io.Writer) {
+		fmt.Fprintf(w, "imported %d users, skipped %d\n", output.Imported, output.Skipped)
+	})
+}
+
+// password returns the password of the first line of Stdin, or a random one
+// and true.
+func (c *CLI) password(fromStdin bool) (string, bool, error) {
+	if !fromStdin {
+		random := make([]byte, 18)
+		if _, err := rand.Read(random); err != nil {
+			return "", false, err
+		}
+		return base64.RawURLEncoding.EncodeToString(random), true, nil
+	}
+	password, err := c.readLine()
+	if err != nil {
+		return "", false, err
+	}
+	if len(password) < minPasswordLength {
+		return "", false, apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+			Field:   "password",
+			Code:    apperror.CodeFieldTooShort,
+			Message: fmt.Sprintf("password must be at least %d characters long", minPasswordLength),
+		})
+	}
+	if len(password) > maxPasswordLength {
+		return "", false, apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+			Field:   "password",
+			Code:    apperror.CodeFieldTooLong,
+			Message: fmt.Sprintf("password must be at most %d characters long", maxPasswordLength),
+		})
+	}
+	return password, false, nil
+}
+
+// findUser returns id, or the id of the user with email.
+func findUser(ctx context.Context, svc *service.Service, id string, email string) (string, error) {
+	switch {
+	case id != "" && email != "":
+		return "", usageError{errors.New("set -id or -email, not both")}
+	case id != "":
+		return id, nil
+	case email == "":
+		return "", usageError{errors.New("set -id or -email")}
+	}
+	connection, err := svc.UserService.GetUserConnection(ctx, dto.UserConnectionRequest{
+		First:  1,
+		Filter: dto.UserListFilter{Email: email},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(connection.Edges) == 0 {
+		return "", apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with email %s not found", email))
+	}
+	return connection.Edges[0].Node.ID, nil
+}
@@ -1,5 +1,9 @@
 package constant
 
 const (
-	CONTEXT_KEY_USER_ID = "user_id"
+	CONTEXT_KEY_USER_ID     = "user_id"
+	CONTEXT_KEY_REQUEST_ID  = "request_id"
+	CONTEXT_KEY_LOCALE      = "locale"
+	CONTEXT_KEY_USER_ROLE   = "user_role"
+	CONTEXT_KEY_USER_LOADER = "user_loader"
 )
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"net/http"
+)
+
+type BatchController interface {
+	Batch(w http.ResponseWriter, r *http.Request)
+}
+
+type batchControllerImpl struct {
+	batchService service.BatchService
+}
+
+func NewBatchController(batchService service.BatchService) BatchController {
+	return &batchControllerImpl{
+		batchService: batchService,
+	}
+}
+
+// Batch runs the operations of the request and responds 200 with the status
+// and body or problem of every operation, the status a single request for the
+// operation would get.
+func (c batchControllerImpl) Batch(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchRequest
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	result, err := c.batchService.ExecuteBatch(r.Context(), req)
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response := dto.BatchResponse{
+		RolledBack: result.RolledBack,
+		Results:    make([]dto.BatchResponseItem, len(result.Results)),
+	}
+	for i, operation := range result.Results {
+		item := dto.BatchResponseItem{ID: operation.ID, Status: batchStatus(req.Operations[i].Method), User: operation.User}
+		if operation.Err != nil {
+			problem := json.NewProblem(r, operation.Err)
+			item = dto.BatchResponseItem{ID: operation.ID, Status: problem.Status, Error: &problem}
+		} else if operation.User != nil {
+			item.ETag = representationTag(r, operation.User.Version)
+		}
+		response.Results[i] = item
+	}
+	codec.ResponseWithSuccess(w, r, response)
+}
+
+// batchStatus returns the status of a successful operation with method.
+func batchStatus(method string) int {
+	switch method {
+	case dto.BatchMethodCreate:
+		return http.StatusCreated
+	case dto.BatchMethodDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
@@ -1,23 +1,107 @@
 package controller
 
 import (
+	encodingjson "encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
 	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/graphqlserver"
 	"github.com/taninchot-work/backend-challenge/internal/service"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"time"
 )
 
-func RegisterRoutes(mux *http.ServeMux, svc *service.Service) {
+// Router is the part of *http.ServeMux that RegisterRoutes uses.
+type Router interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// RouteOptions configures the routes registered by RegisterRoutes.
+type RouteOptions struct {
+	// Idempotent wraps the mutating handlers that accept an Idempotency-Key.
+	Idempotent    func(next http.HandlerFunc) http.HandlerFunc
+	GraphQLLimits graphqlserver.Limits
+	// EventHeartbeat is the interval of the keep-alives sent on idle event streams.
+	EventHeartbeat time.Duration
+}
+
+// RegisterRoutes registers every route on mux. New routes must also be documented
+// in openAPIRoutes.
+func RegisterRoutes(mux Router, svc *service.Service, options RouteOptions) error {
+	document, err := OpenAPIDocument()
+	if err != nil {
+		return err
+	}
+	spec, err := encodingjson.Marshal(document)
+	if err != nil {
+		return err
+	}
+
 	serverController := NewServerController(svc.ServerService)
 	userController := NewUserController(svc.UserService)
+	docsController := NewDocsController(spec)
+	graphQLServer, err := graphqlserver.NewServer(svc.UserService, options.GraphQLLimits)
+	if err != nil {
+		return err
+	}
+	graphQLController := NewGraphQLController(graphQLServer)
+	batchController := NewBatchController(svc.BatchService)
+	webhookController := NewWebhookController(svc.WebhookService)
+	eventController := NewEventController(svc.EventBroker, options.EventHeartbeat)
+	idempotent := options.Idempotent
+	mux = routeRecorder{mux}
 
 	mux.HandleFunc("GET /health", serverController.HealthCheck)
+	mux.HandleFunc("GET /livez", serverController.Livez)
+	mux.HandleFunc("GET /readyz", serverController.Readyz)
+	mux.HandleFunc("GET /openapi.json", docsController.OpenAPI)
+	mux.HandleFunc("GET /metrics", metrics.Handler().ServeHTTP)
+	mux.HandleFunc("GET /docs", docsController.Docs)
+	mux.HandleFunc("GET /docs/{file}", docsController.Asset)
 
 	// user routes
 	mux.HandleFunc("GET /api/v1/users/get/me", middleware.JwtMiddleware(userController.GetMe)) // protected route
 	mux.HandleFunc("GET /api/v1/users/get/list", userController.UserListGet)
-	mux.HandleFunc("POST /api/v1/users/register", userController.UserRegister)
+	mux.HandleFunc("POST /api/v1/users/register", idempotent(userController.UserRegister))
 	mux.HandleFunc("POST /api/v1/users/login", userController.UserLogin)
-	mux.HandleFunc("POST /api/v1/users/update", middleware.JwtMiddleware(userController.UserUpdate)) // protected route
-	mux.HandleFunc("POST /api/v1/users/delete", middleware.JwtMiddleware(userController.UserDelete)) // protected route
+	mux.HandleFunc("POST /api/v1/users/update", middleware.JwtMiddleware(idempotent(userController.UserUpdate))) // protected route
+	mux.HandleFunc("PATCH /api/v1/users/me", middleware.JwtMiddleware(idempotent(userController.UserPatch)))     // protected route
+	mux.HandleFunc("POST /api/v1/users/delete", middleware.JwtMiddleware(idempotent(userController.UserDelete))) // protected route
+
+	// admin routes
+	mux.HandleFunc("POST /api/v1/batch", middleware.JwtMiddleware(middleware.AdminMiddleware(idempotent(batchController.Batch))))                                            // protected route
+	mux.HandleFunc("POST /api/v1/webhooks", middleware.JwtMiddleware(middleware.AdminMiddleware(idempotent(webhookController.WebhookCreate))))                               // protected route
+	mux.HandleFunc("GET /api/v1/webhooks", middleware.JwtMiddleware(middleware.AdminMiddleware(webhookController.WebhookListGet)))                                           // protected route
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", middleware.JwtMiddleware(middleware.AdminMiddleware(webhookController.WebhookDelete)))                                    // protected route
+	mux.HandleFunc("GET /api/v1/webhooks/deliveries", middleware.JwtMiddleware(middleware.AdminMiddleware(webhookController.DeliveryListGet)))                               // protected route
+	mux.HandleFunc("POST /api/v1/webhooks/deliveries/{id}/redeliver", middleware.JwtMiddleware(middleware.AdminMiddleware(idempotent(webhookController.DeliveryRedeliver)))) // protected route
+	mux.HandleFunc("GET /api/v1/events", middleware.JwtMiddleware(middleware.AdminMiddleware(eventController.Stream)))                                                       // protected route
+
+	// graphql, operations that need a user check the context themselves
+	mux.HandleFunc("POST /graphql", middleware.OptionalJwtMiddleware(graphQLController.Query))
+
+	return nil
+}
+
+// routeRecorder records the matched pattern in the request logger and names the
+// server span after it, the mux only sets it on its own copy of the request so
+// the middlewares cannot read it. Each handler runs in a controller span.
+type routeRecorder struct {
+	Router
+}
+
+func (r routeRecorder) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.Router.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+		logger.SetRoute(req.Context(), pattern)
+		serverSpan := trace.SpanFromContext(req.Context())
+		serverSpan.SetName(pattern)
+		serverSpan.SetAttributes(semconv.HTTPRoute(pattern))
 
+		ctx, span := tracing.Start(req.Context(), "controller "+pattern)
+		defer span.End()
+		handler(w, req.WithContext(ctx))
+	})
 }
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static/docs.html
+var docsPage []byte
+
+// swaggerUI is the vendored swagger-ui-dist, so the docs page works offline and
+// loads no third-party scripts.
+//
+//go:embed static/swagger-ui
+var swaggerUI embed.FS
+
+var swaggerUIAssets, _ = fs.Sub(swaggerUI, "static/swagger-ui")
+
+type DocsController interface {
+	OpenAPI(w http.ResponseWriter, r *http.Request)
+	Docs(w http.ResponseWriter, r *http.Request)
+	Asset(w http.ResponseWriter, r *http.Request)
+}
+
+type docsControllerImpl struct {
+	spec []byte
+}
+
+// NewDocsController serves spec, the encoded OpenAPI document, and the docs UI.
+func NewDocsController(spec []byte) DocsController {
+	return &docsControllerImpl{
+		spec: spec,
+	}
+}
+
+func (c *docsControllerImpl) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(c.spec)
+}
+
+func (c *docsControllerImpl) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docsPage)
+}
+
+// Asset serves the swagger-ui file named by the {file} path value.
+func (c *docsControllerImpl) Asset(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, swaggerUIAssets, r.PathValue("file"))
+}
@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+)
+
+var (
+	errUnauthorized         = apperror.Unauthorized(apperror.CodeUnauthorized, "Unauthorized")
+	errUnsupportedPatchType = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeUnsupportedMediaType, "Unsupported content type")
+)
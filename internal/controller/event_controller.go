@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coder/websocket"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/eventstream"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultEventHeartbeat = 15 * time.Second
+	// eventWriteTimeout drops a client whose connection does not take a write in time.
+	eventWriteTimeout = 10 * time.Second
+	// eventRetry tells SSE clients how many milliseconds to wait before reconnecting.
+	eventRetry = 3000
+)
+
+type EventController interface {
+	Stream(w http.ResponseWriter, r *http.Request)
+}
+
+type eventControllerImpl struct {
+	broker    *eventstream.Broker
+	heartbeat time.Duration
+}
+
+func NewEventController(broker *eventstream.Broker, heartbeat time.Duration) EventController {
+	if heartbeat <= 0 {
+		heartbeat = defaultEventHeartbeat
+	}
+	return &eventControllerImpl{
+		broker:    broker,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream sends the user events as Server-Sent Events, or over a WebSocket when
+// the request is an upgrade. The types query parameter filters the event types
+// and the Last-Event-ID header, or lastEventId parameter, resumes a stream.
+// A client that falls behind is disconnected and resumes from where it was.
+func (c eventControllerImpl) Stream(w http.ResponseWriter, r *http.Request) {
+	types, err := eventTypes(r.URL.Query().Get("types"))
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		c.websocket(w, r, types, lastEventID)
+		return
+	}
+	c.serverSentEvents(w, r, types, lastEventID)
+}
+
+func (c eventControllerImpl) serverSentEvents(w http.ResponseWriter, r *http.Request, types []string, lastEventID string) {
+	subscription := c.broker.Subscribe(lastEventID, types)
+	defer subscription.Close()
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // stop proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	write := func(format string, args ...interface{}) bool {
+		// a deadline is not supported by every ResponseWriter, the stream still works without
+		_ = controller.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+	writeMessage := func(message eventstream.Message) bool {
+		data, err := encodingjson.Marshal(message.Event)
+		if err != nil {
+			log.ErrorContext(r.Context(), "failed to encode event", "error", err)
+			return true
+		}
+		return write("id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Event.Type, data)
+	}
+
+	if !write("retry: %d\n\n", eventRetry) {
+		return
+	}
+	if subscription.Reset && !write("event: %s\ndata: {}\n\n", dto.StreamEventReset) {
+		return
+	}
+	for _, message := range subscription.Replay {
+		if !writeMessage(message) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message := <-subscription.Messages():
+			if !writeMessage(message) {
+				return
+			}
+		case <-heartbeat.C:
+			if !write(": heartbeat\n\n") {
+				return
+			}
+		case <-subscription.Done():
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (c eventControllerImpl) websocket(w http.ResponseWriter, r *http.Request, types []string, lastEventID string) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		// Accept already responded
+		log.InfoContext(r.Context(), "failed to accept websocket", "error", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	subscription := c.broker.Subscribe(lastEventID, types)
+	defer subscription.Close()
+
+	// clients only receive, reading in the background answers pings and notices a close
+	ctx := conn.CloseRead(r.Context())
+	write := func(message dto.StreamMessage) bool {
+		writeCtx, cancel := context.WithTimeout(ctx, eventWriteTimeout)
+		defer cancel()
+		data, err := encodingjson.Marshal(message)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to encode event", "error", err)
+			return true
+		}
+		return conn.Write(writeCtx, websocket.MessageText, data) == nil
+	}
+
+	if subscription.Reset && !write(dto.StreamMessage{Type: dto.StreamEventReset}) {
+		return
+	}
+	for _, message := range subscription.Replay {
+		if !write(toStreamMessage(message)) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message := <-subscription.Messages():
+			if !write(toStreamMessage(message)) {
+				return
+			}
+		case <-heartbeat.C:
+			pingCtx, cancel := context.WithTimeout(ctx, eventWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return
+			}
+		case <-subscription.Done():
+			if errors.Is(subscription.Err(), eventstream.ErrSlowConsumer) {
+				conn.Close(websocket.StatusTryAgainLater, "too slow, resume with lastEventId")
+			} else {
+				conn.Close(websocket.StatusGoingAway, "server is shutting down")
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func toStreamMessage(message eventstream.Message) dto.StreamMessage {
+	event := message.Event
+	return dto.StreamMessage{ID: message.ID, Type: event.Type, Event: &event}
+}
+
+// eventTypes parses the comma separated types parameter, empty means every type.
+func eventTypes(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	types := strings.Split(value, ",")
+	for _, eventType := range types {
+		if !slices.Contains(entity.EventTypes(), eventType) {
+			allowed := strings.Join(entity.EventTypes(), ", ")
+			return nil, apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+				Field:   "types",
+				Code:    apperror.CodeFieldNotAllowed,
+				Message: fmt.Sprintf("types must be one of: %s", allowed),
+				Params:  map[string]string{"param": allowed},
+			})
+		}
+	}
+	return types, nil
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	encodingjson "encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/graphqlserver"
+	"net/http"
+)
+
+type GraphQLController interface {
+	Query(w http.ResponseWriter, r *http.Request)
+}
+
+type graphQLControllerImpl struct {
+	server graphqlserver.Server
+}
+
+func NewGraphQLController(server graphqlserver.Server) GraphQLController {
+	return &graphQLControllerImpl{
+		server: server,
+	}
+}
+
+// Query executes a GraphQL request. Errors of the operation itself are part of
+// the GraphQL response, only a malformed request gets a problem response.
+func (c *graphQLControllerImpl) Query(w http.ResponseWriter, r *http.Request) {
+	var req graphqlserver.GraphQLRequest
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response := c.server.Execute(r.Context(), req)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := encodingjson.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,5 @@
+package controller
+
+import "github.com/taninchot-work/backend-challenge/internal/core/logger"
+
+var log = logger.New("controller")
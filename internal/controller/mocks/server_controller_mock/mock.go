@@ -71,3 +71,73 @@ func (_c *ServerController_HealthCheck_Call) RunAndReturn(run func(w http.Respon
 	_c.Run(run)
 	return _c
 }
+
+// Livez provides a mock function for the type ServerController
+func (_mock *ServerController) Livez(w http.ResponseWriter, r *http.Request) {
+	_mock.Called(w, r)
+	return
+}
+
+// ServerController_Livez_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Livez'
+type ServerController_Livez_Call struct {
+	*mock.Call
+}
+
+// Livez is a helper method to define mock.On call
+//   - w
+//   - r
+func (_e *ServerController_Expecter) Livez(w interface{}, r interface{}) *ServerController_Livez_Call {
+	return &ServerController_Livez_Call{Call: _e.mock.On("Livez", w, r)}
+}
+
+func (_c *ServerController_Livez_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *ServerController_Livez_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *ServerController_Livez_Call) Return() *ServerController_Livez_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ServerController_Livez_Call) RunAndReturn(run func(w http.ResponseWriter, r *http.Request)) *ServerController_Livez_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Readyz provides a mock function for the type ServerController
+func (_mock *ServerController) Readyz(w http.ResponseWriter, r *http.Request) {
+	_mock.Called(w, r)
+	return
+}
+
+// ServerController_Readyz_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Readyz'
+type ServerController_Readyz_Call struct {
+	*mock.Call
+}
+
+// Readyz is a helper method to define mock.On call
+//   - w
+//   - r
+func (_e *ServerController_Expecter) Readyz(w interface{}, r interface{}) *ServerController_Readyz_Call {
+	return &ServerController_Readyz_Call{Call: _e.mock.On("Readyz", w, r)}
+}
+
+func (_c *ServerController_Readyz_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *ServerController_Readyz_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *ServerController_Readyz_Call) Return() *ServerController_Readyz_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ServerController_Readyz_Call) RunAndReturn(run func(w http.ResponseWriter, r *http.Request)) *ServerController_Readyz_Call {
+	_c.Run(run)
+	return _c
+}
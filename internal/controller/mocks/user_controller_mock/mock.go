@@ -177,6 +177,41 @@ func (_c *UserController_UserLogin_Call) RunAndReturn(run func(w http.ResponseWr
 	return _c
 }
 
+// UserPatch provides a mock function for the type UserController
+func (_mock *UserController) UserPatch(w http.ResponseWriter, r *http.Request) {
+	_mock.Called(w, r)
+	return
+}
+
+// UserController_UserPatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserPatch'
+type UserController_UserPatch_Call struct {
+	*mock.Call
+}
+
+// UserPatch is a helper method to define mock.On call
+//   - w
+//   - r
+func (_e *UserController_Expecter) UserPatch(w interface{}, r interface{}) *UserController_UserPatch_Call {
+	return &UserController_UserPatch_Call{Call: _e.mock.On("UserPatch", w, r)}
+}
+
+func (_c *UserController_UserPatch_Call) Run(run func(w http.ResponseWriter, r *http.Request)) *UserController_UserPatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(http.ResponseWriter), args[1].(*http.Request))
+	})
+	return _c
+}
+
+func (_c *UserController_UserPatch_Call) Return() *UserController_UserPatch_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *UserController_UserPatch_Call) RunAndReturn(run func(w http.ResponseWriter, r *http.Request)) *UserController_UserPatch_Call {
+	_c.Run(run)
+	return _c
+}
+
 // UserRegister provides a mock function for the type UserController
 func (_mock *UserController) UserRegister(w http.ResponseWriter, r *http.Request) {
 	_mock.Called(w, r)
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/openapi"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/graphqlserver"
+	"net/http"
+)
+
+// jsonPatchOperation documents one operation of an application/json-patch+json body.
+type jsonPatchOperation struct {
+	Op    string      `json:"op" validate:"required,oneof=add remove replace move copy test"`
+	Path  string      `json:"path" validate:"required"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+var (
+	ifMatchHeader = openapi.Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "ETag of the user version the change is based on",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	ifNoneMatchHeader = openapi.Parameter{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag of the cached user",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	idempotencyKeyHeader = openapi.Parameter{
+		Name:        middleware.HeaderIdempotencyKey,
+		In:          "header",
+		Description: "Key that makes retries of the request safe",
+		Schema:      &openapi.Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(255)},
+	}
+	etagHeader = map[string]openapi.Header{
+		"ETag": {Description: "Current user version in the negotiated representation", Schema: &openapi.Schema{Type: "string"}},
+	}
+)
+
+// openAPIRoutes documents every route registered in RegisterRoutes.
+func openAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Pattern:             "GET /health",
+			Summary:             "Health check, OK when the service is ready",
+			Tags:                []string{"server"},
+			ResponseContentType: "text/plain",
+			ResponseStatuses:    []int{http.StatusServiceUnavailable},
+		},
+		{
+			Pattern:             "GET /livez",
+			Summary:             "Liveness probe",
+			Tags:                []string{"server"},
+			Response:            health.Report{},
+			ResponseContentType: "application/json",
+			ResponseStatuses:    []int{http.StatusServiceUnavailable},
+		},
+		{
+			Pattern:             "GET /readyz",
+			Summary:             "Readiness probe, fails while the server shuts down",
+			Tags:                []string{"server"},
+			Response:            health.Report{},
+			ResponseContentType: "application/json",
+			ResponseStatuses:    []int{http.StatusServiceUnavailable},
+		},
+		{
+			Pattern:             "GET /openapi.json",
+			Summary:             "OpenAPI document",
+			Tags:                []string{"server"},
+			Response:            map[string]interface{}{},
+			ResponseContentType: "application/json",
+		},
+		{
+			Pattern:             "GET /metrics",
+			Summary:             "Prometheus metrics",
+			Tags:                []string{"server"},
+			ResponseContentType: "text/plain",
+		},
+		{
+			Pattern:             "GET /docs",
+			Summary:             "API documentation UI",
+			Tags:                []string{"server"},
+			ResponseContentType: "text/html",
+		},
+		{
+			Pattern:             "GET /docs/{file}",
+			Summary:             "Static file of the API documentation UI",
+			Tags:                []string{"server"},
+			Parameters:          []openapi.Parameter{pathParameter("file", "File name, e.g. swagger-ui.css")},
+			ResponseContentType: "application/octet-stream",
+		},
+		{
+			Pattern:         "GET /api/v1/users/get/me",
+			Summary:         "Get the current user",
+			Tags:            []string{"users"},
+			Auth:            true,
+			Parameters:      []openapi.Parameter{ifNoneMatchHeader},
+			Response:        dto.UserGetMeResponse{},
+			ResponseHeaders: etagHeader,
+			Errors:          []int{http.StatusNotFound, http.StatusTooManyRequests},
+			EmptyResponses:  []int{http.StatusNotModified},
+		},
+		{
+			Pattern:  "GET /api/v1/users/get/list",
+			Summary:  "List users",
+			Tags:     []string{"users"},
+			Request:  codecBody(dto.UserListGetRequest{}),
+			Response: dto.UserListGetResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "POST /api/v1/users/register",
+			Summary:    "Register a user",
+			Tags:       []string{"users"},
+			Parameters: []openapi.Parameter{idempotencyKeyHeader},
+			Request:    codecBody(dto.UserRegisterRequest{}),
+			Response:   dto.UserRegisterResponse{},
+			Errors:     []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:  "POST /api/v1/users/login",
+			Summary:  "Log in",
+			Tags:     []string{"users"},
+			Request:  codecBody(dto.UserLoginRequest{}),
+			Response: dto.UserLoginResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:         "POST /api/v1/users/update",
+			Summary:         "Update the current user",
+			Tags:            []string{"users"},
+			Auth:            true,
+			Parameters:      []openapi.Parameter{ifMatchHeader, idempotencyKeyHeader},
+			Request:         codecBody(dto.UserUpdateRequest{}),
+			Response:        dto.UserUpdateResponse{},
+			ResponseHeaders: etagHeader,
+			Errors:          []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "PATCH /api/v1/users/me",
+			Summary:    "Patch the current user",
+			Tags:       []string{"users"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{ifMatchHeader, idempotencyKeyHeader},
+			Request: map[string]interface{}{
+				patch.ContentTypeMergePatch: dto.UserPatchDocument{},
+				patch.ContentTypeJSONPatch:  []jsonPatchOperation{},
+			},
+			Response:        dto.UserUpdateResponse{},
+			ResponseHeaders: etagHeader,
+			Errors:          []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "POST /api/v1/users/delete",
+			Summary:    "Delete the current user",
+			Tags:       []string{"users"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{ifMatchHeader, idempotencyKeyHeader},
+			Response:   "User deleted successfully",
+			Errors:     []int{http.StatusNotFound, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "POST /api/v1/batch",
+			Summary:    "Run a batch of user operations, admins only",
+			Tags:       []string{"admin"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{idempotencyKeyHeader},
+			Request:    codecBody(dto.BatchRequest{}),
+			Response:   dto.BatchResponse{},
+			Errors:     []int{http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "POST /api/v1/webhooks",
+			Summary:    "Subscribe a webhook to user events, admins only",
+			Tags:       []string{"admin"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{idempotencyKeyHeader},
+			Request:    codecBody(dto.WebhookCreateRequest{}),
+			Response:   dto.WebhookResponse{},
+			Errors:     []int{http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:  "GET /api/v1/webhooks",
+			Summary:  "List webhooks, admins only",
+			Tags:     []string{"admin"},
+			Auth:     true,
+			Response: dto.WebhookListResponse{},
+			Errors:   []int{http.StatusForbidden, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "DELETE /api/v1/webhooks/{id}",
+			Summary:    "Delete a webhook, admins only",
+			Tags:       []string{"admin"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{pathParameter("id", "Webhook id")},
+			Response:   "Webhook deleted successfully",
+			Errors:     []int{http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests},
+		},
+		{
+			Pattern: "GET /api/v1/webhooks/deliveries",
+			Summary: "List webhook deliveries newest first, admins only",
+			Tags:    []string{"admin"},
+			Auth:    true,
+			Parameters: []openapi.Parameter{
+				{Name: "webhookId", In: "query", Description: "Only deliveries to this webhook", Schema: &openapi.Schema{Type: "string"}},
+				{Name: "status", In: "query", Description: "Only deliveries in this status, dead lists the dead letters", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{entity.DeliveryStatusPending, entity.DeliveryStatusSucceeded, entity.DeliveryStatusDead}}},
+				{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1)}},
+				{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(100)}},
+			},
+			Response: dto.WebhookDeliveryListResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests},
+		},
+		{
+			Pattern:    "POST /api/v1/webhooks/deliveries/{id}/redeliver",
+			Summary:    "Send a webhook delivery again, admins only",
+			Tags:       []string{"admin"},
+			Auth:       true,
+			Parameters: []openapi.Parameter{pathParameter("id", "Delivery id"), idempotencyKeyHeader},
+			Response:   dto.WebhookDeliveryResponse{},
+			Errors:     []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+		},
+		{
+			Pattern: "GET /api/v1/events",
+			Summary: "Stream user events as Server-Sent Events, or over a WebSocket on upgrade, admins only",
+			Tags:    []string{"admin"},
+			Auth:    true,
+			Parameters: []openapi.Parameter{
+				{Name: "types", In: "query", Description: "Comma separated event types, every type when empty", Schema: &openapi.Schema{Type: "string"}},
+				{Name: "lastEventId", In: "query", Description: "Resume after this event, for clients that cannot set Last-Event-ID", Schema: &openapi.Schema{Type: "string"}},
+				{Name: "Last-Event-ID", In: "header", Description: "Resume after this event", Schema: &openapi.Schema{Type: "string"}},
+			},
+			ResponseContentType: "text/event-stream",
+			Errors:              []int{http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests},
+			EmptyResponses:      []int{http.StatusSwitchingProtocols},
+		},
+		{
+			Pattern:             "POST /graphql",
+			Summary:             "Execute a GraphQL operation, the bearer token is optional",
+			Tags:                []string{"graphql"},
+			Request:             codecBody(graphqlserver.GraphQLRequest{}),
+			Response:            graphqlserver.GraphQLResponse{},
+			ResponseContentType: openapi.ContentTypeJSON,
+			Errors:              []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+	}
+}
+
+// OpenAPIDocument generates the OpenAPI document of the routes in RegisterRoutes.
+func OpenAPIDocument() (*openapi.Document, error) {
+	generator := openapi.NewGenerator(
+		openapi.Info{Title: "User Service API", Version: "1.0.0"},
+		json.Problem{},
+		"data",
+		codec.Default.ContentTypes(),
+		map[string][]string{"locale": i18n.SupportedLocales(), "event_type": entity.EventTypes()},
+	)
+	for _, route := range openAPIRoutes() {
+		if err := generator.Add(route); err != nil {
+			return nil, err
+		}
+	}
+	return generator.Document(), nil
+}
+
+// codecBody documents body in every content type of the codec registry.
+func codecBody(body interface{}) map[string]interface{} {
+	content := map[string]interface{}{}
+	for _, contentType := range codec.Default.ContentTypes() {
+		content[contentType] = body
+	}
+	return content
+}
+
+func pathParameter(name string, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &openapi.Schema{Type: "string"}}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"net/http"
+	"strconv"
+)
+
+// decodeRequest decodes the body of r into req with the codec of its content
+// type and validates it, w is needed to limit the body size. The OpenAPI validation middleware already checked the
+// body against the schema, this keeps handlers safe when they are served without it.
+func decodeRequest(w http.ResponseWriter, r *http.Request, req interface{}) error {
+	if err := codec.DecodeRequest(w, r, req); err != nil {
+		return err
+	}
+	return validation.Struct(req)
+}
+
+// queryInt returns the integer query parameter name of r, or fallback when it
+// is missing. A value that is not an integer is a validation error.
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+			Field:   name,
+			Code:    apperror.CodeFieldInvalidType,
+			Message: fmt.Sprintf("%s must be of type integer", name),
+			Params:  map[string]string{"param": "integer"},
+		})
+	}
+	return i, nil
+}
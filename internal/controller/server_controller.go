@@ -1,12 +1,16 @@
 package controller
 
 import (
+	encodingjson "encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
 	"github.com/taninchot-work/backend-challenge/internal/service"
 	"net/http"
 )
 
 type ServerController interface {
 	HealthCheck(w http.ResponseWriter, r *http.Request)
+	Livez(w http.ResponseWriter, r *http.Request)
+	Readyz(w http.ResponseWriter, r *http.Request)
 }
 
 type serverControllerImpl struct {
@@ -19,8 +23,39 @@ func NewServerController(serverService service.ServerService) ServerController {
 	}
 }
 
+// HealthCheck is the plain text form of Readyz.
 func (s *serverControllerImpl) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := s.serverService.HealthCheck()
+	report := s.serverService.Readiness(r.Context())
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != health.StatusPass {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("UNAVAILABLE"))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(response))
+	w.Write([]byte("OK"))
+}
+
+func (s *serverControllerImpl) Livez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, r, s.serverService.Liveness(r.Context()))
+}
+
+func (s *serverControllerImpl) Readyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, r, s.serverService.Readiness(r.Context()))
+}
+
+// writeReport writes report as is, with 503 when it failed so probes that
+// only look at the status work too.
+func writeReport(w http.ResponseWriter, r *http.Request, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusPass {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := encodingjson.NewEncoder(w).Encode(report); err != nil {
+		log.ErrorContext(r.Context(), "error encoding health report", "error", err)
+	}
 }
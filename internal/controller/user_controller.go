@@ -1,11 +1,15 @@
 package controller
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/idempotency"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/service"
+	"mime"
 	"net/http"
 	"strings"
 )
@@ -16,6 +20,7 @@ type UserController interface {
 	UserRegister(w http.ResponseWriter, r *http.Request)
 	UserLogin(w http.ResponseWriter, r *http.Request)
 	UserUpdate(w http.ResponseWriter, r *http.Request)
+	UserPatch(w http.ResponseWriter, r *http.Request)
 	UserDelete(w http.ResponseWriter, r *http.Request)
 }
 
@@ -32,23 +37,30 @@ func NewUserController(userService service.UserService) UserController {
 func (c userControllerImpl) GetMe(w http.ResponseWriter, r *http.Request) {
 	userId, ok := r.Context().Value(constant.CONTEXT_KEY_USER_ID).(string)
 	if !ok || userId == "" {
-		json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+		json.ResponseWithProblem(w, r, errUnauthorized)
 		return
 	}
 	response, err := c.userService.GetUserByID(r.Context(), userId)
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
-	json.ResponseWithSuccess(w, response)
+
+	tag := representationTag(r, response.Version)
+	w.Header().Set("ETag", tag)
+	if etag.NotModified(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, response)
 	return
 }
 
 func (c userControllerImpl) UserListGet(w http.ResponseWriter, r *http.Request) {
 	var req dto.UserListGetRequest
 
-	if err := json.NewDecoder(r).Decode(&req); err != nil {
-		json.ResponseWithError(w, "Invalid request body", http.StatusBadRequest)
+	if err := codec.DecodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 	// validate request
@@ -58,60 +70,49 @@ func (c userControllerImpl) UserListGet(w http.ResponseWriter, r *http.Request)
 	}
 	response, err := c.userService.GetUserList(r.Context(), req)
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
-	json.ResponseWithSuccess(w, response)
+	codec.ResponseWithSuccess(w, r, response)
 	return
 }
 
 func (c userControllerImpl) UserRegister(w http.ResponseWriter, r *http.Request) {
 	var req dto.UserRegisterRequest
-
-	if err := json.NewDecoder(r).Decode(&req); err != nil {
-		json.ResponseWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// validate request
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Struct(req); err != nil {
-		var validationErrors []string
-		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, err.Error())
-		}
-		json.ResponseWithError(w, "Validation failed: "+json.JoinErrors(validationErrors), http.StatusBadRequest)
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
 	req.Email = strings.ToLower(req.Email)
 
-	response, err := c.userService.RegisterUser(r.Context(), req)
+	var response dto.UserRegisterResponse
+	var err error
+	reissue, idempotent := idempotency.ReissueFromContext(r.Context())
+	if idempotent && reissue.Replay {
+		response, err = c.userService.ReissueRegistration(r.Context(), reissue.Subject)
+	} else {
+		response, err = c.userService.RegisterUser(r.Context(), req)
+	}
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
+	if idempotent {
+		// a retry gets the registered user with a fresh token instead of a conflict
+		reissue.Subject = response.ID
+	}
 
-	json.ResponseWithSuccess(w, response)
+	// the response carries an access token, keep it out of caches and the idempotency store
+	w.Header().Set("Cache-Control", "no-store")
+	codec.ResponseWithSuccess(w, r, response)
 	return
 }
 
 func (c userControllerImpl) UserLogin(w http.ResponseWriter, r *http.Request) {
 	var req dto.UserLoginRequest
-
-	if err := json.NewDecoder(r).Decode(&req); err != nil {
-		json.ResponseWithError(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// validate request
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Struct(req); err != nil {
-		var validationErrors []string
-		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, err.Error())
-		}
-		json.ResponseWithError(w, "Validation failed: "+json.JoinErrors(validationErrors), http.StatusBadRequest)
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
@@ -119,63 +120,108 @@ func (c userControllerImpl) UserLogin(w http.ResponseWriter, r *http.Request) {
 
 	response, err := c.userService.LoginUser(r.Context(), req)
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
-	json.ResponseWithSuccess(w, response)
+	// the response carries an access token, keep it out of caches and the idempotency store
+	w.Header().Set("Cache-Control", "no-store")
+	codec.ResponseWithSuccess(w, r, response)
 	return
 }
 
 func (c userControllerImpl) UserUpdate(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || userId == "" {
+		json.ResponseWithProblem(w, r, errUnauthorized)
+		return
+	}
+
 	var req dto.UserUpdateRequest
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	req.Email = strings.ToLower(req.Email)
+	req.IfMatch = r.Header.Get("If-Match")
 
-	if err := json.NewDecoder(r).Decode(&req); err != nil {
-		json.ResponseWithError(w, "Invalid request body", http.StatusBadRequest)
+	response, err := c.userService.UpdateUser(r.Context(), userId, req)
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
+
+	w.Header().Set("ETag", representationTag(r, response.Version))
+	codec.ResponseWithSuccess(w, r, response)
+	return
+}
+
+func (c userControllerImpl) UserPatch(w http.ResponseWriter, r *http.Request) {
 	userId, ok := r.Context().Value(constant.CONTEXT_KEY_USER_ID).(string)
 	if !ok || userId == "" {
-		json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+		json.ResponseWithProblem(w, r, errUnauthorized)
 		return
 	}
 
-	// validate request TODO: use a validation library
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Struct(req); err != nil {
-		var validationErrors []string
-		for _, err := range err.(validator.ValidationErrors) {
-			validationErrors = append(validationErrors, err.Error())
-		}
-		json.ResponseWithError(w, "Validation failed: "+json.JoinErrors(validationErrors), http.StatusBadRequest)
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != patch.ContentTypeMergePatch && contentType != patch.ContentTypeJSONPatch) {
+		w.Header().Set("Accept-Patch", patch.ContentTypeMergePatch+", "+patch.ContentTypeJSONPatch)
+		json.ResponseWithProblem(w, r, errUnsupportedPatchType)
 		return
 	}
 
-	req.Email = strings.ToLower(req.Email)
+	body, err := codec.ReadBody(w, r)
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
 
-	response, err := c.userService.UpdateUser(r.Context(), userId, req)
+	response, err := c.userService.PatchUser(r.Context(), userId, dto.UserPatchRequest{
+		ContentType: contentType,
+		Patch:       body,
+		IfMatch:     r.Header.Get("If-Match"),
+	})
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
-	json.ResponseWithSuccess(w, response)
+	w.Header().Set("ETag", representationTag(r, response.Version))
+	codec.ResponseWithSuccess(w, r, response)
 	return
 }
 
 func (c userControllerImpl) UserDelete(w http.ResponseWriter, r *http.Request) {
 	userId, ok := r.Context().Value(constant.CONTEXT_KEY_USER_ID).(string)
 	if !ok || userId == "" {
-		json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+		json.ResponseWithProblem(w, r, errUnauthorized)
+		return
+	}
+
+	if err := codec.Acceptable(r); err != nil {
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
-	err := c.userService.DeleteUser(r.Context(), userId)
+	err := c.userService.DeleteUser(r.Context(), userId, dto.UserDeleteRequest{
+		IfMatch: r.Header.Get("If-Match"),
+	})
 	if err != nil {
-		json.ResponseWithError(w, err.Error(), http.StatusBadRequest)
+		json.ResponseWithProblem(w, r, err)
 		return
 	}
 
-	json.ResponseWithSuccess(w, "User deleted successfully")
+	codec.ResponseWithSuccess(w, r, "User deleted successfully")
 	return
 }
+
+// representationTag returns the ETag of the user version in the representation
+// negotiated for r, codec.Response refuses the request when there is none.
+func representationTag(r *http.Request, version int64) string {
+	contentType := codec.ContentTypeJSON
+	if negotiated, ok := codec.Default.Negotiate(r.Header.Get("Accept")); ok {
+		contentType = negotiated.ContentType()
+	}
+	return etag.ForRepresentation(version, contentType)
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"net/http"
+)
+
+type WebhookController interface {
+	WebhookCreate(w http.ResponseWriter, r *http.Request)
+	WebhookListGet(w http.ResponseWriter, r *http.Request)
+	WebhookDelete(w http.ResponseWriter, r *http.Request)
+	DeliveryListGet(w http.ResponseWriter, r *http.Request)
+	DeliveryRedeliver(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookControllerImpl struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookController(webhookService service.WebhookService) WebhookController {
+	return &webhookControllerImpl{
+		webhookService: webhookService,
+	}
+}
+
+func (c webhookControllerImpl) WebhookCreate(w http.ResponseWriter, r *http.Request) {
+	var req dto.WebhookCreateRequest
+	if err := decodeRequest(w, r, &req); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response, err := c.webhookService.CreateWebhook(r.Context(), req)
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, response)
+}
+
+func (c webhookControllerImpl) WebhookListGet(w http.ResponseWriter, r *http.Request) {
+	if err := codec.Acceptable(r); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response, err := c.webhookService.GetWebhookList(r.Context())
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, response)
+}
+
+func (c webhookControllerImpl) WebhookDelete(w http.ResponseWriter, r *http.Request) {
+	if err := codec.Acceptable(r); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	if err := c.webhookService.DeleteWebhook(r.Context(), r.PathValue("id")); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, "Webhook deleted successfully")
+}
+
+// DeliveryListGet lists deliveries newest first, filtered by the webhookId and
+// status query parameters. status=dead lists the dead letters.
+func (c webhookControllerImpl) DeliveryListGet(w http.ResponseWriter, r *http.Request) {
+	if err := codec.Acceptable(r); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	req := dto.WebhookDeliveryListRequest{
+		WebhookID: r.URL.Query().Get("webhookId"),
+		Status:    r.URL.Query().Get("status"),
+	}
+	var err error
+	if req.Page, err = queryInt(r, "page", 1); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	if req.Limit, err = queryInt(r, "limit", 20); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response, err := c.webhookService.GetDeliveryList(r.Context(), req)
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, response)
+}
+
+func (c webhookControllerImpl) DeliveryRedeliver(w http.ResponseWriter, r *http.Request) {
+	if err := codec.Acceptable(r); err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+
+	response, err := c.webhookService.RedeliverDelivery(r.Context(), r.PathValue("id"))
+	if err != nil {
+		json.ResponseWithProblem(w, r, err)
+		return
+	}
+	codec.ResponseWithSuccess(w, r, response)
+}
@@ -0,0 +1,115 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind string
+
+const (
+	KindValidation           Kind = "validation"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindNotFound             Kind = "not_found"
+	KindConflict             Kind = "conflict"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+	KindNotAcceptable        Kind = "not_acceptable"
+	KindUnprocessable        Kind = "unprocessable"
+	KindTooManyRequests      Kind = "too_many_requests"
+	KindPayloadTooLarge      Kind = "payload_too_large"
+	KindFailedDependency     Kind = "failed_dependency"
+	KindInternal             Kind = "internal"
+)
+
+// Error is a domain error carrying a stable machine-readable code and a message
+// that is safe to show to clients. The wrapped cause is only meant for logs.
+// Params fill the placeholders of the localized message for Code.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Params  map[string]string
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string            `json:"field"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithParam returns a copy of e with the message parameter name set to value.
+func (e *Error) WithParam(name string, value string) *Error {
+	copied := *e
+	copied.Params = make(map[string]string, len(e.Params)+1)
+	for k, v := range e.Params {
+		copied.Params[k] = v
+	}
+	copied.Params[name] = value
+	return &copied
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func Validation(code string, message string, fields ...FieldError) *Error {
+	err := New(KindValidation, code, message)
+	err.Fields = fields
+	return err
+}
+
+func Unauthorized(code string, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Internal wraps an unexpected error, its details are hidden from clients.
+func Internal(err error) *Error {
+	return &Error{
+		Kind:    KindInternal,
+		Code:    CodeInternalError,
+		Message: "internal server error",
+		Err:     err,
+	}
+}
+
+// From returns err as an *Error, treating any unknown error as internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
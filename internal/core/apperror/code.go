@@ -0,0 +1,47 @@
+package apperror
+
+// Stable error codes returned to clients, they must not change once published.
+const (
+	CodeInvalidRequestBody     = "INVALID_REQUEST_BODY"
+	CodeRequestBodyTooLarge    = "REQUEST_BODY_TOO_LARGE"
+	CodeValidationFailed       = "VALIDATION_FAILED"
+	CodeUnsupportedMediaType   = "UNSUPPORTED_MEDIA_TYPE"
+	CodeNotAcceptable          = "NOT_ACCEPTABLE"
+	CodeUnauthorized           = "UNAUTHORIZED"
+	CodeForbidden              = "FORBIDDEN"
+	CodeInvalidCredentials     = "INVALID_CREDENTIALS"
+	CodeUserNotFound           = "USER_NOT_FOUND"
+	CodeEmailAlreadyExists     = "EMAIL_ALREADY_EXISTS"
+	CodePreconditionFailed     = "PRECONDITION_FAILED"
+	CodeConcurrentModification = "CONCURRENT_MODIFICATION"
+	CodeInvalidPatch           = "INVALID_PATCH"
+	CodeInvalidIdempotencyKey  = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused   = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress  = "IDEMPOTENCY_REQUEST_IN_PROGRESS"
+	CodeRateLimitExceeded      = "RATE_LIMIT_EXCEEDED"
+	CodeInvalidCursor          = "INVALID_CURSOR"
+	CodeInvalidQuery           = "INVALID_QUERY"
+	CodeQueryTooDeep           = "QUERY_TOO_DEEP"
+	CodeQueryTooComplex        = "QUERY_TOO_COMPLEX"
+	CodeTooManyAuthMutations   = "TOO_MANY_AUTH_MUTATIONS"
+	CodeBatchRolledBack        = "BATCH_ROLLED_BACK"
+	CodeWebhookNotFound        = "WEBHOOK_NOT_FOUND"
+	CodeDeliveryNotFound       = "WEBHOOK_DELIVERY_NOT_FOUND"
+	CodeInternalError          = "INTERNAL_ERROR"
+)
+
+// Field level error codes.
+const (
+	CodeFieldRequired          = "FIELD_REQUIRED"
+	CodeFieldInvalidEmail      = "FIELD_INVALID_EMAIL"
+	CodeFieldTooShort          = "FIELD_TOO_SHORT"
+	CodeFieldTooLong           = "FIELD_TOO_LONG"
+	CodeFieldInvalid           = "FIELD_INVALID"
+	CodeFieldUnsupportedLocale = "FIELD_UNSUPPORTED_LOCALE"
+	CodeFieldImmutable         = "FIELD_IMMUTABLE"
+	CodeFieldUnknown           = "FIELD_UNKNOWN"
+	CodeFieldInvalidType       = "FIELD_INVALID_TYPE"
+	CodeFieldNotAllowed        = "FIELD_NOT_ALLOWED"
+	CodeFieldTooSmall          = "FIELD_TOO_SMALL"
+	CodeFieldTooLarge          = "FIELD_TOO_LARGE"
+)
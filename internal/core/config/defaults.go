@@ -0,0 +1,123 @@
+package config
+
+// Modes of the service, production refuses settings that are only safe locally.
+const (
+	ModeDevelopment = "development"
+	ModeProduction  = "production"
+)
+
+// Backends of the user store.
+const (
+	UserStoreMongo    = "mongo"
+	UserStorePostgres = "postgres"
+	UserStoreSQLite   = "sqlite"
+)
+
+// defaults is the first layer of the configuration, the config file, the
+// environment and the flags override it. There is no default JWT secret.
+var defaults = map[string]interface{}{
+	"mode": ModeDevelopment,
+
+	"restServer.port":                        3000,
+	"restServer.maxBodySize":                 1048576,
+	"restServer.jwt.algorithm":               "HS256",
+	"restServer.jwt.expiresIn":               86400000,
+	"restServer.jwt.issuer":                  "ms_user",
+	"restServer.jwt.revocationCacheTtl":      5000,
+	"restServer.idempotency.store":           "memory",
+	"restServer.idempotency.expiresIn":       86400000,
+	"restServer.idempotency.lockTtl":         60000,
+	"restServer.rateLimit.enabled":           true,
+	"restServer.rateLimit.store":             "memory",
+	"restServer.rateLimit.trustForwardedFor": false,
+	"restServer.rateLimit.policies":          defaultRateLimitPolicies,
+	"restServer.validation.requests":         true,
+	"restServer.graphql.maxDepth":            8,
+	"restServer.graphql.maxComplexity":       1000,
+	"restServer.batch.maxOperations":         100,
+	"restServer.events.replaySize":           1000,
+	"restServer.events.subscriberBuffer":     64,
+	"restServer.events.heartbeat":            15000,
+
+	"grpcServer.enabled": true,
+	"grpcServer.port":    50051,
+
+	"webhook.enabled":              true,
+	"webhook.pollInterval":         1000,
+	"webhook.timeout":              10000,
+	"webhook.maxAttempts":          8,
+	"webhook.initialBackoff":       10000,
+	"webhook.maxBackoff":           3600000,
+	"webhook.batchSize":            50,
+	"webhook.allowPrivateNetworks": false,
+
+	"log.format": "json",
+	"log.level":  "info",
+
+	"metrics.userCountInterval": 10000,
+
+	"tracing.exporter":    "none",
+	"tracing.file":        "traces.jsonl",
+	"tracing.serviceName": "backend-challenge",
+	"tracing.sampleRatio": 1,
+
+	"health.timeout":       2000,
+	"health.cacheTtl":      1000,
+	"health.workerTimeout": 300000,
+	"health.shutdownDelay": 5000,
+	"health.diskPath":      ".",
+
+	"outbox.enabled":            true,
+	"outbox.pollInterval":       500,
+	"outbox.batchSize":          100,
+	"outbox.leaseTtl":           30000,
+	"outbox.maxAttempts":        10,
+	"outbox.initialBackoff":     1000,
+	"outbox.maxBackoff":         300000,
+	"outbox.nats.url":           "nats://localhost:4222",
+	"outbox.nats.subjectPrefix": "users",
+	"outbox.nats.timeout":       5000,
+	"outbox.kafka.topic":        "user-events",
+	"outbox.kafka.timeout":      10000,
+
+	"userStore.backend":               UserStoreMongo,
+	"userStore.postgres.maxOpenConns": 10,
+
+	"migrations.auto":    true,
+	"migrations.lockTtl": 60000,
+
+	"database.host":              "localhost",
+	"database.port":              27017,
+	"database.databaseName":      "ms_user",
+	"database.connectionTimeout": 10000,
+	"database.maxPoolSize":       10,
+}
+
+// defaultRateLimitPolicies guard login and register against brute force and
+// account spraying, over REST, gRPC and GraphQL, when no policy is configured.
+var defaultRateLimitPolicies = []map[string]interface{}{
+	{
+		"name":      "register",
+		"routes":    []string{"POST /api/v1/users/register", "/user.v1.UserService/RegisterUser"},
+		"algorithm": "sliding_window",
+		"limit":     5,
+		"window":    60000,
+		"keyBy":     "ip",
+	},
+	{
+		"name":      "login",
+		"routes":    []string{"POST /api/v1/users/login", "/user.v1.UserService/LoginUser"},
+		"algorithm": "sliding_window",
+		"limit":     10,
+		"window":    60000,
+		"keyBy":     "ip",
+	},
+	{
+		"name":      "graphql",
+		"routes":    []string{"POST /graphql"},
+		"algorithm": "sliding_window",
+		"limit":     30,
+		"window":    60000,
+		"keyBy":     "ip",
+	},
+}
@@ -1,29 +1,237 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
 
-var config *Config
+// defaultConfigFile is read when it exists and no -config flag is given.
+const defaultConfigFile = "config.yaml"
 
-func InitConfig(configFileName string) {
-	viper.SetConfigType("yaml")
-	viper.SetConfigName(configFileName)
-	viper.AddConfigPath(".")
+// envPrefix prefixes the environment variable of every setting, like
+// APP_DATABASE_HOST for database.host.
+const envPrefix = "APP_"
 
-	err := viper.ReadInConfig()
+// fileSuffix marks an environment variable that holds the path of a file with
+// the value instead, like APP_REST_SERVER_JWT_SECRET_FILE for secrets.
+const fileSuffix = "_FILE"
+
+var config atomic.Pointer[Config]
+
+// Load reads the configuration in layers, each overriding the previous one:
+// the defaults, the YAML file given by -config, the APP_* environment
+// variables and the -set flags. The result is validated, every problem is
+// reported in one error.
+func Load(args []string) (*Config, error) {
+	path, overrides, err := parseFlags(args)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	v := viper.New()
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+		}
+	}
+
+	var errs []error
+	for _, key := range settingKeys() {
+		value, ok, err := lookupEnv(envName(key))
+		if err != nil {
+			errs = append(errs, err)
+		} else if ok {
+			v.Set(key, value)
+		}
+	}
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		if !isSettingKey(key) {
+			errs = append(errs, fmt.Errorf("-set %s: unknown setting %q", override, key))
+			continue
+		}
+		v.Set(key, value)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, listErrors("invalid environment or flags", err)
+	}
+
+	var c Config
+	if err := v.Unmarshal(&c, func(decoderConfig *mapstructure.DecoderConfig) {
+		decoderConfig.ErrorUnused = true
+	}); err != nil {
+		return nil, fmt.Errorf("error reading configuration: %w", err)
+	}
+	if err := Validate(&c); err != nil {
+		return nil, listErrors("invalid configuration", err)
+	}
+	return &c, nil
+}
+
+// listErrors returns the errors joined in err as a list under title.
+func listErrors(title string, err error) error {
+	return fmt.Errorf("%s:\n  - %s", title, strings.ReplaceAll(err.Error(), "\n", "\n  - "))
+}
+
+// Flags are the -config and -set flags of a command, passed to Load with Args.
+type Flags struct {
+	path      string
+	overrides setFlag
+}
+
+// AddFlags defines -config and -set on flags, for commands with flags of their own.
+func AddFlags(flags *flag.FlagSet) *Flags {
+	f := &Flags{}
+	flags.StringVar(&f.path, "config", "", "path of the YAML config file, "+defaultConfigFile+" is read when it exists")
+	flags.Var(&f.overrides, "set", "override a setting like restServer.port=8080, may be repeated")
+	return f
+}
+
+// Args returns the flags as the arguments of Load.
+func (f *Flags) Args() []string {
+	var args []string
+	if f.path != "" {
+		args = append(args, "-config", f.path)
+	}
+	for _, override := range f.overrides {
+		args = append(args, "-set", override)
+	}
+	return args
+}
+
+// parseFlags returns the config file of args, empty when there is none, and
+// the -set overrides.
+func parseFlags(args []string) (string, []string, error) {
+	flags := flag.NewFlagSet("backend-challenge", flag.ContinueOnError)
+	f := AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if f.path == "" {
+		if _, err := os.Stat(defaultConfigFile); err == nil {
+			f.path = defaultConfigFile
+		}
 	}
+	return f.path, f.overrides, nil
+}
 
-	err = viper.Unmarshal(&config)
+// lookupEnv returns the value of the environment variable name, or the
+// content of the file named by name_FILE.
+func lookupEnv(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(name)
+	path, fromFile := os.LookupEnv(name + fileSuffix)
+	if !fromFile {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("%s and %s are both set, set only one", name, name+fileSuffix)
+	}
+	content, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return "", false, fmt.Errorf("%s: %w", name+fileSuffix, err)
+	}
+	// secret files usually end with a newline that is not part of the secret
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// settingKeys returns the key of every setting of Config, like
+// restServer.jwt.secret. Maps and lists are one setting.
+func settingKeys() []string {
+	return appendKeys(nil, reflect.TypeOf(Config{}), "")
+}
+
+func appendKeys(keys []string, t reflect.Type, prefix string) []string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Tag.Get("mapstructure")
+		if field.Type.Kind() == reflect.Struct {
+			keys = appendKeys(keys, field.Type, key+".")
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// isSettingKey reports whether key is a setting or an entry of a map setting,
+// like log.packages.repository. Keys are case-insensitive.
+func isSettingKey(key string) bool {
+	t := reflect.TypeOf(Config{})
+	for _, segment := range strings.Split(key, ".") {
+		if t.Kind() == reflect.Map {
+			return true
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		next := t
+		for i := 0; i < t.NumField(); i++ {
+			if strings.EqualFold(t.Field(i).Tag.Get("mapstructure"), segment) {
+				next = t.Field(i).Type
+				break
+			}
+		}
+		if next == t {
+			return false
+		}
+		t = next
+	}
+	return t.Kind() != reflect.Struct
+}
+
+// envName returns the environment variable of key, restServer.jwt.secret is
+// APP_REST_SERVER_JWT_SECRET.
+func envName(key string) string {
+	var name strings.Builder
+	name.WriteString(envPrefix)
+	for i, r := range key {
+		switch {
+		case r == '.':
+			name.WriteByte('_')
+		case unicode.IsUpper(r) && i > 0 && key[i-1] != '.':
+			name.WriteByte('_')
+			name.WriteRune(r)
+		default:
+			name.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return name.String()
+}
+
+// setFlag collects the values of a repeated flag.
+type setFlag []string
+
+func (f *setFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *setFlag) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not key=value", value)
 	}
+	*f = append(*f, value)
+	return nil
 }
 
+// GetConfig returns the current configuration, do not keep it across
+// requests, a reload replaces it.
 func GetConfig() *Config {
-	return config
+	return config.Load()
 }
 
 func SetConfig(c *Config) {
-	config = c
+	config.Store(c)
 }
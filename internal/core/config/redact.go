@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"log/slog"
+	"strings"
+)
+
+// Redacted returns c without its credentials: the password, and the password
+// and secret options of URI.
+func (c MongoConfig) Redacted() MongoConfig {
+	if c.Password != "" {
+		c.Password = logger.Redacted
+	}
+	c.URI = redactURI(c.URI)
+	return c
+}
+
+// String formats c without its credentials, for %v.
+func (c MongoConfig) String() string {
+	// plain has no String method, formatting it does not come back here
+	type plain MongoConfig
+	return fmt.Sprintf("%+v", plain(c.Redacted()))
+}
+
+// LogValue logs c without its credentials.
+func (c MongoConfig) LogValue() slog.Value {
+	redacted := c.Redacted()
+	return slog.GroupValue(
+		slog.String("uri", redacted.URI),
+		slog.Any("hosts", redacted.Hosts),
+		slog.String("host", redacted.Host),
+		slog.Int("port", redacted.Port),
+		slog.String("databaseName", redacted.DatabaseName),
+		slog.String("username", redacted.Username),
+		slog.String("password", redacted.Password),
+		slog.String("authSource", redacted.AuthSource),
+		slog.String("replicaSet", redacted.ReplicaSet),
+		slog.Bool("tls", redacted.TLS.Enabled),
+	)
+}
+
+// redactURI replaces the password of the user info and the values of the
+// secret options of a connection string.
+func redactURI(uri string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return uri
+	}
+	authority, query, hasQuery := strings.Cut(rest, "?")
+	// the user info ends at the last @ before the path
+	hostsEnd := strings.Index(authority, "/")
+	if hostsEnd < 0 {
+		hostsEnd = len(authority)
+	}
+	if at := strings.LastIndex(authority[:hostsEnd], "@"); at >= 0 {
+		if user, _, hasPassword := strings.Cut(authority[:at], ":"); hasPassword {
+			authority = user + ":" + logger.Redacted + authority[at:]
+		}
+	}
+	if !hasQuery {
+		return scheme + "://" + authority
+	}
+
+	options := strings.Split(query, "&")
+	for i, option := range options {
+		name, _, _ := strings.Cut(option, "=")
+		lowerName := strings.ToLower(name)
+		if strings.Contains(lowerName, "password") || lowerName == "authmechanismproperties" {
+			options[i] = name + "=" + logger.Redacted
+		}
+	}
+	return scheme + "://" + authority + "?" + strings.Join(options, "&")
+}
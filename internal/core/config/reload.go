@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var log = logger.New("config")
+
+// reloadable are the settings, and the settings under them, that are applied
+// without a restart. The others keep their value until the next start.
+var reloadable = []string{"log", "restServer.jwt", "restServer.rateLimit.policies"}
+
+// Subscriber applies a reloaded configuration to a component, previous is the
+// configuration it replaced.
+type Subscriber func(previous *Config, current *Config) error
+
+var (
+	// reloadMu serializes reloads, so subscribers see them in order
+	reloadMu    sync.Mutex
+	subscribers []*namedSubscriber
+)
+
+type namedSubscriber struct {
+	name       string
+	subscriber Subscriber
+}
+
+// Subscribe calls subscriber after every reload, in the order of subscription,
+// name identifies it in the logs. The returned function unsubscribes it.
+func Subscribe(name string, subscriber Subscriber) func() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscribed := &namedSubscriber{name: name, subscriber: subscriber}
+	subscribers = append(subscribers, subscribed)
+	return func() {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		subscribers = slices.DeleteFunc(subscribers, func(s *namedSubscriber) bool { return s == subscribed })
+	}
+}
+
+// Reload loads the configuration again from args and swaps it in when it is
+// valid, then notifies the subscribers. Changed settings that need a restart
+// keep their current value and are logged.
+func Reload(args []string) error {
+	next, err := Load(args)
+	if err != nil {
+		return err
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	previous := GetConfig()
+	for _, key := range keepStatic(previous, next) {
+		log.Warn("setting changed but needs a restart, keeping the current value", "setting", key)
+	}
+	// the kept values may not go with the new ones, e.g. a weak secret with the production mode
+	if err := Validate(next); err != nil {
+		return listErrors("invalid configuration", err)
+	}
+	config.Store(next)
+
+	for _, s := range subscribers {
+		if err := s.subscriber(previous, next); err != nil {
+			log.Error("failed to apply reloaded configuration", "component", s.name, "error", err)
+		}
+	}
+	log.Info("configuration reloaded")
+	return nil
+}
+
+// NotifyHangups relays SIGHUP to the returned channel, the returned function
+// stops it. Register it before starting Watch, a SIGHUP sent before then
+// would otherwise terminate the process.
+func NotifyHangups() (<-chan os.Signal, func()) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	return hangups, func() { signal.Stop(hangups) }
+}
+
+// Watch reloads the configuration on every signal of hangups and when the
+// config file of args changes, until ctx is done. A configuration that fails
+// to load is logged and the current one stays.
+func Watch(ctx context.Context, args []string, hangups <-chan os.Signal) {
+	changes := make(chan struct{}, 1)
+	if path, _, err := parseFlags(args); err == nil && path != "" {
+		v := viper.New()
+		v.SetConfigFile(path)
+		v.SetConfigType("yaml")
+		v.OnConfigChange(func(fsnotify.Event) {
+			// an editor saving the file sends several events, one pending reload is enough
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		})
+		v.WatchConfig()
+	}
+
+	for {
+		var trigger string
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+			trigger = "signal"
+		case <-changes:
+			trigger = "file"
+		}
+		if err := Reload(args); err != nil {
+			log.Error("failed to reload configuration", "trigger", trigger, "error", err)
+		}
+	}
+}
+
+// keepStatic copies the settings of previous that are not reloadable into
+// next and returns the keys of those that changed.
+func keepStatic(previous *Config, next *Config) []string {
+	if previous == nil {
+		return nil
+	}
+	var changed []string
+	for _, key := range settingKeys() {
+		if isReloadable(key) {
+			continue
+		}
+		current, reloaded := settingField(previous, key), settingField(next, key)
+		if !reflect.DeepEqual(current.Interface(), reloaded.Interface()) {
+			changed = append(changed, key)
+			reloaded.Set(current)
+		}
+	}
+	return changed
+}
+
+func isReloadable(key string) bool {
+	for _, prefix := range reloadable {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// settingField returns the field of c that holds the setting key.
+func settingField(c *Config, key string) reflect.Value {
+	field := reflect.ValueOf(c).Elem()
+	for _, segment := range strings.Split(key, ".") {
+		for i := 0; i < field.NumField(); i++ {
+			if field.Type().Field(i).Tag.Get("mapstructure") == segment {
+				field = field.Field(i)
+				break
+			}
+		}
+	}
+	return field
+}
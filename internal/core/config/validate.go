@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/keypair"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// minProductionSecretLength is the shortest JWT secret accepted in production,
+// 32 bytes match the output of the HS256 hash.
+const minProductionSecretLength = 32
+
+// weakSecrets are placeholders that must never sign tokens in production.
+var weakSecrets = []string{"secret", "jwtsecret", "secret_key", "changeme", "password", "test"}
+
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// sha256Hex matches the hex digest of a SHA-256 hash.
+var sha256Hex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// Validate reports every setting the service cannot run with, one error per
+// setting, so all of them can be fixed at once.
+func Validate(c *Config) error {
+	if c == nil {
+		return errors.New("configuration is not loaded")
+	}
+	v := &validator{}
+
+	v.oneOf("mode", c.Mode, ModeDevelopment, ModeProduction)
+
+	v.port("restServer.port", c.RestServer.Port)
+	v.positive("restServer.maxBodySize", c.RestServer.MaxBodySize)
+	if c.GrpcServer.Enabled {
+		v.port("grpcServer.port", c.GrpcServer.Port)
+	}
+
+	v.oneOf("restServer.jwt.algorithm", c.RestServer.Jwt.Algorithm, append([]string{"", "HS256"}, keypair.Algorithms...)...)
+	if c.RestServer.Jwt.Algorithm == "" || c.RestServer.Jwt.Algorithm == "HS256" {
+		v.secret(c.Mode, c.RestServer.Jwt.Secret)
+	} else {
+		v.signingKeys(c.RestServer.Jwt)
+	}
+	v.positive("restServer.jwt.expiresIn", c.RestServer.Jwt.ExpireIn)
+	v.notNegative("restServer.jwt.revocationCacheTtl", c.RestServer.Jwt.RevocationCacheTTL)
+
+	v.oneOf("restServer.idempotency.store", c.RestServer.Idempotency.Store, "", "memory", "mongo")
+	v.notNegative("restServer.idempotency.expiresIn", c.RestServer.Idempotency.ExpireIn)
+	v.positive("restServer.idempotency.lockTtl", c.RestServer.Idempotency.LockTTL)
+	if c.RestServer.RateLimit.Enabled {
+		v.oneOf("restServer.rateLimit.store", c.RestServer.RateLimit.Store, "", "memory", "mongo")
+		for i, apiKey := range c.RestServer.RateLimit.APIKeys {
+			if !sha256Hex.MatchString(apiKey) {
+				v.fail(fmt.Sprintf("restServer.rateLimit.apiKeys[%d]", i), "must be the SHA-256 hex digest of an API key")
+			}
+		}
+		for i, policy := range c.RestServer.RateLimit.Policies {
+			key := fmt.Sprintf("restServer.rateLimit.policies[%d]", i)
+			if policy.Name == "" {
+				v.fail(key+".name", "must be set")
+			}
+			v.oneOf(key+".algorithm", policy.Algorithm, "token_bucket", "sliding_window")
+			v.oneOf(key+".keyBy", policy.KeyBy, "ip", "user", "api_key")
+			v.positive(key+".limit", policy.Limit)
+			v.positive(key+".window", policy.Window)
+		}
+	}
+	v.notNegative("restServer.graphql.maxDepth", c.RestServer.GraphQL.MaxDepth)
+	v.notNegative("restServer.graphql.maxComplexity", c.RestServer.GraphQL.MaxComplexity)
+	v.notNegative("restServer.batch.maxOperations", c.RestServer.Batch.MaxOperations)
+	v.notNegative("restServer.events.replaySize", c.RestServer.Events.ReplaySize)
+	v.notNegative("restServer.events.subscriberBuffer", c.RestServer.Events.SubscriberBuffer)
+	v.notNegative("restServer.events.heartbeat", c.RestServer.Events.Heartbeat)
+
+	v.database(c.Database)
+	v.userStore(c.UserStore)
+	v.notNegative("migrations.lockTtl", c.Migrations.LockTTL)
+
+	v.notNegative("webhook.pollInterval", c.Webhook.PollInterval)
+	v.notNegative("webhook.timeout", c.Webhook.Timeout)
+	v.notNegative("webhook.maxAttempts", c.Webhook.MaxAttempts)
+	v.notNegative("webhook.initialBackoff", c.Webhook.InitialBackoff)
+	v.notNegative("webhook.maxBackoff", c.Webhook.MaxBackoff)
+	v.notNegative("webhook.batchSize", c.Webhook.BatchSize)
+	v.notNegative("outbox.pollInterval", c.Outbox.PollInterval)
+	v.notNegative("outbox.batchSize", c.Outbox.BatchSize)
+	v.notNegative("outbox.leaseTtl", c.Outbox.LeaseTTL)
+	v.notNegative("outbox.maxAttempts", c.Outbox.MaxAttempts)
+	v.notNegative("outbox.initialBackoff", c.Outbox.InitialBackoff)
+	v.notNegative("outbox.maxBackoff", c.Outbox.MaxBackoff)
+	v.brokers(c.Outbox)
+
+	v.oneOf("log.format", strings.ToLower(c.Log.Format), "", "json", "text")
+	v.oneOf("log.level", strings.ToLower(c.Log.Level), append([]string{""}, logLevels...)...)
+	for pkg, level := range c.Log.Packages {
+		v.oneOf("log.packages."+pkg, strings.ToLower(level), logLevels...)
+	}
+
+	v.notNegative("metrics.userCountInterval", c.Metrics.UserCountInterval)
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "", "none", "stdout", "file", "otlp")
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.fail("tracing.sampleRatio", fmt.Sprintf("must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+
+	v.notNegative("health.timeout", c.Health.Timeout)
+	v.notNegative("health.cacheTtl", c.Health.CacheTTL)
+	v.notNegative("health.workerTimeout", c.Health.WorkerTimeout)
+	v.notNegative("health.shutdownDelay", c.Health.ShutdownDelay)
+	if c.Health.MinFreeDisk < 0 {
+		v.fail("health.minFreeDisk", "must not be negative")
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// secret checks the JWT secret, production refuses short and placeholder secrets.
+func (v *validator) secret(mode string, secret string) {
+	switch {
+	case secret == "":
+		v.fail("restServer.jwt.secret", "must be set, e.g. with APP_REST_SERVER_JWT_SECRET_FILE")
+	case mode == ModeProduction && len(secret) < minProductionSecretLength:
+		v.fail("restServer.jwt.secret", fmt.Sprintf("is too weak for production, use at least %d random characters", minProductionSecretLength))
+	case mode == ModeProduction && slices.ContainsFunc(weakSecrets, func(weak string) bool {
+		return strings.Contains(strings.ToLower(secret), weak)
+	}):
+		v.fail("restServer.jwt.secret", "is too weak for production, it contains a placeholder")
+	}
+}
+
+// signingKeys checks that the key pair of cfg fits its algorithm and that the
+// keys belong together.
+func (v *validator) signingKeys(cfg JwtConfig) {
+	if cfg.PrivateKey == "" || cfg.PublicKey == "" {
+		v.fail("restServer.jwt", fmt.Sprintf("%s needs privateKey and publicKey, e.g. with APP_REST_SERVER_JWT_PRIVATE_KEY_FILE", cfg.Algorithm))
+		return
+	}
+	private, err := keypair.ParsePrivateKey(cfg.PrivateKey, cfg.Algorithm)
+	if err != nil {
+		v.fail("restServer.jwt.privateKey", fmt.Sprintf("is invalid: %v", err))
+	}
+	public, err := keypair.ParsePublicKey(cfg.PublicKey, cfg.Algorithm)
+	if err != nil {
+		v.fail("restServer.jwt.publicKey", fmt.Sprintf("is invalid: %v", err))
+	}
+	if private != nil && public != nil && !keypair.Match(private, public) {
+		v.fail("restServer.jwt.publicKey", "is not the public key of privateKey")
+	}
+}
+
+// validator collects the problems of a configuration.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(key string, problem string) {
+	v.errs = append(v.errs, fmt.Errorf("%s %s", key, problem))
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail(key, fmt.Sprintf("must be between 1 and 65535, got %d", port))
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	if value <= 0 {
+		v.fail(key, fmt.Sprintf("must be greater than 0, got %d", value))
+	}
+}
+
+func (v *validator) notNegative(key string, value int) {
+	if value < 0 {
+		v.fail(key, fmt.Sprintf("must not be negative, got %d", value))
+	}
+}
+
+func (v *validator) oneOf(key string, value string, allowed ...string) {
+	if slices.Contains(allowed, value) {
+		return
+	}
+	// the empty value is allowed for settings with a default, it is not worth listing
+	named := slices.DeleteFunc(slices.Clone(allowed), func(s string) bool { return s == "" })
+	v.fail(key, fmt.Sprintf("must be one of %s, got %q", strings.Join(named, ", "), value))
+}
+
+// file fails key when path is set but cannot be read.
+func (v *validator) file(key string, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.fail(key, fmt.Sprintf("cannot be read: %v", err))
+	}
+}
+
+// userStore validates the settings of the backend of the user store.
+func (v *validator) userStore(c UserStoreConfig) {
+	v.oneOf("userStore.backend", c.Backend, "", UserStoreMongo, UserStorePostgres, UserStoreSQLite)
+	switch c.Backend {
+	case UserStorePostgres:
+		if c.Postgres.URL == "" {
+			v.fail("userStore.postgres.url", "must be set for the postgres backend")
+		} else if !strings.HasPrefix(c.Postgres.URL, "postgres://") && !strings.HasPrefix(c.Postgres.URL, "postgresql://") {
+			v.fail("userStore.postgres.url", "must start with postgres:// or postgresql://")
+		}
+		v.notNegative("userStore.postgres.maxOpenConns", c.Postgres.MaxOpenConns)
+	case UserStoreSQLite:
+		if c.SQLite.Path == "" {
+			v.fail("userStore.sqlite.path", "must be set for the sqlite backend")
+		}
+	}
+}
+
+// brokers validates the settings of the enabled outbox sinks.
+func (v *validator) brokers(c OutboxConfig) {
+	if c.NATS.Enabled {
+		if !strings.HasPrefix(c.NATS.URL, "nats://") && !strings.HasPrefix(c.NATS.URL, "tls://") {
+			v.fail("outbox.nats.url", "must start with nats:// or tls://")
+		}
+		if c.NATS.SubjectPrefix == "" {
+			v.fail("outbox.nats.subjectPrefix", "must be set")
+		}
+		v.positive("outbox.nats.timeout", c.NATS.Timeout)
+	}
+	if c.Kafka.Enabled {
+		if len(c.Kafka.Brokers) == 0 {
+			v.fail("outbox.kafka.brokers", "must be set")
+		}
+		for i, broker := range c.Kafka.Brokers {
+			if _, port, err := net.SplitHostPort(broker); err != nil || port == "" {
+				v.fail(fmt.Sprintf("outbox.kafka.brokers[%d]", i), fmt.Sprintf("must be host:port, got %q", broker))
+			}
+		}
+		if c.Kafka.Topic == "" {
+			v.fail("outbox.kafka.topic", "must be set")
+		}
+		v.positive("outbox.kafka.timeout", c.Kafka.Timeout)
+	}
+}
+
+// database validates the MongoDB settings, the URI replaces the hosts.
+func (v *validator) database(c MongoConfig) {
+	switch {
+	case c.URI != "":
+		if !strings.HasPrefix(c.URI, "mongodb://") && !strings.HasPrefix(c.URI, "mongodb+srv://") {
+			v.fail("database.uri", "must start with mongodb:// or mongodb+srv://")
+		}
+	case len(c.Hosts) > 0:
+		for i, host := range c.Hosts {
+			if _, port, err := net.SplitHostPort(host); err != nil || port == "" {
+				v.fail(fmt.Sprintf("database.hosts[%d]", i), fmt.Sprintf("must be host:port, got %q", host))
+			}
+		}
+	default:
+		if c.Host == "" {
+			v.fail("database.host", "must be set, or database.uri or database.hosts")
+		}
+		v.port("database.port", c.Port)
+	}
+	if c.DatabaseName == "" {
+		v.fail("database.databaseName", "must be set")
+	}
+	if c.Password != "" && c.Username == "" && c.URI == "" {
+		v.fail("database.username", "must be set with database.password")
+	}
+	v.oneOf("database.readPreference", c.ReadPreference, "", "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest")
+	if w, err := strconv.Atoi(c.WriteConcern); c.WriteConcern != "" && c.WriteConcern != "majority" && (err != nil || w < 0) {
+		v.fail("database.writeConcern", fmt.Sprintf("must be majority or a number of nodes, got %q", c.WriteConcern))
+	}
+	if c.TLS.KeyFile != "" && c.TLS.CertFile == "" {
+		v.fail("database.tls.certFile", "must be set with database.tls.keyFile")
+	}
+	v.file("database.tls.caFile", c.TLS.CAFile)
+	v.file("database.tls.certFile", c.TLS.CertFile)
+	v.file("database.tls.keyFile", c.TLS.KeyFile)
+	v.notNegative("database.maxPoolSize", c.MaxPoolSize)
+	v.notNegative("database.connectionTimeout", c.ConnectionTimeout)
+	v.notNegative("database.serverSelectionTimeout", c.ServerSelectionTimeout)
+	v.notNegative("database.timeout", c.Timeout)
+}
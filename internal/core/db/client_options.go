@@ -0,0 +1,135 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NewClientOptions returns the client options of cfg: the options of the URI,
+// or the hosts, overridden by the fields that are set.
+func NewClientOptions(cfg config.MongoConfig) (*options.ClientOptions, error) {
+	clientOptions := options.Client()
+	switch {
+	case cfg.URI != "":
+		clientOptions.ApplyURI(cfg.URI)
+	case len(cfg.Hosts) > 0:
+		clientOptions.SetHosts(cfg.Hosts)
+	default:
+		clientOptions.SetHosts([]string{net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))})
+	}
+	if err := clientOptions.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MongoDB options: %w", err)
+	}
+
+	if cfg.Username != "" || cfg.Password != "" || cfg.AuthSource != "" || cfg.AuthMechanism != "" {
+		credential := options.Credential{}
+		if clientOptions.Auth != nil {
+			credential = *clientOptions.Auth
+		}
+		if cfg.Username != "" {
+			credential.Username = cfg.Username
+		}
+		if cfg.Password != "" {
+			credential.Password = cfg.Password
+			credential.PasswordSet = true
+		}
+		if cfg.AuthSource != "" {
+			credential.AuthSource = cfg.AuthSource
+		}
+		if cfg.AuthMechanism != "" {
+			credential.AuthMechanism = cfg.AuthMechanism
+		}
+		clientOptions.SetAuth(credential)
+	}
+	if cfg.ReplicaSet != "" {
+		clientOptions.SetReplicaSet(cfg.ReplicaSet)
+	}
+	if cfg.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		readPreference, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetReadPreference(readPreference)
+	}
+	if cfg.WriteConcern != "" {
+		clientOptions.SetWriteConcern(writeConcern(cfg.WriteConcern))
+	}
+	if cfg.RetryWrites != nil {
+		clientOptions.SetRetryWrites(*cfg.RetryWrites)
+	}
+	if cfg.TLS.Enabled {
+		tlsConfig, err := newTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+	if cfg.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(uint64(cfg.MaxPoolSize))
+	}
+	if cfg.ConnectionTimeout > 0 {
+		clientOptions.SetConnectTimeout(time.Duration(cfg.ConnectionTimeout) * time.Millisecond)
+	}
+	if cfg.ServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(time.Duration(cfg.ServerSelectionTimeout) * time.Millisecond)
+	}
+	if cfg.Timeout > 0 {
+		clientOptions.SetTimeout(time.Duration(cfg.Timeout) * time.Millisecond)
+	}
+	return clientOptions, nil
+}
+
+// writeConcern returns majority, or the concern of a number of nodes.
+func writeConcern(w string) *writeconcern.WriteConcern {
+	if nodes, err := strconv.Atoi(w); err == nil {
+		return &writeconcern.WriteConcern{W: nodes}
+	}
+	return writeconcern.Majority()
+}
+
+// newTLSConfig trusts the CA file, or the system roots, and presents the
+// client certificate when one is set.
+func newTLSConfig(cfg config.MongoTLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MongoDB CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			return nil, errors.New("MongoDB CA file has no PEM certificate")
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if cfg.CertFile != "" {
+		// MongoDB usually takes the certificate and its key in one file
+		keyFile := cfg.KeyFile
+		if keyFile == "" {
+			keyFile = cfg.CertFile
+		}
+		certificate, err := tls.LoadX509KeyPair(cfg.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MongoDB client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"go.mongodb.org/mongo-driver/v2/event"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+)
+
+// commandSpans holds the span of every command that did not finish yet, by
+// request id.
+var commandSpans sync.Map
+
+// commandMonitor traces every command sent to MongoDB as a client span of the
+// operation that sent it. The commands themselves are not recorded, they hold
+// personal data.
+var commandMonitor = &event.CommandMonitor{
+	Started: func(ctx context.Context, started *event.CommandStartedEvent) {
+		name := started.CommandName
+		attributes := []trace.SpanStartOption{
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameMongoDB,
+				semconv.DBNamespace(started.DatabaseName),
+				semconv.DBOperationName(started.CommandName),
+			),
+		}
+		// the value of the command name is the collection for collection commands
+		if collection, ok := started.Command.Lookup(started.CommandName).StringValueOK(); ok {
+			name += " " + collection
+			attributes = append(attributes, trace.WithAttributes(semconv.DBCollectionName(collection)))
+		}
+		_, span := tracing.StartChild(ctx, name, attributes...)
+		if span.IsRecording() {
+			commandSpans.Store(started.RequestID, span)
+		}
+	},
+	Succeeded: func(_ context.Context, succeeded *event.CommandSucceededEvent) {
+		if span, ok := commandSpans.LoadAndDelete(succeeded.RequestID); ok {
+			span.(trace.Span).End()
+		}
+	},
+	Failed: func(_ context.Context, failed *event.CommandFailedEvent) {
+		if span, ok := commandSpans.LoadAndDelete(failed.RequestID); ok {
+			tracing.End(span.(trace.Span), failed.Failure)
+		}
+	},
+}
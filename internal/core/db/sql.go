@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	// the drivers of the SQL user stores
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var sqlDB *sql.DB
+
+// InitializeSQL opens the database of the user store when it is PostgreSQL or
+// SQLite, it does nothing when the users are in MongoDB.
+func InitializeSQL() error {
+	cfg := config.GetConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Database.ConnectionTimeout)*time.Millisecond)
+	defer cancel()
+
+	database, err := OpenSQL(ctx, cfg.UserStore)
+	if err != nil {
+		return err
+	}
+	sqlDB = database
+	if sqlDB != nil {
+		log.InfoContext(ctx, "connected to the user store", "backend", cfg.UserStore.Backend)
+	}
+	return nil
+}
+
+// OpenSQL opens and pings the SQL database of cfg, it returns nil when the
+// backend is MongoDB.
+func OpenSQL(ctx context.Context, cfg config.UserStoreConfig) (*sql.DB, error) {
+	var database *sql.DB
+	var err error
+	switch cfg.Backend {
+	case config.UserStorePostgres:
+		database, err = sql.Open("pgx", cfg.Postgres.URL)
+		if err == nil {
+			database.SetMaxOpenConns(cfg.Postgres.MaxOpenConns)
+		}
+	case config.UserStoreSQLite:
+		database, err = sql.Open("sqlite3", sqliteDSN(cfg.SQLite.Path))
+		if err == nil {
+			// SQLite has one writer, a single connection saves waiting on its lock
+			database.SetMaxOpenConns(1)
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s user store: %w", cfg.Backend, err)
+	}
+	if err := database.PingContext(ctx); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to ping the %s user store: %w", cfg.Backend, err)
+	}
+	return database, nil
+}
+
+// sqliteDSN returns the data source of the SQLite file path. Writes wait for
+// the lock of another process instead of failing, and transactions take it
+// when they begin, so a read in them cannot go stale before their write.
+func sqliteDSN(path string) string {
+	query := url.Values{}
+	query.Set("_busy_timeout", "5000")
+	query.Set("_txlock", "immediate")
+	query.Set("_foreign_keys", "on")
+	return "file:" + path + "?" + query.Encode()
+}
+
+// PingSQL checks that the SQL database of the user store is reachable.
+func PingSQL(ctx context.Context) error {
+	return GetSQL().PingContext(ctx)
+}
+
+// GetSQL returns the SQL database of the user store, nil when the users are in
+// MongoDB.
+func GetSQL() *sql.DB {
+	return sqlDB
+}
+
+// Rebind returns query with the ? placeholders in the style of the backend,
+// PostgreSQL numbers them like $1. The queries have no ? in literals.
+func Rebind(backend string, query string) string {
+	if backend != config.UserStorePostgres {
+		return query
+	}
+	var rebound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			rebound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		rebound.WriteRune(r)
+	}
+	return rebound.String()
+}
@@ -0,0 +1,18 @@
+// Package eventsink publishes user events to external brokers. The outbox relay
+// publishes every event at least once, so consumers must deduplicate by event id.
+package eventsink
+
+import (
+	"encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+)
+
+// Headers sent with every message, the body is the JSON encoded event.
+const (
+	HeaderEventID   = "Event-Id"
+	HeaderEventType = "Event-Type"
+)
+
+func encode(event entity.Event) ([]byte, error) {
+	return json.Marshal(event)
+}
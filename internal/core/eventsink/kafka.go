@@ -0,0 +1,59 @@
+package eventsink
+
+import (
+	"context"
+	"github.com/segmentio/kafka-go"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"time"
+)
+
+// KafkaWriter is the part of *kafka.Writer that KafkaPublisher uses.
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewKafkaWriter returns a writer to topic that waits for every in-sync replica
+// to store a message. Messages with the same key go to the same partition.
+func NewKafkaWriter(brokers []string, topic string, timeout time.Duration) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// the relay publishes one event at a time, do not wait for a batch to fill
+		BatchSize:    1,
+		WriteTimeout: timeout,
+		ReadTimeout:  timeout,
+	}
+}
+
+// KafkaPublisher publishes events to a Kafka topic keyed by user id, so the
+// events of a user stay in order on one partition.
+type KafkaPublisher struct {
+	writer KafkaWriter
+}
+
+func NewKafkaPublisher(writer KafkaWriter) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event entity.Event) error {
+	data, err := encode(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.Data.ID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: HeaderEventID, Value: []byte(event.ID)},
+			{Key: HeaderEventType, Value: []byte(event.Type)},
+		},
+	})
+}
+
+// Close sends the buffered messages and closes the writer.
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
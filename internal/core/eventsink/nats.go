@@ -0,0 +1,73 @@
+package eventsink
+
+import (
+	"context"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"time"
+)
+
+// NATSOptions configures a NATSPublisher.
+type NATSOptions struct {
+	// SubjectPrefix is prepended to the event type, e.g. users.user.created.
+	SubjectPrefix string
+	// JetStream waits for a stream to store each event. Without it Publish waits
+	// for the server to receive the event, which is lost when nobody listens.
+	JetStream bool
+	Timeout   time.Duration
+}
+
+// NATSPublisher publishes events to a NATS subject per event type. The event id
+// is sent as Nats-Msg-Id, so JetStream drops the duplicates of a republished event.
+type NATSPublisher struct {
+	conn      *nats.Conn
+	jetStream jetstream.JetStream
+	options   NATSOptions
+}
+
+// NewNATSPublisher connects to the NATS server at url.
+func NewNATSPublisher(url string, options NATSOptions) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("user-service outbox relay"), nats.Timeout(options.Timeout), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	publisher := &NATSPublisher{conn: conn, options: options}
+	if options.JetStream {
+		publisher.jetStream, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("open jetstream: %w", err)
+		}
+	}
+	return publisher, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event entity.Event) error {
+	data, err := encode(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(p.options.SubjectPrefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Header.Set(HeaderEventID, event.ID)
+	msg.Header.Set(HeaderEventType, event.Type)
+	msg.Data = data
+
+	ctx, cancel := context.WithTimeout(ctx, p.options.Timeout)
+	defer cancel()
+	if p.jetStream != nil {
+		_, err := p.jetStream.PublishMsg(ctx, msg)
+		return err
+	}
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+// Close sends the buffered messages and closes the connection.
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
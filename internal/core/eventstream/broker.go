@@ -0,0 +1,203 @@
+package eventstream
+
+import (
+	"context"
+	"errors"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSlowConsumer ends a subscription whose buffer was full when an event was published.
+	ErrSlowConsumer = errors.New("eventstream: subscriber is too slow")
+	// ErrClosed ends the subscriptions of a closed broker.
+	ErrClosed = errors.New("eventstream: broker is closed")
+)
+
+// Message is an event with its position in the stream.
+type Message struct {
+	// ID is "<epoch>-<sequence>", clients send it back as Last-Event-ID to resume.
+	ID    string
+	Event entity.Event
+
+	sequence uint64
+}
+
+// Broker fans published events out to subscribers and keeps the last events for
+// subscribers that resume after a disconnect. Sequences restart with every
+// broker, the epoch in the message ids tells the streams of two brokers apart.
+type Broker struct {
+	epoch            string
+	replaySize       int
+	subscriberBuffer int
+
+	mu          sync.Mutex
+	sequence    uint64
+	replay      []Message
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroker returns a Broker that keeps the last replaySize events and buffers
+// up to subscriberBuffer events per subscriber.
+func NewBroker(replaySize int, subscriberBuffer int) *Broker {
+	if subscriberBuffer <= 0 {
+		subscriberBuffer = 1
+	}
+	return &Broker{
+		epoch:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		replaySize:       replaySize,
+		subscriberBuffer: subscriberBuffer,
+		subscribers:      map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends event to every matching subscriber without blocking. A
+// subscriber whose buffer is full is dropped with ErrSlowConsumer and can
+// resume from the replay buffer.
+func (b *Broker) Publish(_ context.Context, event entity.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	b.sequence++
+	message := Message{ID: b.epoch + "-" + strconv.FormatUint(b.sequence, 10), Event: event, sequence: b.sequence}
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			b.replay = slices.Delete(b.replay, 0, 1)
+		}
+		b.replay = append(b.replay, message)
+	}
+
+	for subscription := range b.subscribers {
+		if !subscription.matches(event.Type) {
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		default:
+			b.remove(subscription, ErrSlowConsumer)
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to the events of types, every type when
+// types is empty. With a lastEventID the buffered events after it are replayed
+// first, Reset is set when some of them are no longer buffered.
+func (b *Broker) Subscribe(lastEventID string, types []string) *Subscription {
+	subscription := &Subscription{
+		broker:   b,
+		types:    types,
+		messages: make(chan Message, b.subscriberBuffer),
+		done:     make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		subscription.err = ErrClosed
+		close(subscription.done)
+		return subscription
+	}
+
+	if lastEventID != "" {
+		sequence, ok := b.parseID(lastEventID)
+		switch {
+		case !ok || sequence > b.sequence:
+			subscription.Reset = true
+		case sequence < b.sequence:
+			// the replay buffer must still hold the event right after the last one seen
+			if len(b.replay) == 0 || b.replay[0].sequence > sequence+1 {
+				subscription.Reset = true
+				break
+			}
+			for _, message := range b.replay {
+				if message.sequence > sequence && subscription.matches(message.Event.Type) {
+					subscription.Replay = append(subscription.Replay, message)
+				}
+			}
+		}
+	}
+	b.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Close ends every subscription with ErrClosed, later subscriptions end at once.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for subscription := range b.subscribers {
+		b.remove(subscription, ErrClosed)
+	}
+}
+
+func (b *Broker) parseID(id string) (uint64, bool) {
+	epoch, sequence, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(sequence, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// remove must be called with b.mu held.
+func (b *Broker) remove(subscription *Subscription, err error) {
+	if _, ok := b.subscribers[subscription]; !ok {
+		return
+	}
+	delete(b.subscribers, subscription)
+	subscription.err = err
+	close(subscription.done)
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	// Replay holds the buffered events after the Last-Event-ID, send them before Messages.
+	Replay []Message
+	// Reset is set when the subscriber missed events that are no longer buffered
+	// and has to reload its state.
+	Reset bool
+
+	broker   *Broker
+	types    []string
+	messages chan Message
+	done     chan struct{}
+	err      error
+}
+
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Done is closed when the subscription ended, Err tells why.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes, it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s, nil)
+}
+
+func (s *Subscription) matches(eventType string) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, eventType)
+}
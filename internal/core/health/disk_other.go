@@ -0,0 +1,12 @@
+//go:build !unix
+
+package health
+
+import "context"
+
+// DiskSpace always passes, free space is only checked on unix systems.
+func DiskSpace(path string, minFree uint64) Checker {
+	return CheckerFunc(func(_ context.Context) error {
+		return nil
+	})
+}
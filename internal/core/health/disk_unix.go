@@ -0,0 +1,25 @@
+//go:build unix
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+// DiskSpace fails when the file system of path has less than minFree bytes
+// available.
+func DiskSpace(path string, minFree uint64) Checker {
+	return CheckerFunc(func(_ context.Context) error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(path, &stat); err != nil {
+			return err
+		}
+		available := stat.Bavail * uint64(stat.Bsize)
+		if available < minFree {
+			return fmt.Errorf("%d bytes available on %s, below %d", available, path, minFree)
+		}
+		return nil
+	})
+}
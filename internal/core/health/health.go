@@ -0,0 +1,192 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe is the question a check answers: Liveness checks fail when the process
+// must be restarted, Readiness checks when it should not get traffic.
+type Probe string
+
+const (
+	Liveness  Probe = "liveness"
+	Readiness Probe = "readiness"
+)
+
+// Status of a check or of a whole report.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+)
+
+// Defaults used for the zero arguments of NewRegistry.
+const (
+	defaultTimeout  = 2 * time.Second
+	defaultCacheTTL = time.Second
+)
+
+// shutdownCheck is the readiness check that fails once Shutdown was called.
+const shutdownCheck = "shutdown"
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// Checker is a dependency probe, it returns an error when the dependency is unhealthy.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a function used as a Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status    Status  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of every check of a probe, it passes when every check passed.
+type Report struct {
+	Status    Status                 `json:"status"`
+	CheckedAt time.Time              `json:"checkedAt"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+type cachedReport struct {
+	mu     sync.Mutex
+	report Report
+	valid  bool
+}
+
+// Registry runs the registered checks of a probe. Reports are cached for a
+// short time so frequent probes do not load the dependencies.
+type Registry struct {
+	mu           sync.RWMutex
+	checkers     map[Probe][]namedChecker
+	cache        map[Probe]*cachedReport
+	timeout      time.Duration
+	cacheTTL     time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry returns a Registry that gives every check timeout to finish and
+// caches reports for cacheTTL.
+func NewRegistry(timeout time.Duration, cacheTTL time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &Registry{
+		checkers: map[Probe][]namedChecker{},
+		cache: map[Probe]*cachedReport{
+			Liveness:  {},
+			Readiness: {},
+		},
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Register adds checker to probe under name.
+func (r *Registry) Register(probe Probe, name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[probe] = append(r.checkers[probe], namedChecker{name: name, checker: checker})
+}
+
+// Shutdown makes readiness fail from now on, so load balancers stop sending
+// traffic before the server stops.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Run returns the report of probe, from the cache when it is recent enough.
+func (r *Registry) Run(ctx context.Context, probe Probe) Report {
+	if probe == Readiness && r.shuttingDown.Load() {
+		return Report{
+			Status:    StatusFail,
+			CheckedAt: time.Now(),
+			Checks:    map[string]CheckResult{shutdownCheck: {Status: StatusFail, Error: errShuttingDown.Error()}},
+		}
+	}
+
+	cached := r.cache[probe]
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	if cached.valid && time.Since(cached.report.CheckedAt) < r.cacheTTL {
+		return cached.report
+	}
+	// the report is shared, the request that happens to run it must not cancel it
+	cached.report = r.run(context.WithoutCancel(ctx), probe)
+	cached.valid = true
+	return cached.report
+}
+
+// run runs the checks of probe in parallel.
+func (r *Registry) run(ctx context.Context, probe Probe) Report {
+	r.mu.RLock()
+	checkers := r.checkers[probe]
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusPass, CheckedAt: time.Now(), Checks: make(map[string]CheckResult, len(checkers))}
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.check(ctx, checker.checker)
+		}()
+	}
+	wg.Wait()
+
+	for i, checker := range checkers {
+		report.Checks[checker.name] = results[i]
+		if results[i].Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// check runs checker until the timeout, a checker that ignores its ctx fails
+// on time all the same.
+func (r *Registry) check(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckResult{Status: StatusPass, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
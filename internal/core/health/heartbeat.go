@@ -0,0 +1,35 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat is a Checker for a background worker, it fails when the worker did
+// not beat for longer than maxAge.
+type Heartbeat struct {
+	last   atomic.Int64
+	maxAge time.Duration
+}
+
+// NewHeartbeat returns a Heartbeat that counts as beaten now.
+func NewHeartbeat(maxAge time.Duration) *Heartbeat {
+	heartbeat := &Heartbeat{maxAge: maxAge}
+	heartbeat.Beat()
+	return heartbeat
+}
+
+// Beat records that the worker is still making progress.
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+func (h *Heartbeat) Check(_ context.Context) error {
+	age := time.Since(time.Unix(0, h.last.Load()))
+	if age > h.maxAge {
+		return fmt.Errorf("no heartbeat for %s", age.Round(time.Second))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+type catalog struct {
+	Messages map[string]string `json:"messages"`
+	Fields   map[string]string `json:"fields"`
+}
+
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]catalog {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read locale catalogs: %v", err))
+	}
+
+	catalogs := make(map[string]catalog, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(fmt.Sprintf("failed to read locale catalog %s: %v", entry.Name(), err))
+		}
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("failed to parse locale catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = c
+	}
+	return catalogs
+}
+
+// SupportedLocales returns the locales that have a message catalog, sorted.
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// falling back to DefaultLocale.
+func Negotiate(acceptLanguage string) string {
+	best := DefaultLocale
+	bestQuality := 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := parseLanguageRange(part)
+		if tag == "" || quality <= bestQuality {
+			continue
+		}
+		// match "th-TH" against the "th" catalog
+		base := strings.SplitN(tag, "-", 2)[0]
+		if IsSupported(base) {
+			best = base
+			bestQuality = quality
+		}
+	}
+	return best
+}
+
+func parseLanguageRange(part string) (string, float64) {
+	fields := strings.Split(strings.TrimSpace(part), ";")
+	tag := strings.ToLower(strings.TrimSpace(fields[0]))
+	quality := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if value, ok := strings.CutPrefix(param, "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", 0
+			}
+			quality = q
+		}
+	}
+	return tag, quality
+}
+
+// Translate renders the message for code in locale, replacing {name} placeholders
+// with params. It reports false when neither locale nor the default has the code.
+func Translate(locale string, code string, params map[string]string) (string, bool) {
+	template, ok := catalogs[locale].Messages[code]
+	if !ok {
+		template, ok = catalogs[DefaultLocale].Messages[code]
+		if !ok {
+			return "", false
+		}
+	}
+	for name, value := range params {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+	return template, true
+}
+
+// FieldLabel returns the human-readable name of a request field in locale,
+// falling back to the field name itself.
+func FieldLabel(locale string, field string) string {
+	if label, ok := catalogs[locale].Fields[field]; ok {
+		return label
+	}
+	return field
+}
@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*Record
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns a Store kept in process memory, suitable for a single replica.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]*Record),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Begin(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && record.ExpiresAt.After(now) {
+		if record.Fingerprint == fingerprint && !record.Completed {
+			return nil, ErrInProgress
+		}
+		copied := *record
+		return &copied, nil
+	}
+
+	s.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(lockTTL),
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte, subject string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	record.Completed = true
+	record.StatusCode = statusCode
+	record.Header = header.Clone()
+	record.Body = append([]byte(nil), body...)
+	record.Subject = subject
+	record.ExpiresAt = s.now().Add(ttl)
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && !record.Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// sweep drops expired records at most once a minute to keep the map bounded,
+// Begin ignores an expired record that is still there.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, key)
+		}
+	}
+}
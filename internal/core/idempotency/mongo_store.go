@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"net/http"
+	"time"
+)
+
+type mongoStore struct {
+	mongoCollection *mongo.Collection
+}
+
+// NewMongoStore returns a Store shared by every replica using the same collection.
+// Expired records are removed by a TTL index on expires_at.
+func NewMongoStore(ctx context.Context, mongoCollection *mongo.Collection) (Store, error) {
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_index"),
+	}
+	if _, err := mongoCollection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return nil, fmt.Errorf("failed to create idempotency TTL index: %w", err)
+	}
+	return &mongoStore{
+		mongoCollection: mongoCollection,
+	}, nil
+}
+
+func (s *mongoStore) Begin(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	// the TTL monitor runs about once a minute, so expired records may still be there
+	_, err := s.mongoCollection.DeleteOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		return nil, err
+	}
+
+	// inserting with the key as _id is the lock, the unique index rejects a second owner
+	_, err = s.mongoCollection.InsertOne(ctx, Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   time.Now().Add(lockTTL),
+	})
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	var record Record
+	err = s.mongoCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&record)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// released between our insert and find, let the client retry
+			return nil, ErrInProgress
+		}
+		return nil, err
+	}
+	if record.Fingerprint == fingerprint && !record.Completed {
+		return nil, ErrInProgress
+	}
+	return &record, nil
+}
+
+func (s *mongoStore) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte, subject string, ttl time.Duration) error {
+	_, err := s.mongoCollection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{
+		"completed":   true,
+		"status_code": statusCode,
+		"header":      header,
+		"body":        body,
+		"subject":     subject,
+		"expires_at":  time.Now().Add(ttl),
+	}})
+	return err
+}
+
+func (s *mongoStore) Release(ctx context.Context, key string) error {
+	_, err := s.mongoCollection.DeleteOne(ctx, bson.M{"_id": key, "completed": false})
+	return err
+}
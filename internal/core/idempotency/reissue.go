@@ -0,0 +1,30 @@
+package idempotency
+
+import "context"
+
+type reissueKey struct{}
+
+// Reissue lets the handler of a response carrying a credential, like an
+// access token, have it replayed without keeping the credential: the
+// response is stored without its body and the handler issues a fresh
+// credential to Subject when a retry replays it.
+type Reissue struct {
+	// Subject is who the credential was issued to, like the user id. The
+	// handler sets it on the first request, a response without one is not
+	// stored.
+	Subject string
+	// Replay tells the handler that the request retries a completed one and
+	// must only issue a credential to Subject.
+	Replay bool
+}
+
+// ContextWithReissue returns a copy of ctx carrying reissue.
+func ContextWithReissue(ctx context.Context, reissue *Reissue) context.Context {
+	return context.WithValue(ctx, reissueKey{}, reissue)
+}
+
+// ReissueFromContext returns the Reissue of an idempotent request, if any.
+func ReissueFromContext(ctx context.Context) (*Reissue, bool) {
+	reissue, ok := ctx.Value(reissueKey{}).(*Reissue)
+	return reissue, ok
+}
@@ -0,0 +1,41 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInProgress is returned by Begin while another request with the same key is still running.
+	ErrInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// Record is the stored outcome of the first request made with an idempotency key.
+type Record struct {
+	Key         string      `bson:"_id"`
+	Fingerprint string      `bson:"fingerprint"`
+	Completed   bool        `bson:"completed"`
+	StatusCode  int         `bson:"status_code"`
+	Header      http.Header `bson:"header"`
+	Body        []byte      `bson:"body"`
+	Subject     string      `bson:"subject,omitempty"`
+	ExpiresAt   time.Time   `bson:"expires_at"`
+}
+
+// Store keeps idempotency records. Begin acts as a lock: only one caller can
+// hold an unfinished record for a key until it is completed, released or expired.
+type Store interface {
+	// Begin reserves key for a new request for lockTTL, so the key of a request
+	// that never finished, like one of a crashed replica, frees up soon. It
+	// returns nil when the caller now owns the key, the existing record when the
+	// key was already used, or ErrInProgress.
+	Begin(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (*Record, error)
+	// Complete stores the response of the request that owns key for ttl. A
+	// response carrying a credential is stored without its body and with the
+	// subject the credential is reissued to on replay.
+	Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte, subject string, ttl time.Duration) error
+	// Release drops an unfinished reservation so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type fieldsKey struct{}
+
+// fields are the request fields added to every record logged with the request
+// context. Route and user are only known further down the handler chain, so
+// they are set on the shared fields instead of a new context.
+type fields struct {
+	mu        sync.Mutex
+	requestID string
+	method    string
+	route     string
+	userID    string
+}
+
+// NewContext returns ctx carrying the request fields of one request.
+func NewContext(ctx context.Context, requestID string, method string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &fields{requestID: requestID, method: method})
+}
+
+// SetRoute records the route pattern that matched the request of ctx.
+func SetRoute(ctx context.Context, route string) {
+	if f := fieldsFrom(ctx); f != nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.route = route
+	}
+}
+
+// Route returns the route pattern that matched the request of ctx, empty when
+// none matched yet.
+func Route(ctx context.Context) string {
+	f := fieldsFrom(ctx)
+	if f == nil {
+		return ""
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.route
+}
+
+// SetUserID records the authenticated user of the request of ctx.
+func SetUserID(ctx context.Context, userID string) {
+	if f := fieldsFrom(ctx); f != nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.userID = userID
+	}
+}
+
+func fieldsFrom(ctx context.Context) *fields {
+	if ctx == nil {
+		return nil
+	}
+	f, _ := ctx.Value(fieldsKey{}).(*fields)
+	return f
+}
+
+func (f *fields) attrs() []slog.Attr {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attrs := []slog.Attr{slog.String("request_id", f.requestID), slog.String("method", f.method)}
+	if f.route != "" {
+		attrs = append(attrs, slog.String("route", f.route))
+	}
+	if f.userID != "" {
+		attrs = append(attrs, slog.String("user_id", f.userID))
+	}
+	return attrs
+}
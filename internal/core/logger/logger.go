@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted replaces the values of redacted attributes.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are the attribute keys redacted when Options.RedactKeys is empty.
+var DefaultRedactKeys = []string{"email", "password", "name", "token", "accessToken", "authorization", "secret"}
+
+// emailPattern finds addresses inside messages and errors, e.g. duplicate key errors.
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Options configures the loggers.
+type Options struct {
+	// Format is json or text.
+	Format string
+	// Level is the lowest level logged, debug, info, warn or error.
+	Level string
+	// Packages overrides Level for the loggers of single packages.
+	Packages map[string]string
+	// RedactKeys are attribute keys, matched case-insensitively, whose values are never written.
+	RedactKeys []string
+}
+
+type state struct {
+	handler  slog.Handler
+	level    slog.Level
+	packages map[string]slog.Level
+}
+
+var current atomic.Pointer[state]
+
+func init() {
+	if err := Configure(Options{}, os.Stderr); err != nil {
+		panic(err)
+	}
+}
+
+// Configure replaces the output of every logger, including the ones created before.
+func Configure(options Options, w io.Writer) error {
+	level, err := parseLevel(options.Level)
+	if err != nil {
+		return err
+	}
+	packages := map[string]slog.Level{}
+	for name, value := range options.Packages {
+		packages[name], err = parseLevel(value)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+	}
+
+	redactKeys := options.RedactKeys
+	if len(redactKeys) == 0 {
+		redactKeys = DefaultRedactKeys
+	}
+	handlerOptions := &slog.HandlerOptions{
+		// the package loggers filter the levels
+		Level:       slog.Level(-1 << 10),
+		ReplaceAttr: redact(redactKeys),
+	}
+	var handler slog.Handler
+	switch strings.ToLower(options.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	case "text":
+		handler = slog.NewTextHandler(w, handlerOptions)
+	default:
+		return fmt.Errorf("unknown log format %q", options.Format)
+	}
+
+	current.Store(&state{handler: handler, level: level, packages: packages})
+	return nil
+}
+
+// New returns the logger of a package. Its records carry the package name, the
+// request fields and the trace of the context they are logged with.
+func New(pkg string) *slog.Logger {
+	return slog.New(&packageHandler{pkg: pkg})
+}
+
+func parseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", value)
+	}
+	return level, nil
+}
+
+// RedactEmails replaces the email addresses in s.
+func RedactEmails(s string) string {
+	return emailPattern.ReplaceAllString(s, Redacted)
+}
+
+func redact(keys []string) func(groups []string, attr slog.Attr) slog.Attr {
+	return func(_ []string, attr slog.Attr) slog.Attr {
+		if slices.ContainsFunc(keys, func(key string) bool { return strings.EqualFold(key, attr.Key) }) {
+			return slog.String(attr.Key, Redacted)
+		}
+		switch value := attr.Value.Any().(type) {
+		case string:
+			if emailPattern.MatchString(value) {
+				return slog.String(attr.Key, RedactEmails(value))
+			}
+		case error:
+			if message := value.Error(); emailPattern.MatchString(message) {
+				return slog.String(attr.Key, RedactEmails(message))
+			}
+		}
+		return attr
+	}
+}
+
+// packageHandler resolves the configured handler on every record, so loggers
+// created at package initialization follow Configure.
+type packageHandler struct {
+	pkg string
+	// ops are the WithAttrs and WithGroup calls made on the logger, in order.
+	ops []func(slog.Handler) slog.Handler
+}
+
+func (h *packageHandler) Enabled(_ context.Context, level slog.Level) bool {
+	s := current.Load()
+	minimum, ok := s.packages[h.pkg]
+	if !ok {
+		minimum = s.level
+	}
+	return level >= minimum
+}
+
+func (h *packageHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := []slog.Attr{slog.String("package", h.pkg)}
+	attrs = append(attrs, fieldsFrom(ctx).attrs()...)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	handler := current.Load().handler.WithAttrs(attrs)
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *packageHandler) with(op func(slog.Handler) slog.Handler) *packageHandler {
+	return &packageHandler{pkg: h.pkg, ops: append(slices.Clip(h.ops), op)}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RouteUnmatched labels requests that matched no route, so unknown paths do not
+// create new series.
+const RouteUnmatched = "unmatched"
+
+// Registry holds every metric of the service, next to the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests by route pattern and status.",
+	}, []string{"route", "status"})
+	httpRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route pattern and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	dbOperationDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_operation_duration_seconds",
+		Help:    "Latency of MongoDB operations by repository method.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+	dbOperationErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_operation_errors_total",
+		Help: "Number of failed MongoDB operations by repository method.",
+	}, []string{"repository", "method"})
+
+	// DBPoolOpenConnections is the number of connections in the MongoDB pool.
+	DBPoolOpenConnections = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "mongodb_pool_open_connections",
+		Help: "Number of open connections in the MongoDB connection pool.",
+	})
+	// DBPoolInUseConnections is the number of connections checked out of the MongoDB pool.
+	DBPoolInUseConnections = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "mongodb_pool_in_use_connections",
+		Help: "Number of connections checked out of the MongoDB connection pool.",
+	})
+	// DBPoolCheckoutFailures counts the times no connection could be checked out.
+	DBPoolCheckoutFailures = factory.NewCounter(prometheus.CounterOpts{
+		Name: "mongodb_pool_checkout_failures_total",
+		Help: "Number of failed connection checkouts from the MongoDB connection pool.",
+	})
+
+	userLogins = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_logins_total",
+		Help: "Number of user logins by result, success or failure.",
+	}, []string{"result"})
+	outboxDeadEvents = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_dead_events_total",
+		Help: "Number of outbox events that used up their publish attempts, by event type.",
+	}, []string{"type"})
+	// RegisteredUsers is the number of users in the database, refreshed in the background.
+	RegisteredUsers = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "registered_users",
+		Help: "Number of registered users.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// Handler serves the metrics of Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest records a request served by the route pattern.
+func ObserveHTTPRequest(route string, status int, duration time.Duration) {
+	if route == "" {
+		route = RouteUnmatched
+	}
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(route, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(route, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveDBOperation records a repository method that started at start, failed
+// tells whether it counts as an error.
+func ObserveDBOperation(repository string, method string, start time.Time, failed bool) {
+	dbOperationDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	if failed {
+		dbOperationErrors.WithLabelValues(repository, method).Inc()
+	}
+}
+
+// ObserveOutboxDeadEvent records an outbox event of eventType that is no longer published.
+func ObserveOutboxDeadEvent(eventType string) {
+	outboxDeadEvents.WithLabelValues(eventType).Inc()
+}
+
+// ObserveLogin records the result of a login attempt.
+func ObserveLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	userLogins.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/idempotency"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	errInvalidIdempotencyKey = apperror.Validation(apperror.CodeInvalidIdempotencyKey, "Idempotency-Key must be between 1 and 255 characters")
+	errIdempotencyKeyReused  = apperror.New(apperror.KindUnprocessable, apperror.CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+	errIdempotencyInProgress = apperror.Conflict(apperror.CodeIdempotencyInProgress, "a request with this Idempotency-Key is still in progress")
+)
+
+// IdempotencyOptions configures IdempotencyMiddleware.
+type IdempotencyOptions struct {
+	// TTL is how long a response is kept for retries.
+	TTL time.Duration
+	// LockTTL is how long a key stays locked by a request that does not finish.
+	LockTTL time.Duration
+	// TrustForwardedFor takes the client IP of anonymous requests from
+	// X-Forwarded-For, set it behind a proxy.
+	TrustForwardedFor bool
+}
+
+// IdempotencyMiddleware makes POST, PATCH and DELETE requests carrying an
+// Idempotency-Key header safe to retry. The first response is stored per key and
+// principal and replayed for identical retries. Protected routes must be
+// wrapped inside JwtMiddleware so the principal is known, the keys of
+// anonymous requests are scoped to the client IP. A response with
+// Cache-Control: no-store, like one carrying an access token, is only stored
+// when its handler set the subject of the idempotency.Reissue in the request
+// context, it is then kept without its body and a retry runs the handler again
+// to issue a fresh credential.
+func IdempotencyMiddleware(store idempotency.Store, options IdempotencyOptions) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" || !isIdempotentMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				json.ResponseWithProblem(w, r, errInvalidIdempotencyKey)
+				return
+			}
+
+			body, err := codec.ReadBody(w, r)
+			if err != nil {
+				json.ResponseWithProblem(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := idempotencyStoreKey(r, key, options.TrustForwardedFor)
+			fingerprint := requestFingerprint(r, body)
+
+			record, err := store.Begin(r.Context(), storeKey, fingerprint, options.LockTTL)
+			if err != nil {
+				if errors.Is(err, idempotency.ErrInProgress) {
+					w.Header().Set("Retry-After", "1")
+					json.ResponseWithProblem(w, r, errIdempotencyInProgress)
+					return
+				}
+				json.ResponseWithProblem(w, r, apperror.Internal(err))
+				return
+			}
+			if record != nil {
+				if record.Fingerprint != fingerprint {
+					json.ResponseWithProblem(w, r, errIdempotencyKeyReused)
+					return
+				}
+				if record.Subject != "" {
+					w.Header().Set(HeaderIdempotencyReplayed, "true")
+					reissue := &idempotency.Reissue{Subject: record.Subject, Replay: true}
+					next.ServeHTTP(w, r.WithContext(idempotency.ContextWithReissue(r.Context(), reissue)))
+					return
+				}
+				replayResponse(w, r, record)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			completed := false
+			defer func() {
+				// release the key when the handler failed so the client can retry
+				if !completed {
+					if err := store.Release(context.WithoutCancel(r.Context()), storeKey); err != nil {
+						log.ErrorContext(r.Context(), "failed to release idempotency key", "error", err)
+					}
+				}
+			}()
+
+			reissue := &idempotency.Reissue{}
+			next.ServeHTTP(recorder, r.WithContext(idempotency.ContextWithReissue(r.Context(), reissue)))
+
+			if recorder.statusCode >= http.StatusInternalServerError {
+				return
+			}
+			response := recorder.body.Bytes()
+			if noStore(recorder.Header()) {
+				if reissue.Subject == "" {
+					return
+				}
+				response = nil
+			} else {
+				reissue.Subject = ""
+			}
+			header := recorder.Header().Clone()
+			header.Del(HeaderRequestID)
+			if err := store.Complete(context.WithoutCancel(r.Context()), storeKey, recorder.statusCode, header, response, reissue.Subject, options.TTL); err != nil {
+				log.ErrorContext(r.Context(), "failed to store idempotent response", "error", err)
+				return
+			}
+			completed = true
+		}
+	}
+}
+
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch || method == http.MethodDelete
+}
+
+// idempotencyStoreKey scopes a client key to the principal and route so keys of
+// different users never collide, anonymous clients are told apart by IP.
+func idempotencyStoreKey(r *http.Request, key string, trustForwardedFor bool) string {
+	principal, ok := r.Context().Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || principal == "" {
+		principal = "anonymous@" + clientIP(r, trustForwardedFor)
+	}
+	return principal + ":" + r.Method + ":" + r.URL.Path + ":" + key
+}
+
+// noStore tells whether header forbids keeping the response.
+func noStore(header http.Header) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, r *http.Request, record *idempotency.Record) {
+	for name, values := range record.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderIdempotencyReplayed, "true")
+	w.WriteHeader(record.StatusCode)
+	if _, err := w.Write(record.Body); err != nil {
+		log.ErrorContext(r.Context(), "failed to replay idempotent response", "error", err)
+	}
+}
+
+// responseRecorder passes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the flusher and hijacker underneath.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
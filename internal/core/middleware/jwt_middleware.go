@@ -3,38 +3,92 @@ package middleware
 import (
 	"context"
 	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
-	"log"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
 	"net/http"
 	"strings"
 )
 
+var (
+	errUnauthorized = apperror.Unauthorized(apperror.CodeUnauthorized, "Unauthorized")
+	errForbidden    = apperror.Forbidden(apperror.CodeForbidden, "Forbidden")
+)
+
 func JwtMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" || strings.HasPrefix("Bearer ", authHeader) {
-			log.Println("Missing or malformed Authorization header")
-			json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+		ctx, ok := authenticate(r)
+		if !ok {
+			json.ResponseWithProblem(w, r, errUnauthorized)
 			return
 		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == "" {
-			log.Println("Invalid token")
-			json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+// OptionalJwtMiddleware authenticates the request like JwtMiddleware when it has
+// an Authorization header and lets anonymous requests through, so the handler
+// decides which operations need a user.
+func OptionalJwtMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
+		ctx, ok := authenticate(r)
+		if !ok {
+			json.ResponseWithProblem(w, r, errUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
 
-		claim, err := jwt.ValidateJwt(token)
-		if err != nil {
-			log.Println("Token validation failed:", err)
-			json.ResponseWithError(w, "Unauthorized", http.StatusUnauthorized)
+// AdminMiddleware lets only admins through, it must run after JwtMiddleware.
+func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, _ := r.Context().Value(constant.CONTEXT_KEY_USER_ROLE).(string)
+		if role != entity.RoleAdmin {
+			json.ResponseWithProblem(w, r, errForbidden)
 			return
 		}
+		next.ServeHTTP(w, r)
+	}
+}
 
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_ID, claim.UserId)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// authenticate validates the bearer token of r and returns its context with the
+// user id and the user's locale.
+func authenticate(r *http.Request) (context.Context, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		log.DebugContext(r.Context(), "missing or malformed Authorization header")
+		return nil, false
+	}
+	if token == "" {
+		log.DebugContext(r.Context(), "invalid token")
+		return nil, false
+	}
+
+	claim, err := jwt.ValidateJwt(token)
+	if err != nil {
+		log.DebugContext(r.Context(), "token validation failed", "error", err)
+		return nil, false
+	}
+	if err := jwt.CheckRevoked(r.Context(), claim); err != nil {
+		log.DebugContext(r.Context(), "token revoked", "error", err)
+		return nil, false
+	}
+
+	ctx := r.Context()
+	logger.SetUserID(ctx, claim.UserId)
+	ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_ID, claim.UserId)
+	ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_ROLE, claim.Role)
+	// the user's saved preference wins over the negotiated Accept-Language
+	if i18n.IsSupported(claim.Locale) {
+		ctx = context.WithValue(ctx, constant.CONTEXT_KEY_LOCALE, claim.Locale)
 	}
+	return ctx, true
 }
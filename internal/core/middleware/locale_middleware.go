@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"net/http"
+)
+
+// LocaleMiddleware negotiates the response locale from the Accept-Language header.
+// JwtMiddleware overrides it with the user's preferred locale on protected routes.
+func LocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		ctx := context.WithValue(r.Context(), constant.CONTEXT_KEY_LOCALE, locale)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,16 +1,70 @@
 package middleware
 
 import (
-	"log"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
+// LoggingMiddleware gives every request a logger context with the request id
+// and method, and writes one access log line when the response is done. It
+// must run inside RequestIDMiddleware.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		now := time.Now()
-		log.Printf("Request: %s %s", r.Method, r.URL.Path)
-		next.ServeHTTP(w, r)
-		log.Println("Response in ", time.Since(now))
+		start := time.Now()
+		requestID, _ := r.Context().Value(constant.CONTEXT_KEY_REQUEST_ID).(string)
+		ctx := logger.NewContext(r.Context(), requestID, r.Method)
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if recorder.status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		log.LogAttrs(ctx, level, "request completed",
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status()),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
 	})
 }
+
+// statusRecorder keeps the status code and size of a response it passes through.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int64
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the flusher and hijacker underneath.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// status is the status code sent, 200 when the handler wrote nothing.
+func (r *statusRecorder) status() int {
+	if r.statusCode == 0 {
+		return http.StatusOK
+	}
+	return r.statusCode
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"net/http"
+	"time"
+)
+
+// MetricsMiddleware counts the requests and their latency by route pattern and
+// status. It must run inside LoggingMiddleware, which holds the matched route.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		metrics.ObserveHTTPRequest(logger.Route(r.Context()), recorder.status(), time.Since(start))
+	})
+}
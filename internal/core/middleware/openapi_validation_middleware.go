@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/taninchot-work/backend-challenge/internal/core/openapi"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"io"
+	"net/http"
+)
+
+// OpenAPIValidationOptions configures OpenAPIValidationMiddleware.
+type OpenAPIValidationOptions struct {
+	// ValidateRequests rejects requests that do not match the document.
+	ValidateRequests bool
+	// ValidateResponses checks every response against the document, meant for tests.
+	ValidateResponses bool
+	// OnInvalidResponse is called for a response that does not match the document.
+	// It defaults to logging the mismatch.
+	OnInvalidResponse func(r *http.Request, err error)
+}
+
+// OpenAPIValidationMiddleware rejects requests whose parameters, content type or
+// body do not match the documented operation before they reach the handlers, a
+// protected operation without a valid token is answered with 401 first, and
+// optionally reports responses that do not match it. Requests for undocumented
+// routes are passed through unchanged.
+func OpenAPIValidationMiddleware(validator *openapi.Validator, options OpenAPIValidationOptions) func(next http.Handler) http.Handler {
+	if options.OnInvalidResponse == nil {
+		options.OnInvalidResponse = func(r *http.Request, err error) {
+			log.WarnContext(r.Context(), "response does not match the OpenAPI document", "path", r.URL.Path, "error", err)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			operation, pathParams, ok := validator.Operation(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if options.ValidateRequests {
+				// authentication comes first, a client without a valid token
+				// learns that it must log in rather than what its body lacks
+				if len(operation.Security) > 0 {
+					if _, ok := authenticate(r); !ok {
+						json.ResponseWithProblem(w, r, errUnauthorized)
+						return
+					}
+				}
+				var body []byte
+				if operation.RequestBody != nil {
+					var err error
+					body, err = codec.ReadBody(w, r)
+					if err != nil {
+						json.ResponseWithProblem(w, r, err)
+						return
+					}
+					r.Body = io.NopCloser(bytes.NewReader(body))
+				}
+				if err := validator.ValidateRequest(r, operation, pathParams, body); err != nil {
+					json.ResponseWithProblem(w, r, err)
+					return
+				}
+			}
+
+			if !options.ValidateResponses || isStreaming(operation) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if err := validator.ValidateResponse(operation, recorder.statusCode, recorder.Header(), recorder.body.Bytes()); err != nil {
+				options.OnInvalidResponse(r, err)
+			}
+		})
+	}
+}
+
+// isStreaming reports whether the operation responds with an event stream, which
+// is never complete and cannot be buffered for validation.
+func isStreaming(operation *openapi.Operation) bool {
+	response, ok := operation.Responses["200"]
+	if !ok {
+		return false
+	}
+	_, ok = response.Content["text/event-stream"]
+	return ok
+}
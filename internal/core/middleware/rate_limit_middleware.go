@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+var errRateLimitExceeded = apperror.New(apperror.KindTooManyRequests, apperror.CodeRateLimitExceeded, "too many requests, please retry later")
+
+// RateLimitOptions configure RateLimitMiddleware.
+type RateLimitOptions struct {
+	// TrustForwardedFor keys by the first X-Forwarded-For entry, only set it behind a proxy that sets it
+	TrustForwardedFor bool
+	// APIKeys are the SHA-256 hex digests of the API keys that get a budget of their own
+	APIKeys []string
+}
+
+// RateLimitMiddleware enforces the limiter's policies and reports the budget
+// with RateLimit-* headers. Requests are keyed by client IP, user ID or API key
+// as configured per policy, falling back to the client IP when the key is
+// missing, unknown, or the token is invalid or revoked. The store failing never
+// blocks traffic, a counter too contended to update does reject the request.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, options RateLimitOptions) func(next http.Handler) http.Handler {
+	apiKeys := make(map[string]bool, len(options.APIKeys))
+	for _, apiKey := range options.APIKeys {
+		apiKeys[strings.ToLower(apiKey)] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy, ok := limiter.Match(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.Take(r.Context(), policy, rateLimitKey(r, policy.KeyBy, apiKeys, options.TrustForwardedFor))
+			if err != nil {
+				log.ErrorContext(r.Context(), "rate limit store failed", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", deltaSeconds(result.Reset))
+			w.Header().Set("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+deltaSeconds(policy.Window))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", deltaSeconds(result.RetryAfter))
+				json.ResponseWithProblem(w, r, errRateLimitExceeded)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns the key of r for keyBy. A client could get a fresh
+// budget per request with made-up keys, so only a valid, unrevoked token and a
+// known API key count, other requests are keyed by their IP.
+func rateLimitKey(r *http.Request, keyBy ratelimit.KeyBy, apiKeys map[string]bool, trustForwardedFor bool) string {
+	switch keyBy {
+	case ratelimit.KeyByUser:
+		// the middleware runs before routing, so the token is checked here as well
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && token != "" {
+			if claim, err := jwt.ValidateJwt(token); err == nil && jwt.CheckRevoked(r.Context(), claim) == nil {
+				return claim.UserId
+			}
+		}
+	case ratelimit.KeyByAPIKey:
+		if apiKey := r.Header.Get(HeaderAPIKey); apiKey != "" {
+			sum := sha256.Sum256([]byte(apiKey))
+			if digest := hex.EncodeToString(sum[:]); apiKeys[digest] {
+				return digest
+			}
+		}
+	}
+	return "ip:" + clientIP(r, trustForwardedFor)
+}
+
+// clientIP returns the remote address, or the first X-Forwarded-For entry when
+// the server runs behind a trusted proxy.
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func deltaSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(max(d, 0).Seconds())))
+}
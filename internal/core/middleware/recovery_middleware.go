@@ -1,8 +1,11 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
 	"net/http"
+	"runtime/debug"
 )
 
 func RecoveryMiddleware(next http.Handler) http.Handler {
@@ -10,8 +13,8 @@ func RecoveryMiddleware(next http.Handler) http.Handler {
 		defer func() {
 			err := recover()
 			if err != nil {
-				log.Printf("recovered from panic: %v\n", err)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				log.ErrorContext(r.Context(), "recovered from panic", "panic", err, "stack", string(debug.Stack()))
+				json.ResponseWithProblem(w, r, apperror.Internal(fmt.Errorf("panic: %v", err)))
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// TracingMiddleware continues the trace of the W3C traceparent header, or starts
+// a new one, with a server span per request. The span is named after the method
+// until RegisterRoutes renames it to the matched route. It must run outside
+// LoggingMiddleware so the access log carries the trace.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		))
+		defer span.End()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status()))
+		if recorder.status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status()))
+		}
+	})
+}
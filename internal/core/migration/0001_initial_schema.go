@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// initialSchema creates the users collection and the indexes the server used
+// to create on every start. Down drops the indexes and keeps the documents.
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(ctx context.Context, schema Schema) error {
+		if err := schema.CreateCollection(ctx, "users"); err != nil {
+			return err
+		}
+
+		err := schema.CreateIndexes(ctx, "users", mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("unique_email_index"),
+		})
+		if mongo.IsDuplicateKeyError(err) {
+			log.WarnContext(ctx, "unique index on 'email' might already exist or data conflicts", "error", err)
+		} else if err != nil {
+			return err
+		}
+
+		// the webhooks by event type and the deliveries for the dispatcher, the
+		// unique event index stops an event from being delivered twice to the same webhook
+		err = schema.CreateIndexes(ctx, "webhooks", mongo.IndexModel{
+			Keys:    bson.D{{Key: "events", Value: 1}},
+			Options: options.Index().SetName("events_index"),
+		})
+		if err != nil {
+			return err
+		}
+		err = schema.CreateIndexes(ctx, "webhook_deliveries",
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+				Options: options.Index().SetName("due_deliveries_index"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "webhook_id", Value: 1}, {Key: "event_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("unique_webhook_event_index"),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return schema.CreateIndexes(ctx, "outbox", mongo.IndexModel{
+			Keys:    bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("pending_events_index"),
+		})
+	},
+	Down: func(ctx context.Context, schema Schema) error {
+		indexes := []struct{ collection, name string }{
+			{"outbox", "pending_events_index"},
+			{"webhook_deliveries", "unique_webhook_event_index"},
+			{"webhook_deliveries", "due_deliveries_index"},
+			{"webhooks", "events_index"},
+			{"users", "unique_email_index"},
+		}
+		for _, index := range indexes {
+			if err := schema.DropIndex(ctx, index.collection, index.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
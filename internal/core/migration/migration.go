@@ -0,0 +1,157 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Migration is one versioned change of the database. Up applies it and Down
+// reverts it, both go through Schema so a dry run can print them instead.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, schema Schema) error
+	Down    func(ctx context.Context, schema Schema) error
+}
+
+// Schema changes the database, or describes the changes in a dry run.
+type Schema interface {
+	// CreateCollection creates the collection name unless it exists.
+	CreateCollection(ctx context.Context, name string) error
+	// DropCollection drops the collection name and its documents.
+	DropCollection(ctx context.Context, name string) error
+	// CreateIndexes creates indexes on collection, an index that exists with
+	// the same keys and options is left as is.
+	CreateIndexes(ctx context.Context, collection string, indexes ...mongo.IndexModel) error
+	// DropIndex drops the index name of collection unless it is missing.
+	DropIndex(ctx context.Context, collection string, name string) error
+	// Run runs fn, for changes of the documents. A dry run prints description.
+	Run(ctx context.Context, description string, fn func(ctx context.Context, database *mongo.Database) error) error
+}
+
+// NewSchema returns the Schema that changes database.
+func NewSchema(database *mongo.Database) Schema {
+	return &mongoSchema{
+		database: database,
+	}
+}
+
+type mongoSchema struct {
+	database *mongo.Database
+}
+
+func (s *mongoSchema) CreateCollection(ctx context.Context, name string) error {
+	collections, err := s.database.ListCollectionNames(ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		return fmt.Errorf("failed to list collections: %w", err)
+	}
+	if slices.Contains(collections, name) {
+		log.DebugContext(ctx, "collection already exists", "collection", name)
+		return nil
+	}
+	if err := s.database.CreateCollection(ctx, name); err != nil {
+		return fmt.Errorf("failed to create '%s' collection: %w", name, err)
+	}
+	return nil
+}
+
+func (s *mongoSchema) DropCollection(ctx context.Context, name string) error {
+	if err := s.database.Collection(name).Drop(ctx); err != nil {
+		return fmt.Errorf("failed to drop '%s' collection: %w", name, err)
+	}
+	return nil
+}
+
+func (s *mongoSchema) CreateIndexes(ctx context.Context, collection string, indexes ...mongo.IndexModel) error {
+	if _, err := s.database.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("failed to create indexes for '%s' collection: %w", collection, err)
+	}
+	return nil
+}
+
+func (s *mongoSchema) DropIndex(ctx context.Context, collection string, name string) error {
+	err := s.database.Collection(collection).Indexes().DropOne(ctx, name)
+	var commandErr mongo.CommandError
+	// IndexNotFound and NamespaceNotFound
+	if errors.As(err, &commandErr) && (commandErr.HasErrorCode(27) || commandErr.HasErrorCode(26)) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to drop index %s of '%s' collection: %w", name, collection, err)
+	}
+	return nil
+}
+
+func (s *mongoSchema) Run(ctx context.Context, _ string, fn func(ctx context.Context, database *mongo.Database) error) error {
+	return fn(ctx, s.database)
+}
+
+// dryRunSchema writes the changes to out instead of making them.
+type dryRunSchema struct {
+	out io.Writer
+}
+
+func (s *dryRunSchema) CreateCollection(_ context.Context, name string) error {
+	_, err := fmt.Fprintf(s.out, "  create collection %s\n", name)
+	return err
+}
+
+func (s *dryRunSchema) DropCollection(_ context.Context, name string) error {
+	_, err := fmt.Fprintf(s.out, "  drop collection %s\n", name)
+	return err
+}
+
+func (s *dryRunSchema) CreateIndexes(_ context.Context, collection string, indexes ...mongo.IndexModel) error {
+	for _, index := range indexes {
+		if _, err := fmt.Fprintf(s.out, "  create index %s on %s\n", describeIndex(index), collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *dryRunSchema) DropIndex(_ context.Context, collection string, name string) error {
+	_, err := fmt.Fprintf(s.out, "  drop index %s on %s\n", name, collection)
+	return err
+}
+
+func (s *dryRunSchema) Run(_ context.Context, description string, _ func(ctx context.Context, database *mongo.Database) error) error {
+	_, err := fmt.Fprintf(s.out, "  run %s\n", description)
+	return err
+}
+
+// describeIndex formats index like unique_email_index {email: 1} unique.
+func describeIndex(index mongo.IndexModel) string {
+	var keys []string
+	if document, ok := index.Keys.(bson.D); ok {
+		for _, key := range document {
+			keys = append(keys, fmt.Sprintf("%s: %v", key.Key, key.Value))
+		}
+	}
+	description := "{" + strings.Join(keys, ", ") + "}"
+
+	if index.Options == nil {
+		return description
+	}
+	indexOptions := &options.IndexOptions{}
+	for _, set := range index.Options.List() {
+		_ = set(indexOptions)
+	}
+	if indexOptions.Name != nil {
+		description = *indexOptions.Name + " " + description
+	}
+	if indexOptions.Unique != nil && *indexOptions.Unique {
+		description += " unique"
+	}
+	if indexOptions.ExpireAfterSeconds != nil {
+		description += fmt.Sprintf(" expireAfterSeconds=%d", *indexOptions.ExpireAfterSeconds)
+	}
+	return description
+}
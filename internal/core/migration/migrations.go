@@ -0,0 +1,17 @@
+package migration
+
+import "go.mongodb.org/mongo-driver/v2/mongo"
+
+// Migrations returns the migrations of this build. A new migration gets the
+// next version and its own file, an applied migration is never changed.
+func Migrations() []Migration {
+	return []Migration{
+		initialSchema,
+	}
+}
+
+// New returns the Migrator of the migrations of this build on database,
+// recorded in its migrations collection.
+func New(database *mongo.Database, locker Locker, options Options) (*Migrator, error) {
+	return NewMigrator(Migrations(), NewSchema(database), NewMongoStore(database.Collection("migrations")), locker, options)
+}
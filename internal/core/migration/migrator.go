@@ -0,0 +1,329 @@
+package migration
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	lockName       = "migrations"
+	defaultLockTTL = time.Minute
+)
+
+// Locker hands out the lease that stops two replicas from migrating at once,
+// repository.LockRepository is one.
+type Locker interface {
+	AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name string, owner string) error
+}
+
+// Options configures a Migrator.
+type Options struct {
+	// LockTTL is how long the lock is kept without renewing it, another
+	// replica takes over after that.
+	LockTTL time.Duration
+	// DryRun receives what Up and Down would do, when it is set they do not
+	// change the database.
+	DryRun io.Writer
+}
+
+// Status is a migration and when it was applied, AppliedAt is zero while it
+// is pending.
+type Status struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+	// Unknown is set for an applied version that has no migration in this build.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Migrator applies and reverts migrations and records them in a Store.
+type Migrator struct {
+	migrations []Migration
+	schema     Schema
+	store      Store
+	locker     Locker
+	owner      string
+	options    Options
+}
+
+// NewMigrator returns a Migrator of migrations, they need distinct positive
+// versions and are applied in the order of their versions.
+func NewMigrator(migrations []Migration, schema Schema, store Store, locker Locker, options Options) (*Migrator, error) {
+	migrations = slices.Clone(migrations)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	for i, migration := range migrations {
+		switch {
+		case migration.Version <= 0:
+			return nil, fmt.Errorf("migration %q needs a positive version, got %d", migration.Name, migration.Version)
+		case i > 0 && migrations[i-1].Version == migration.Version:
+			return nil, fmt.Errorf("migrations %q and %q have the same version %d", migrations[i-1].Name, migration.Name, migration.Version)
+		case migration.Name == "":
+			return nil, fmt.Errorf("migration %d needs a name", migration.Version)
+		case migration.Up == nil:
+			return nil, fmt.Errorf("migration %d %s has no Up", migration.Version, migration.Name)
+		}
+	}
+	if options.LockTTL <= 0 {
+		options.LockTTL = defaultLockTTL
+	}
+	return &Migrator{
+		migrations: migrations,
+		schema:     schema,
+		store:      store,
+		locker:     locker,
+		owner:      lockOwner(),
+		options:    options,
+	}, nil
+}
+
+// Status returns every migration and the applied versions that have no
+// migration in this build, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	records, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := records[migration.Version]; ok {
+			status.AppliedAt = record.AppliedAt
+			delete(records, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, AppliedAt: record.AppliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
+
+// Pending returns the migrations that are not applied.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	return m.pending(ctx, 0)
+}
+
+// Up applies the pending migrations up to the version target, every pending
+// migration when target is 0, and returns them. A migration that was merged
+// below an applied version is still applied.
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	if target != 0 && !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == target }) {
+		return nil, fmt.Errorf("there is no migration %d", target)
+	}
+	if m.options.DryRun != nil {
+		pending, err := m.pending(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		return pending, m.dryRun(ctx, "apply", pending, func(migration Migration) func(context.Context, Schema) error {
+			return migration.Up
+		})
+	}
+
+	var applied []Migration
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		// another replica may have migrated while this one waited for the lock
+		pending, err := m.pending(ctx, target)
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			log.InfoContext(ctx, "applying migration", "version", migration.Version, "name", migration.Name)
+			if err := migration.Up(ctx, m.schema); err != nil {
+				return fmt.Errorf("failed to apply migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			if err := m.store.Add(ctx, Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}); err != nil {
+				return fmt.Errorf("failed to record migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them. Nothing is reverted when one of them cannot be.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if m.options.DryRun != nil {
+		reverted, err := m.reverted(ctx, steps)
+		if err != nil {
+			return nil, err
+		}
+		return reverted, m.dryRun(ctx, "revert", reverted, func(migration Migration) func(context.Context, Schema) error {
+			return migration.Down
+		})
+	}
+
+	var reverted []Migration
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		migrations, err := m.reverted(ctx, steps)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			log.InfoContext(ctx, "reverting migration", "version", migration.Version, "name", migration.Name)
+			if err := migration.Down(ctx, m.schema); err != nil {
+				return fmt.Errorf("failed to revert migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			if err := m.store.Remove(ctx, migration.Version); err != nil {
+				return fmt.Errorf("failed to remove the record of migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// applied returns the records of the store by version.
+func (m *Migrator) applied(ctx context.Context) (map[int]Record, error) {
+	records, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// pending returns the migrations up to target that are not applied.
+func (m *Migrator) pending(ctx context.Context, target int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if target != 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// reverted returns the last steps applied migrations, newest first.
+func (m *Migrator) reverted(ctx context.Context, steps int) ([]Migration, error) {
+	records, err := m.store.Applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	var reverted []Migration
+	for i := len(records) - 1; i >= 0 && len(reverted) < steps; i-- {
+		index := slices.IndexFunc(m.migrations, func(migration Migration) bool { return migration.Version == records[i].Version })
+		if index < 0 {
+			return nil, fmt.Errorf("migration %d %s is not in this build, it cannot be reverted", records[i].Version, records[i].Name)
+		}
+		if m.migrations[index].Down == nil {
+			return nil, fmt.Errorf("migration %d %s cannot be reverted", records[i].Version, records[i].Name)
+		}
+		reverted = append(reverted, m.migrations[index])
+	}
+	return reverted, nil
+}
+
+// dryRun writes action and each of migrations with the changes of its step.
+func (m *Migrator) dryRun(ctx context.Context, action string, migrations []Migration, step func(Migration) func(context.Context, Schema) error) error {
+	schema := &dryRunSchema{out: m.options.DryRun}
+	for _, migration := range migrations {
+		if _, err := fmt.Fprintf(m.options.DryRun, "%s %d %s\n", action, migration.Version, migration.Name); err != nil {
+			return err
+		}
+		if err := step(migration)(ctx, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withLock runs fn while this process holds the migration lock, it waits
+// while another replica holds it. The lock is renewed until fn returns, fn's
+// context is canceled when the lock is lost.
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	interval := m.options.LockTTL / 3
+	for {
+		acquired, err := m.locker.AcquireLease(ctx, lockName, m.owner, m.options.LockTTL)
+		if err != nil {
+			return fmt.Errorf("failed to acquire the migration lock: %w", err)
+		}
+		if acquired {
+			break
+		}
+		log.InfoContext(ctx, "waiting for another replica to finish migrating")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+	defer func() {
+		// released even when ctx is canceled, so the next replica does not wait for the lock to expire
+		if err := m.locker.ReleaseLease(context.WithoutCancel(ctx), lockName, m.owner); err != nil {
+			log.WarnContext(ctx, "failed to release the migration lock", "error", err)
+		}
+	}()
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-lockCtx.Done():
+				return
+			case <-ticker.C:
+				acquired, err := m.locker.AcquireLease(lockCtx, lockName, m.owner, m.options.LockTTL)
+				if err == nil && !acquired {
+					err = errors.New("another replica holds it")
+				}
+				if err != nil && lockCtx.Err() == nil {
+					cancel(fmt.Errorf("lost the migration lock: %w", err))
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn(lockCtx)
+	if cause := context.Cause(lockCtx); err != nil && cause != nil && ctx.Err() == nil {
+		err = cause
+	}
+	cancel(nil)
+	<-renewed
+	return err
+}
+
+// lockOwner identifies this process as the holder of the migration lock.
+func lockOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type mongoStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoStore returns a Store that keeps one document per applied migration
+// in collection, keyed by version.
+func NewMongoStore(collection *mongo.Collection) Store {
+	return &mongoStore{
+		collection: collection,
+	}
+}
+
+func (s *mongoStore) Applied(ctx context.Context) ([]Record, error) {
+	cursor, err := s.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (s *mongoStore) Add(ctx context.Context, record Record) error {
+	_, err := s.collection.InsertOne(ctx, record)
+	return err
+}
+
+func (s *mongoStore) Remove(ctx context.Context, version int) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": version})
+	return err
+}
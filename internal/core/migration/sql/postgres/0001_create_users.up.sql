@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id                  CHAR(24) PRIMARY KEY,
+    name                TEXT        NOT NULL,
+    email               TEXT        NOT NULL,
+    password            TEXT        NOT NULL,
+    locale              TEXT        NOT NULL DEFAULT '',
+    role                TEXT        NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL,
+    updated_at          TIMESTAMPTZ NOT NULL,
+    version             BIGINT      NOT NULL DEFAULT 1,
+    sessions_revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX unique_email_index ON users (email);
@@ -0,0 +1,15 @@
+CREATE TABLE outbox (
+    id              TEXT        PRIMARY KEY,
+    type            TEXT        NOT NULL,
+    occurred_at     TIMESTAMPTZ NOT NULL,
+    data            TEXT        NOT NULL,
+    aggregate_id    CHAR(24)    NOT NULL,
+    sequence        BIGINT      NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    status          TEXT        NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ
+);
+
+CREATE INDEX pending_events_index ON outbox (created_at, id);
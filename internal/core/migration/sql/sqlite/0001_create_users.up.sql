@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id                  TEXT     PRIMARY KEY,
+    name                TEXT     NOT NULL,
+    email               TEXT     NOT NULL,
+    password            TEXT     NOT NULL,
+    locale              TEXT     NOT NULL DEFAULT '',
+    role                TEXT     NOT NULL DEFAULT '',
+    created_at          DATETIME NOT NULL,
+    updated_at          DATETIME NOT NULL,
+    version             INTEGER  NOT NULL DEFAULT 1,
+    sessions_revoked_at DATETIME
+);
+
+CREATE UNIQUE INDEX unique_email_index ON users (email);
@@ -0,0 +1,15 @@
+CREATE TABLE outbox (
+    id              TEXT     PRIMARY KEY,
+    type            TEXT     NOT NULL,
+    occurred_at     DATETIME NOT NULL,
+    data            TEXT     NOT NULL,
+    aggregate_id    TEXT     NOT NULL,
+    sequence        INTEGER  NOT NULL,
+    created_at      DATETIME NOT NULL,
+    status          TEXT     NOT NULL,
+    attempts        INTEGER  NOT NULL DEFAULT 0,
+    last_error      TEXT     NOT NULL DEFAULT '',
+    next_attempt_at DATETIME
+);
+
+CREATE INDEX pending_events_index ON outbox (created_at, id);
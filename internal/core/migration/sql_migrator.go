@@ -0,0 +1,317 @@
+package migration
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql
+var sqlFiles embed.FS
+
+// sqlLockKey is the PostgreSQL advisory lock that stops two replicas from
+// migrating at once, SQLite transactions lock the whole database.
+const sqlLockKey = 4_827_519
+
+// createRecordsTable creates the table of the applied migrations, by backend.
+var createRecordsTable = map[string]string{
+	config.UserStorePostgres: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL
+)`,
+	config.UserStoreSQLite: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    name       TEXT     NOT NULL,
+    applied_at DATETIME NOT NULL
+)`,
+}
+
+// SQLMigration is one versioned change of a SQL user store, Up and Down are
+// its statements. Down is empty when it cannot be reverted.
+type SQLMigration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SQLMigrations returns the migrations of this build for backend, postgres or
+// sqlite. They are the files sql/<backend>/<version>_<name>.up.sql and
+// .down.sql, an applied migration is never changed.
+func SQLMigrations(backend string) ([]SQLMigration, error) {
+	dir := path.Join("sql", backend)
+	entries, err := fs.ReadDir(sqlFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("there are no SQL migrations for %q", backend)
+	}
+	var migrations []SQLMigration
+	for _, entry := range entries {
+		base, up := strings.CutSuffix(entry.Name(), ".up.sql")
+		if !up {
+			var down bool
+			if base, down = strings.CutSuffix(entry.Name(), ".down.sql"); !down {
+				return nil, fmt.Errorf("SQL migration %s must end with .up.sql or .down.sql", entry.Name())
+			}
+		}
+		versionText, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionText)
+		if err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("SQL migration %s must be named <version>_<name>", entry.Name())
+		}
+		statements, err := fs.ReadFile(sqlFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		index := slices.IndexFunc(migrations, func(m SQLMigration) bool { return m.Version == version })
+		if index < 0 {
+			migrations = append(migrations, SQLMigration{Version: version, Name: name})
+			index = len(migrations) - 1
+		} else if migrations[index].Name != name {
+			return nil, fmt.Errorf("SQL migrations %q and %q have the same version %d", migrations[index].Name, name, version)
+		}
+		if up {
+			migrations[index].Up = string(statements)
+		} else {
+			migrations[index].Down = string(statements)
+		}
+	}
+	for _, migration := range migrations {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("SQL migration %d %s has no .up.sql", migration.Version, migration.Name)
+		}
+	}
+	slices.SortFunc(migrations, func(a, b SQLMigration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return migrations, nil
+}
+
+// SQLMigrator applies and reverts the migrations of a SQL user store and
+// records them in its schema_migrations table. Each migration runs in a
+// transaction of its own.
+type SQLMigrator struct {
+	database   *sql.DB
+	backend    string
+	migrations []SQLMigration
+	dryRun     io.Writer
+}
+
+// NewSQLMigrator returns the SQLMigrator of the migrations of backend on
+// database. When dryRun is set Up and Down write what they would do to it and
+// do not change the database.
+func NewSQLMigrator(database *sql.DB, backend string, dryRun io.Writer) (*SQLMigrator, error) {
+	migrations, err := SQLMigrations(backend)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLMigrator{
+		database:   database,
+		backend:    backend,
+		migrations: migrations,
+		dryRun:     dryRun,
+	}, nil
+}
+
+// Status returns every migration and the applied versions that have no
+// migration in this build, ordered by version.
+func (m *SQLMigrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.transaction(ctx, false, func(tx *sql.Tx) error {
+		records, err := m.applied(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if record, ok := records[migration.Version]; ok {
+				status.AppliedAt = record.AppliedAt
+				delete(records, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, record := range records {
+			statuses = append(statuses, Status{Version: record.Version, Name: record.Name, AppliedAt: record.AppliedAt, Unknown: true})
+		}
+		return nil
+	})
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, err
+}
+
+// Pending returns the migrations that are not applied.
+func (m *SQLMigrator) Pending(ctx context.Context) ([]SQLMigration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []SQLMigration
+	for _, status := range statuses {
+		if status.AppliedAt.IsZero() {
+			pending = append(pending, m.migrations[slices.IndexFunc(m.migrations, func(migration SQLMigration) bool { return migration.Version == status.Version })])
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations up to the version target, every pending
+// migration when target is 0, and returns them.
+func (m *SQLMigrator) Up(ctx context.Context, target int) ([]SQLMigration, error) {
+	if target != 0 && !slices.ContainsFunc(m.migrations, func(migration SQLMigration) bool { return migration.Version == target }) {
+		return nil, fmt.Errorf("there is no migration %d", target)
+	}
+	var applied []SQLMigration
+	for _, migration := range m.migrations {
+		if target != 0 && migration.Version > target {
+			break
+		}
+		// another replica may have applied it while this one waited for the lock
+		err := m.transaction(ctx, m.dryRun == nil, func(tx *sql.Tx) error {
+			records, err := m.applied(ctx, tx)
+			if _, ok := records[migration.Version]; err != nil || ok {
+				return err
+			}
+			if m.dryRun != nil {
+				applied = append(applied, migration)
+				return m.describe("apply", migration, migration.Up)
+			}
+			log.InfoContext(ctx, "applying migration", "version", migration.Version, "name", migration.Name, "backend", m.backend)
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			insert := db.Rebind(m.backend, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)")
+			if _, err := tx.ExecContext(ctx, insert, migration.Version, migration.Name, time.Now().UTC()); err != nil {
+				return fmt.Errorf("failed to record migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+			return nil
+		})
+		if err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them. Nothing is reverted when one of them cannot be.
+func (m *SQLMigrator) Down(ctx context.Context, steps int) ([]SQLMigration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []SQLMigration
+	for i := len(statuses) - 1; i >= 0 && len(migrations) < steps; i-- {
+		status := statuses[i]
+		switch {
+		case status.AppliedAt.IsZero():
+			continue
+		case status.Unknown:
+			return nil, fmt.Errorf("migration %d %s is not in this build, it cannot be reverted", status.Version, status.Name)
+		}
+		migration := m.migrations[slices.IndexFunc(m.migrations, func(migration SQLMigration) bool { return migration.Version == status.Version })]
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %d %s cannot be reverted", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	var reverted []SQLMigration
+	for _, migration := range migrations {
+		err := m.transaction(ctx, m.dryRun == nil, func(tx *sql.Tx) error {
+			if m.dryRun != nil {
+				reverted = append(reverted, migration)
+				return m.describe("revert", migration, migration.Down)
+			}
+			log.InfoContext(ctx, "reverting migration", "version", migration.Version, "name", migration.Name, "backend", m.backend)
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return fmt.Errorf("failed to revert migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			remove := db.Rebind(m.backend, "DELETE FROM schema_migrations WHERE version = ?")
+			if _, err := tx.ExecContext(ctx, remove, migration.Version); err != nil {
+				return fmt.Errorf("failed to remove the record of migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+			return nil
+		})
+		if err != nil {
+			return reverted, err
+		}
+	}
+	return reverted, nil
+}
+
+// transaction runs fn in a transaction that holds the migration lock, after
+// creating the table of the records. The transaction is rolled back unless
+// commit is set, so a dry run leaves no table behind.
+func (m *SQLMigrator) transaction(ctx context.Context, commit bool, fn func(tx *sql.Tx) error) error {
+	tx, err := m.database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin the migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if m.backend == config.UserStorePostgres {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", sqlLockKey); err != nil {
+			return fmt.Errorf("failed to acquire the migration lock: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, createRecordsTable[m.backend]); err != nil {
+		return fmt.Errorf("failed to create the schema_migrations table: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !commit {
+		return nil
+	}
+	return tx.Commit()
+}
+
+// applied returns the records of the applied migrations by version.
+func (m *SQLMigrator) applied(ctx context.Context, tx *sql.Tx) (map[int]Record, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := map[int]Record{}
+	for rows.Next() {
+		var record Record
+		if err := rows.Scan(&record.Version, &record.Name, &record.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+		}
+		applied[record.Version] = record
+	}
+	return applied, rows.Err()
+}
+
+// describe writes action and migration with its statements to the dry run.
+func (m *SQLMigrator) describe(action string, migration SQLMigration, statements string) error {
+	if _, err := fmt.Fprintf(m.dryRun, "%s %d %s\n", action, migration.Version, migration.Name); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(statements), "\n") {
+		if _, err := fmt.Fprintf(m.dryRun, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package migration
+
+import (
+	"context"
+	"time"
+)
+
+// Record is an applied migration.
+type Record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Store keeps the records of the applied migrations.
+type Store interface {
+	// Applied returns the records ordered by version.
+	Applied(ctx context.Context) ([]Record, error)
+	// Add records that a migration was applied.
+	Add(ctx context.Context, record Record) error
+	// Remove drops the record of version after the migration was reverted.
+	Remove(ctx context.Context, version int) error
+}
@@ -0,0 +1,381 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	Version = "3.1.0"
+
+	ContentTypeJSON = "application/json"
+
+	securitySchemeName = "bearerAuth"
+)
+
+// Route describes one route registered on the mux. Pattern uses the
+// http.ServeMux syntax, e.g. "POST /api/v1/users/register".
+type Route struct {
+	Pattern    string
+	Summary    string
+	Tags       []string
+	Auth       bool
+	Parameters []Parameter
+	// Request maps a request content type to a value of the body type.
+	Request map[string]interface{}
+	// Response is a value of the data type wrapped in the {"data": ...} envelope.
+	// When ResponseContentType is set the body is returned as is instead.
+	Response            interface{}
+	ResponseContentType string
+	ResponseHeaders     map[string]Header
+	// Errors lists the problem+json statuses besides 500, 406 for enveloped
+	// responses and, for Auth, 401.
+	Errors []int
+	// EmptyResponses lists statuses returned without a body, e.g. 304.
+	EmptyResponses []int
+	// ResponseStatuses lists other statuses returned with the success body,
+	// e.g. 503 for a failing health report.
+	ResponseStatuses []int
+}
+
+// Generator builds a Document from routes and the Go types of their bodies.
+type Generator struct {
+	document *Document
+	// tagEnums maps custom validate tags to the values they accept.
+	tagEnums map[string][]string
+	problem  interface{}
+	envelope string
+	// mediaTypes are the content types of enveloped success responses.
+	mediaTypes []string
+}
+
+// NewGenerator returns a Generator. problem is the error body type, envelope
+// the name of the success envelope property and mediaTypes the content types
+// the envelope is served in.
+func NewGenerator(info Info, problem interface{}, envelope string, mediaTypes []string, tagEnums map[string][]string) *Generator {
+	return &Generator{
+		document: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{},
+				SecuritySchemes: map[string]SecurityScheme{
+					securitySchemeName: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		tagEnums:   tagEnums,
+		problem:    problem,
+		envelope:   envelope,
+		mediaTypes: mediaTypes,
+	}
+}
+
+// Add documents route. It returns an error for a pattern without a method or a
+// route that is already documented.
+func (g *Generator) Add(route Route) error {
+	method, path, ok := strings.Cut(route.Pattern, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("openapi: route %q must have a method and a path", route.Pattern)
+	}
+	method = strings.ToLower(method)
+
+	item, ok := g.document.Paths[path]
+	if !ok {
+		item = PathItem{}
+		g.document.Paths[path] = item
+	}
+	if _, exists := item[method]; exists {
+		return fmt.Errorf("openapi: route %q is documented twice", route.Pattern)
+	}
+
+	operation := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     route.Summary,
+		Tags:        route.Tags,
+		Parameters:  route.Parameters,
+		Responses:   map[string]Response{},
+	}
+	if route.Auth {
+		operation.Security = []map[string][]string{{securitySchemeName: {}}}
+	}
+
+	if len(route.Request) > 0 {
+		operation.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{}}
+		for contentType, body := range route.Request {
+			operation.RequestBody.Content[contentType] = MediaType{Schema: g.schema(reflect.TypeOf(body), true)}
+		}
+	}
+
+	operation.Responses[strconv.Itoa(http.StatusOK)] = g.successResponse(route)
+	for _, status := range route.ResponseStatuses {
+		response := g.successResponse(route)
+		response.Description = http.StatusText(status)
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+	for _, status := range route.EmptyResponses {
+		operation.Responses[strconv.Itoa(status)] = Response{Description: http.StatusText(status)}
+	}
+	errors := append([]int{}, route.Errors...)
+	if route.Auth {
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	if route.ResponseContentType == "" {
+		// the envelope is negotiated from the Accept header
+		errors = append(errors, http.StatusNotAcceptable)
+	}
+	errors = append(errors, http.StatusInternalServerError)
+	for _, status := range errors {
+		operation.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{
+				"application/problem+json": {Schema: g.schema(reflect.TypeOf(g.problem), false)},
+			},
+		}
+	}
+
+	item[method] = operation
+	return nil
+}
+
+// Document returns the generated document.
+func (g *Generator) Document() *Document {
+	return g.document
+}
+
+func (g *Generator) successResponse(route Route) Response {
+	response := Response{Description: http.StatusText(http.StatusOK), Headers: route.ResponseHeaders}
+	if route.ResponseContentType != "" {
+		schema := &Schema{Type: "string"}
+		if route.Response != nil {
+			schema = g.schema(reflect.TypeOf(route.Response), false)
+		}
+		response.Content = map[string]MediaType{route.ResponseContentType: {Schema: schema}}
+		return response
+	}
+
+	data := &Schema{}
+	if route.Response != nil {
+		data = g.schema(reflect.TypeOf(route.Response), false)
+	}
+	envelope := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{g.envelope: data},
+		Required:   []string{g.envelope},
+	}
+	response.Content = map[string]MediaType{}
+	for _, mediaType := range g.mediaTypes {
+		response.Content[mediaType] = MediaType{Schema: envelope}
+	}
+	return response
+}
+
+// schema returns the schema of t. Named structs are added to the components and
+// referenced. In a request, validate:"required" makes a field required, in a
+// response every field without omitempty is.
+func (g *Generator) schema(t reflect.Type, request bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), request)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, request)
+		}
+		name := componentName(t)
+		if _, ok := g.document.Components.Schemas[name]; !ok {
+			// reserve the name first so recursive types terminate
+			g.document.Components.Schemas[name] = &Schema{}
+			*g.document.Components.Schemas[name] = *g.structSchema(t, request)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *Generator) structSchema(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			embedded := g.structSchema(field.Type, request)
+			for key, value := range embedded.Properties {
+				schema.Properties[key] = value
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		property := g.schema(field.Type, request)
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		if property.Ref == "" {
+			g.applyRules(property, rules)
+			applyLengthTags(property, field.Tag)
+		}
+		schema.Properties[name] = property
+
+		if request && containsRule(rules, "required") || !request && !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (g *Generator) applyRules(schema *Schema, rules []string) {
+	for i, rule := range rules {
+		tag, param, _ := strings.Cut(rule, "=")
+		switch tag {
+		case "dive":
+			// the rules after dive apply to the items
+			if schema.Items != nil && schema.Items.Ref == "" {
+				g.applyRules(schema.Items, rules[i+1:])
+			}
+			return
+		case "email":
+			schema.Format = "email"
+		case "url", "http_url":
+			schema.Format = "uri"
+		case "uuid":
+			schema.Format = "uuid"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "min", "max", "len":
+			applyBound(schema, tag, param)
+		default:
+			for _, value := range g.tagEnums[tag] {
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+	}
+}
+
+func applyBound(schema *Schema, tag string, param string) {
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		if tag != "max" {
+			schema.MinLength = &value
+		}
+		if tag != "min" {
+			schema.MaxLength = &value
+		}
+	case "array":
+		if tag != "max" {
+			schema.MinItems = &value
+		}
+		if tag != "min" {
+			schema.MaxItems = &value
+		}
+	case "integer", "number":
+		bound := float64(value)
+		if tag != "max" {
+			schema.Minimum = &bound
+		}
+		if tag != "min" {
+			schema.Maximum = &bound
+		}
+	}
+}
+
+// applyLengthTags reads the minlength and maxlength tags the dto structs use to
+// document string lengths.
+func applyLengthTags(schema *Schema, tag reflect.StructTag) {
+	if schema.Type != "string" {
+		return
+	}
+	if value, err := strconv.Atoi(tag.Get("minlength")); err == nil {
+		schema.MinLength = &value
+	}
+	if value, err := strconv.Atoi(tag.Get("maxlength")); err == nil {
+		schema.MaxLength = &value
+	}
+}
+
+func jsonName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func componentName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+// operationID turns "post /api/v1/users/register" into "postApiV1UsersRegister".
+func operationID(method string, path string) string {
+	var builder strings.Builder
+	builder.WriteString(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return builder.String()
+}
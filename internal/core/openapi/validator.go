@@ -0,0 +1,400 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"mime"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const contentTypeMergePatch = "application/merge-patch+json"
+
+var (
+	errUnsupportedMediaType = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeUnsupportedMediaType, "Unsupported content type")
+)
+
+// Validator checks requests and responses against a Document.
+type Validator struct {
+	document *Document
+	routes   []route
+}
+
+type route struct {
+	method    string
+	segments  []string
+	params    int
+	operation *Operation
+}
+
+// NewValidator returns a Validator for document.
+func NewValidator(document *Document) *Validator {
+	validator := &Validator{document: document}
+	for path, item := range document.Paths {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		params := 0
+		for _, segment := range segments {
+			if isPathParam(segment) {
+				params++
+			}
+		}
+		for method, operation := range item {
+			validator.routes = append(validator.routes, route{
+				method:    strings.ToUpper(method),
+				segments:  segments,
+				params:    params,
+				operation: operation,
+			})
+		}
+	}
+	// literal segments are more specific than parameters
+	sort.Slice(validator.routes, func(i, j int) bool {
+		return validator.routes[i].params < validator.routes[j].params
+	})
+	return validator
+}
+
+// Operation returns the documented operation for r and its path parameters.
+func (v *Validator) Operation(r *http.Request) (*Operation, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, route := range v.routes {
+		if route.method != r.Method || len(route.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, segment := range route.segments {
+			if isPathParam(segment) {
+				params[strings.Trim(segment, "{}")] = segments[i]
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return route.operation, params, true
+		}
+	}
+	return nil, nil, false
+}
+
+// ValidateRequest checks the parameters, content type and body of r against
+// operation. body is the already read request body. The returned error is an
+// *apperror.Error with one entry per invalid field.
+func (v *Validator) ValidateRequest(r *http.Request, operation *Operation, pathParams map[string]string, body []byte) error {
+	var fields []apperror.FieldError
+	for _, parameter := range operation.Parameters {
+		var value string
+		var present bool
+		switch parameter.In {
+		case "path":
+			value, present = pathParams[parameter.Name]
+		case "query":
+			present = r.URL.Query().Has(parameter.Name)
+			value = r.URL.Query().Get(parameter.Name)
+		case "header":
+			value = r.Header.Get(parameter.Name)
+			present = value != ""
+		}
+		if !present {
+			if parameter.Required || parameter.In == "path" {
+				fields = append(fields, requiredField(parameter.Name))
+			}
+			continue
+		}
+		fields = append(fields, v.validateParameter(parameter.Name, value, parameter.Schema)...)
+	}
+
+	if operation.RequestBody != nil {
+		bodyFields, err := v.validateBody(r, operation.RequestBody, body)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, bodyFields...)
+	}
+
+	if len(fields) > 0 {
+		return apperror.Validation(apperror.CodeValidationFailed, "validation failed", fields...)
+	}
+	return nil
+}
+
+func (v *Validator) validateBody(r *http.Request, requestBody *RequestBody, body []byte) ([]apperror.FieldError, error) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Header.Get("Content-Type") == "" {
+		// clients that omit the header have always been treated as sending JSON
+		contentType, err = ContentTypeJSON, nil
+	}
+	if err != nil {
+		return nil, errUnsupportedMediaType
+	}
+	// aliases like application/x-msgpack are documented under the codec's name
+	if c, ok := codec.Default.ForContentType(contentType); ok {
+		contentType = c.ContentType()
+	}
+	mediaType, ok := requestBody.Content[contentType]
+	if !ok {
+		return nil, errUnsupportedMediaType
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if requestBody.Required {
+			return nil, codec.ErrInvalidRequestBody
+		}
+		return nil, nil
+	}
+	body, err = toJSON(contentType, body)
+	if err != nil {
+		return nil, codec.ErrInvalidRequestBody
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, codec.ErrInvalidRequestBody
+	}
+	// a null member of a merge patch removes it, so null is valid for any property
+	allowNull := contentType == contentTypeMergePatch
+	return v.validateValue("", value, mediaType.Schema, allowNull), nil
+}
+
+// ValidateResponse checks that status, the content type and body of a response
+// are documented for operation.
+func (v *Validator) ValidateResponse(operation *Operation, status int, header http.Header, body []byte) error {
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+	if len(response.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("status %d is documented without a body", status)
+		}
+		return nil
+	}
+
+	contentType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("invalid content type %q", header.Get("Content-Type"))
+	}
+	mediaType, ok := response.Content[contentType]
+	if !ok {
+		return fmt.Errorf("content type %s is not documented for status %d", contentType, status)
+	}
+	if _, ok := codec.Default.ForContentType(contentType); !ok && !strings.HasSuffix(contentType, "+json") {
+		return nil
+	}
+	body, err = toJSON(contentType, body)
+	if err != nil {
+		return fmt.Errorf("invalid %s body: %w", contentType, err)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	if fields := v.validateValue("", value, mediaType.Schema, false); len(fields) > 0 {
+		messages := make([]string, len(fields))
+		for i, field := range fields {
+			messages[i] = field.Message
+		}
+		return fmt.Errorf("body does not match the schema: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// toJSON re-encodes a body of a binary codec as JSON, so every format is
+// validated against the same schema.
+func toJSON(contentType string, body []byte) ([]byte, error) {
+	c, ok := codec.Default.ForContentType(contentType)
+	if !ok || c == codec.JSON {
+		return body, nil
+	}
+	var value interface{}
+	if err := c.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+func (v *Validator) validateParameter(name string, value string, schema *Schema) []apperror.FieldError {
+	schema = v.resolve(schema)
+	var parsed interface{} = value
+	switch schema.Type {
+	case "integer", "number":
+		parsed = json.Number(value)
+		if _, err := json.Number(value).Float64(); err != nil {
+			return []apperror.FieldError{typeField(name, schema.Type)}
+		}
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return []apperror.FieldError{typeField(name, schema.Type)}
+		}
+		parsed = b
+	}
+	return v.validateValue(name, parsed, schema, false)
+}
+
+func (v *Validator) validateValue(path string, value interface{}, schema *Schema, allowNull bool) []apperror.FieldError {
+	schema = v.resolve(schema)
+	if schema == nil || schema.Type == "" && schema.Properties == nil {
+		return nil
+	}
+	if value == nil {
+		if allowNull {
+			return nil
+		}
+		return []apperror.FieldError{typeField(path, schema.Type)}
+	}
+
+	var fields []apperror.FieldError
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				fields = append(fields, requiredField(joinPath(path, name)))
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property != nil {
+				fields = append(fields, v.validateValue(joinPath(path, name), object[name], property, allowNull)...)
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooSmall, "must have at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooLarge, "must have at most %d items", *schema.MaxItems))
+		}
+		for i, item := range array {
+			fields = append(fields, v.validateValue(fmt.Sprintf("%s[%d]", path, i), item, schema.Items, false)...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+		length := utf8.RuneCountInString(s)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooShort, "must be at least %d characters", *schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooLong, "must be at most %d characters", *schema.MaxLength))
+		}
+		if schema.Format == "email" {
+			if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+				fields = append(fields, apperror.FieldError{Field: path, Code: apperror.CodeFieldInvalidEmail, Message: fmt.Sprintf("%s must be a valid email address", path)})
+			}
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+		f, err := number.Float64()
+		if err != nil || schema.Type == "integer" && strings.ContainsAny(number.String(), ".eE") {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooSmall, "must be at least %v", *schema.Minimum))
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			fields = append(fields, boundField(path, apperror.CodeFieldTooLarge, "must be at most %v", *schema.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []apperror.FieldError{typeField(path, schema.Type)}
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		allowed := make([]string, len(schema.Enum))
+		for i, item := range schema.Enum {
+			allowed[i] = fmt.Sprint(item)
+		}
+		param := strings.Join(allowed, ", ")
+		fields = append(fields, apperror.FieldError{
+			Field:   path,
+			Code:    apperror.CodeFieldNotAllowed,
+			Message: fmt.Sprintf("%s must be one of: %s", path, param),
+			Params:  map[string]string{"param": param},
+		})
+	}
+	return fields
+}
+
+func (v *Validator) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = v.document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, item := range enum {
+		if fmt.Sprint(item) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func requiredField(path string) apperror.FieldError {
+	return apperror.FieldError{Field: path, Code: apperror.CodeFieldRequired, Message: fmt.Sprintf("%s is required", path)}
+}
+
+func typeField(path string, schemaType string) apperror.FieldError {
+	return apperror.FieldError{
+		Field:   path,
+		Code:    apperror.CodeFieldInvalidType,
+		Message: fmt.Sprintf("%s must be of type %s", path, schemaType),
+		Params:  map[string]string{"param": schemaType},
+	}
+}
+
+func boundField(path string, code string, format string, bound interface{}) apperror.FieldError {
+	return apperror.FieldError{
+		Field:   path,
+		Code:    code,
+		Message: path + " " + fmt.Sprintf(format, bound),
+		Params:  map[string]string{"param": fmt.Sprint(bound)},
+	}
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
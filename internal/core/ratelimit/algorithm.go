@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// state is the per key counter shared by both algorithms, so a store only has
+// to load and save it.
+type state struct {
+	Tokens      float64   `bson:"tokens"`
+	Last        time.Time `bson:"last"`
+	WindowStart time.Time `bson:"window_start"`
+	Current     int       `bson:"current"`
+	Previous    int       `bson:"previous"`
+}
+
+func take(s state, policy Policy, now time.Time) (state, Result) {
+	if policy.Algorithm == AlgorithmTokenBucket {
+		return takeTokenBucket(s, policy, now)
+	}
+	return takeSlidingWindow(s, policy, now)
+}
+
+func takeTokenBucket(s state, policy Policy, now time.Time) (state, Result) {
+	capacity := float64(policy.capacity())
+	perSecond := float64(policy.Limit) / policy.Window.Seconds()
+
+	if s.Last.IsZero() {
+		s.Tokens = capacity
+	} else if elapsed := now.Sub(s.Last).Seconds(); elapsed > 0 {
+		s.Tokens = min(capacity, s.Tokens+elapsed*perSecond)
+	}
+	s.Last = now
+
+	result := Result{Limit: policy.capacity()}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - s.Tokens) / perSecond)
+	}
+	result.Remaining = int(math.Floor(s.Tokens))
+	result.Reset = seconds((capacity - s.Tokens) / perSecond)
+	return s, result
+}
+
+func takeSlidingWindow(s state, policy Policy, now time.Time) (state, Result) {
+	start := now.Truncate(policy.Window)
+	if !s.WindowStart.Equal(start) {
+		if s.WindowStart.Equal(start.Add(-policy.Window)) {
+			s.Previous = s.Current
+		} else {
+			s.Previous = 0
+		}
+		s.Current = 0
+		s.WindowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	count := float64(s.Previous)*(1-float64(elapsed)/float64(policy.Window)) + float64(s.Current)
+
+	result := Result{Limit: policy.Limit, Reset: start.Add(policy.Window).Sub(now)}
+	if count+1 <= float64(policy.Limit) {
+		s.Current++
+		count++
+		result.Allowed = true
+	} else if s.Current+1 > policy.Limit {
+		// the current window becomes the previous one and has to slide out far enough
+		overlap := 1 - float64(policy.Limit-1)/float64(s.Current)
+		result.RetryAfter = result.Reset + time.Duration(overlap*float64(policy.Window))
+	} else {
+		overlap := 1 - float64(policy.Limit-s.Current-1)/float64(s.Previous)
+		result.RetryAfter = time.Duration(overlap*float64(policy.Window)) - elapsed
+	}
+	result.Remaining = max(0, policy.Limit-int(math.Ceil(count)))
+	return s, result
+}
+
+// expiresAt is when an untouched counter no longer affects any decision.
+func expiresAt(policy Policy, now time.Time) time.Time {
+	return now.Add(2 * policy.Window)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
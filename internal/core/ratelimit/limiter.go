@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter matches requests to policies and takes them from the store.
+type Limiter struct {
+	store Store
+	index atomic.Pointer[policyIndex]
+}
+
+// policyIndex maps route patterns to their policy.
+type policyIndex struct {
+	routes   *http.ServeMux
+	policies map[string]Policy
+}
+
+// NewLimiter validates policies and indexes them by route pattern. A pattern may
+// only belong to one policy, the most specific pattern wins like in http.ServeMux.
+func NewLimiter(store Store, policies []Policy) (*Limiter, error) {
+	limiter := &Limiter{
+		store: store,
+	}
+	if err := limiter.SetPolicies(policies); err != nil {
+		return nil, err
+	}
+	return limiter, nil
+}
+
+// SetPolicies replaces the policies, the current ones stay when policies are
+// invalid. Budgets already taken are kept for the policies that keep their name.
+func (l *Limiter) SetPolicies(policies []Policy) error {
+	index := &policyIndex{
+		routes:   http.NewServeMux(),
+		policies: make(map[string]Policy),
+	}
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		for _, route := range policy.Routes {
+			if err := index.addRoute(route, policy); err != nil {
+				return err
+			}
+		}
+	}
+	l.index.Store(index)
+	return nil
+}
+
+func (i *policyIndex) addRoute(route string, policy Policy) (err error) {
+	// ServeMux panics on invalid or conflicting patterns
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("rate limit policy %s: %v", policy.Name, recovered)
+		}
+	}()
+	i.routes.Handle(route, http.NotFoundHandler())
+	i.policies[route] = policy
+	return nil
+}
+
+// Match returns the policy that applies to r.
+func (l *Limiter) Match(r *http.Request) (Policy, bool) {
+	index := l.index.Load()
+	_, pattern := index.routes.Handler(r)
+	policy, ok := index.policies[pattern]
+	return policy, ok
+}
+
+// MatchMethod returns the policy that applies to the gRPC call of fullMethod,
+// e.g. "/user.v1.UserService/LoginUser". gRPC calls are POST requests to that
+// path, so a policy lists it among its routes like an HTTP route.
+func (l *Limiter) MatchMethod(fullMethod string) (Policy, bool) {
+	return l.Match(&http.Request{Method: http.MethodPost, URL: &url.URL{Path: fullMethod}})
+}
+
+// Take takes one request for key from the policy's budget. A counter the store
+// could not update for contention rejects the request, to retry after a second.
+func (l *Limiter) Take(ctx context.Context, policy Policy, key string) (Result, error) {
+	result, err := l.store.Take(ctx, policy.Name+":"+string(policy.KeyBy)+":"+key, policy)
+	if errors.Is(err, ErrContended) {
+		log.WarnContext(ctx, "rate limit counter is too contended, rejecting the request", "policy", policy.Name)
+		return Result{Limit: policy.capacity(), Reset: time.Second, RetryAfter: time.Second}, nil
+	}
+	return result, err
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+const maxMongoAttempts = 5
+
+type mongoCounter struct {
+	Key       string    `bson:"_id"`
+	State     state     `bson:"state"`
+	Version   int64     `bson:"version"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type mongoStore struct {
+	mongoCollection *mongo.Collection
+}
+
+// NewMongoStore returns a Store shared by every replica using the same collection.
+// Counters are updated with optimistic concurrency and removed by a TTL index.
+func NewMongoStore(ctx context.Context, mongoCollection *mongo.Collection) (Store, error) {
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_index"),
+	}
+	if _, err := mongoCollection.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return nil, fmt.Errorf("failed to create rate limit TTL index: %w", err)
+	}
+	return &mongoStore{
+		mongoCollection: mongoCollection,
+	}, nil
+}
+
+func (s *mongoStore) Take(ctx context.Context, key string, policy Policy) (Result, error) {
+	for attempt := 0; attempt < maxMongoAttempts; attempt++ {
+		var counter mongoCounter
+		err := s.mongoCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&counter)
+		found := err == nil
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return Result{}, err
+		}
+
+		now := time.Now()
+		next, result := take(counter.State, policy, now)
+
+		if !found {
+			_, err = s.mongoCollection.InsertOne(ctx, mongoCounter{
+				Key:       key,
+				State:     next,
+				Version:   1,
+				ExpiresAt: expiresAt(policy, now),
+			})
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			if err != nil {
+				return Result{}, err
+			}
+			return result, nil
+		}
+
+		updateResult, err := s.mongoCollection.UpdateOne(ctx,
+			bson.M{"_id": key, "version": counter.Version},
+			bson.M{"$set": bson.M{
+				"state":      next,
+				"version":    counter.Version + 1,
+				"expires_at": expiresAt(policy, now),
+			}},
+		)
+		if err != nil {
+			return Result{}, err
+		}
+		if updateResult.MatchedCount == 1 {
+			return result, nil
+		}
+	}
+	return Result{}, ErrContended
+}
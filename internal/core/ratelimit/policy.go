@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	// AlgorithmTokenBucket refills Limit tokens per Window and allows bursts up to Burst.
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+	// AlgorithmSlidingWindow allows Limit requests in any Window, weighting the
+	// previous window by how much of it still overlaps.
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+)
+
+type KeyBy string
+
+const (
+	KeyByIP     KeyBy = "ip"
+	KeyByUser   KeyBy = "user"
+	KeyByAPIKey KeyBy = "api_key"
+)
+
+// Policy limits the requests matching Routes. Routes use the same patterns as
+// http.ServeMux, e.g. "POST /api/v1/users/login" or "/" for every route.
+type Policy struct {
+	Name      string
+	Routes    []string
+	Algorithm Algorithm
+	Limit     int
+	Burst     int
+	Window    time.Duration
+	KeyBy     KeyBy
+}
+
+// Result is the outcome of taking one request from a policy's budget.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+func (p Policy) capacity() int {
+	if p.Algorithm == AlgorithmTokenBucket && p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+func (p Policy) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("rate limit policy name is required")
+	}
+	if len(p.Routes) == 0 {
+		return fmt.Errorf("rate limit policy %s has no routes", p.Name)
+	}
+	if p.Algorithm != AlgorithmTokenBucket && p.Algorithm != AlgorithmSlidingWindow {
+		return fmt.Errorf("rate limit policy %s has unknown algorithm %q", p.Name, p.Algorithm)
+	}
+	if p.Limit < 1 || p.Window <= 0 || p.Burst < 0 {
+		return fmt.Errorf("rate limit policy %s must have a positive limit and window", p.Name)
+	}
+	if p.KeyBy != KeyByIP && p.KeyBy != KeyByUser && p.KeyBy != KeyByAPIKey {
+		return fmt.Errorf("rate limit policy %s has unknown key %q", p.Name, p.KeyBy)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrContended is returned by a Store that gave up updating a counter because
+// other requests kept changing it. Only a flood of requests on the key does
+// that, so the Limiter rejects the request.
+var ErrContended = errors.New("rate limit counter is too contended")
+
+// Store keeps the counters of every key. A shared Store lets several replicas
+// enforce one limit; Take must be atomic per key.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"os"
+)
+
+// instrumentation is the name of the tracer every span of the service is started with.
+const instrumentation = "github.com/taninchot-work/backend-challenge"
+
+// Options configures the exported traces.
+type Options struct {
+	// Exporter is none, stdout, file or otlp.
+	Exporter string
+	// Endpoint is the OTLP/HTTP endpoint, like http://localhost:4318, the
+	// OTEL_EXPORTER_OTLP_* variables apply when it is empty.
+	Endpoint string
+	// File is the file the spans are appended to with the file exporter.
+	File string
+	// ServiceName is the service.name of the spans.
+	ServiceName string
+	// SampleRatio is the share of new traces that are recorded, 0 records all.
+	// Requests that carry a sampled traceparent are always recorded.
+	SampleRatio float64
+}
+
+// Setup installs the tracer provider and the W3C trace context propagator. The
+// returned function flushes the spans that are not exported yet.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	var err error
+	switch options.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		var file *os.File
+		file, err = os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		closer = file
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	case "otlp":
+		var exporterOptions []otlptracehttp.Option
+		if options.Endpoint != "" {
+			exporterOptions = append(exporterOptions, otlptracehttp.WithEndpointURL(options.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, exporterOptions...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", options.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := options.ServiceName
+	if serviceName == "" {
+		serviceName = "backend-challenge"
+	}
+	sampleRatio := options.SampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span named name as a child of the span in ctx. While tracing
+// is off ctx is returned as is.
+func Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(instrumentation).Start(ctx, name, options...)
+	if !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
+
+// StartChild starts a span like Start only when ctx already has one, so polling
+// background jobs do not start a trace for every query.
+func StartChild(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Start(ctx, name, options...)
+}
+
+// End ends span, marking it failed when err is not nil. Email addresses in the
+// error are redacted like in the logs.
+func End(span trace.Span, err error) {
+	if err != nil {
+		message := logger.RedactEmails(err.Error())
+		span.RecordError(errors.New(message))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+// TraceID returns the id of the trace in ctx, empty when there is none.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
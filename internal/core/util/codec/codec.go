@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"reflect"
+)
+
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeMessagePack = "application/msgpack"
+	ContentTypeCBOR        = "application/cbor"
+)
+
+// Codec encodes and decodes bodies of one media type. Every codec uses the json
+// struct tags so the field names are the same in every format.
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type messagePackCodec struct{}
+
+func (messagePackCodec) ContentType() string {
+	return ContentTypeMessagePack
+}
+
+func (messagePackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (messagePackCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(v)
+}
+
+type cborCodec struct {
+	encMode cbor.EncMode
+	decMode cbor.DecMode
+}
+
+func newCBORCodec() Codec {
+	encMode, err := cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	decMode, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}{})}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return &cborCodec{encMode: encMode, decMode: decMode}
+}
+
+func (c *cborCodec) ContentType() string {
+	return ContentTypeCBOR
+}
+
+func (c *cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return c.encMode.Marshal(v)
+}
+
+func (c *cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.decMode.Unmarshal(data, v)
+}
+
+var (
+	JSON        Codec = jsonCodec{}
+	MessagePack Codec = messagePackCodec{}
+	CBOR              = newCBORCodec()
+)
@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"errors"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ErrInvalidRequestBody is returned for a body that cannot be read or decoded.
+var ErrInvalidRequestBody = apperror.Validation(apperror.CodeInvalidRequestBody, "Invalid request body")
+
+var (
+	errRequestBodyTooLarge  = apperror.New(apperror.KindPayloadTooLarge, apperror.CodeRequestBodyTooLarge, "Request body is too large")
+	errUnsupportedMediaType = apperror.New(apperror.KindUnsupportedMediaType, apperror.CodeUnsupportedMediaType, "Unsupported content type")
+	errNotAcceptable        = apperror.New(apperror.KindNotAcceptable, apperror.CodeNotAcceptable, "None of the accepted content types can be produced")
+)
+
+// ReadBody reads the body of r, at most restServer.maxBodySize bytes of it. A
+// larger body is a 413 error and the connection is closed after the response.
+func ReadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	limit := config.GetConfig().RestServer.MaxBodySize
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(limit)))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return nil, errRequestBodyTooLarge.WithParam("limit", strconv.Itoa(limit))
+	case err != nil:
+		return nil, ErrInvalidRequestBody
+	}
+	return body, nil
+}
+
+// DecodeRequest decodes the body of r into v with the codec of its Content-Type,
+// the body is limited like in ReadBody. It also rejects requests whose response
+// could not be encoded, so nothing is changed for a client that cannot read the result.
+func DecodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	if err := Acceptable(r); err != nil {
+		return err
+	}
+	codec, ok := Default.ForContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		return errUnsupportedMediaType
+	}
+	body, err := ReadBody(w, r)
+	if err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(body, v); err != nil {
+		return ErrInvalidRequestBody
+	}
+	return nil
+}
+
+// Acceptable returns a 406 error when no codec matches the Accept header of r.
+func Acceptable(r *http.Request) error {
+	if _, ok := Default.Negotiate(r.Header.Get("Accept")); !ok {
+		return errNotAcceptable
+	}
+	return nil
+}
+
+// ResponseWithSuccess writes data in the {"data": ...} envelope with the codec
+// negotiated from the Accept header of r.
+func ResponseWithSuccess(w http.ResponseWriter, r *http.Request, data interface{}) {
+	Response(w, r, http.StatusOK, json.Response{Data: data})
+}
+
+// Response writes body as is with the codec negotiated from the Accept header
+// of r, or a 406 problem when none of the accepted types is supported.
+func Response(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	w.Header().Add("Vary", "Accept")
+	codec, ok := Default.Negotiate(r.Header.Get("Accept"))
+	if !ok {
+		json.ResponseWithProblem(w, r, errNotAcceptable)
+		return
+	}
+	encoded, err := codec.Marshal(body)
+	if err != nil {
+		log.ErrorContext(r.Context(), "error encoding response", "error", err)
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", codec.ContentType())
+	w.WriteHeader(status)
+	w.Write(encoded)
+}
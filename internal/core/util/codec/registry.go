@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Registry selects a codec for the Content-Type of a request and the Accept
+// header of a response. The first registered codec is the default.
+type Registry struct {
+	codecs       []Codec
+	contentTypes map[string]Codec
+}
+
+// Default serves JSON, MessagePack and CBOR, JSON being the default.
+var Default = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry(JSON)
+	registry.Register(MessagePack, "application/x-msgpack", "application/vnd.msgpack")
+	registry.Register(CBOR)
+	return registry
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	registry := &Registry{contentTypes: map[string]Codec{}}
+	for _, codec := range codecs {
+		registry.Register(codec)
+	}
+	return registry
+}
+
+// Register adds codec for its content type and the given aliases, e.g. the
+// application/x-msgpack name some clients still send.
+func (r *Registry) Register(codec Codec, aliases ...string) {
+	r.codecs = append(r.codecs, codec)
+	r.contentTypes[codec.ContentType()] = codec
+	for _, alias := range aliases {
+		r.contentTypes[alias] = codec
+	}
+}
+
+// ContentTypes returns the content types of the registered codecs in order.
+func (r *Registry) ContentTypes() []string {
+	contentTypes := make([]string, len(r.codecs))
+	for i, codec := range r.codecs {
+		contentTypes[i] = codec.ContentType()
+	}
+	return contentTypes
+}
+
+// ForContentType returns the codec of a Content-Type header, a missing header
+// selects the default codec.
+func (r *Registry) ForContentType(header string) (Codec, bool) {
+	if header == "" {
+		return r.codecs[0], true
+	}
+	contentType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, false
+	}
+	codec, ok := r.contentTypes[contentType]
+	return codec, ok
+}
+
+// Negotiate picks the codec with the highest quality in an Accept header. Ties
+// are won by the codec registered first and a missing header accepts anything.
+func (r *Registry) Negotiate(accept string) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return r.codecs[0], true
+	}
+	var best Codec
+	bestQuality := 0.0
+	for _, codec := range r.codecs {
+		if quality := acceptQuality(accept, codec.ContentType()); quality > bestQuality {
+			best = codec
+			bestQuality = quality
+		}
+	}
+	return best, best != nil
+}
+
+// acceptQuality returns the quality of the most specific range of accept that
+// matches contentType, 0 when none does.
+func acceptQuality(accept string, contentType string) float64 {
+	mainType := strings.SplitN(contentType, "/", 2)[0]
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		var s int
+		switch mediaRange {
+		case contentType:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		quality, specificity = q, s
+	}
+	return quality
+}
@@ -0,0 +1,80 @@
+package etag
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// FromVersion builds the strong entity tag of the JSON representation of a
+// document version.
+func FromVersion(version int64) string {
+	return ForRepresentation(version, "application/json")
+}
+
+// ForRepresentation builds the strong entity tag of a document version encoded
+// as contentType. The representations differ byte for byte, so each media type
+// gets its own tag: "<version>" for JSON and "<version>-<subtype>" otherwise,
+// like "3-msgpack".
+func ForRepresentation(version int64, contentType string) string {
+	tag := strconv.FormatInt(version, 10)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if _, subtype, _ := strings.Cut(mediaType, "/"); subtype != "" && subtype != "json" {
+		tag += "-" + subtype
+	}
+	return strconv.Quote(tag)
+}
+
+// PreconditionMet reports whether an If-Match header is satisfied by the
+// document version, the tag of any of its representations matches. An empty
+// header means the client did not ask for a precondition.
+func PreconditionMet(ifMatch string, version int64) bool {
+	if strings.TrimSpace(ifMatch) == "" {
+		return true
+	}
+	for _, tag := range splitTags(ifMatch) {
+		// If-Match uses the strong comparison, weak tags never match
+		if tag == "*" || (!isWeak(tag) && versionOf(tag) == strconv.FormatInt(version, 10)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified reports whether an If-None-Match header matches etag, meaning
+// the client already holds the current representation.
+func NotModified(ifNoneMatch string, etag string) bool {
+	for _, tag := range splitTags(ifNoneMatch) {
+		// If-None-Match uses the weak comparison
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func splitTags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isWeak(tag string) bool {
+	return strings.HasPrefix(tag, "W/")
+}
+
+// versionOf returns the document version of a strong tag built by
+// ForRepresentation, empty for any other tag.
+func versionOf(tag string) string {
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return ""
+	}
+	version, _, _ := strings.Cut(unquoted, "-")
+	return version
+}
@@ -0,0 +1,127 @@
+package json
+
+import (
+	"encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"net/http"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body extended with a stable error code.
+type Problem struct {
+	Type          string                `json:"type"`
+	Title         string                `json:"title"`
+	Status        int                   `json:"status"`
+	Detail        string                `json:"detail,omitempty"`
+	Instance      string                `json:"instance,omitempty"`
+	Code          string                `json:"code"`
+	CorrelationID string                `json:"correlationId,omitempty"`
+	TraceID       string                `json:"traceId,omitempty"`
+	Errors        []apperror.FieldError `json:"errors,omitempty"`
+}
+
+// ResponseWithProblem writes err as application/problem+json. Errors that are not
+// an *apperror.Error are treated as internal, logged and hidden from the client.
+func ResponseWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	problem := NewProblem(r, err)
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set("Content-Language", requestLocale(r))
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.ErrorContext(r.Context(), "error encoding problem response", "error", err)
+	}
+}
+
+// NewProblem returns the localized problem details of err for r, logging
+// internal errors with the correlation id of r.
+func NewProblem(r *http.Request, err error) Problem {
+	appErr := apperror.From(err)
+	status := StatusCode(appErr.Kind)
+	correlationID, _ := r.Context().Value(constant.CONTEXT_KEY_REQUEST_ID).(string)
+
+	if appErr.Kind == apperror.KindInternal {
+		log.ErrorContext(r.Context(), "internal error", "correlation_id", correlationID, "path", r.URL.Path, "error", appErr.Err)
+	}
+
+	locale := requestLocale(r)
+	return Problem{
+		Type:          "about:blank",
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        localizeMessage(locale, appErr.Code, appErr.Params, appErr.Message),
+		Instance:      r.URL.Path,
+		Code:          appErr.Code,
+		CorrelationID: correlationID,
+		TraceID:       tracing.TraceID(r.Context()),
+		Errors:        localizeFields(locale, appErr.Fields),
+	}
+}
+
+func requestLocale(r *http.Request) string {
+	locale, ok := r.Context().Value(constant.CONTEXT_KEY_LOCALE).(string)
+	if !ok || !i18n.IsSupported(locale) {
+		return i18n.DefaultLocale
+	}
+	return locale
+}
+
+func localizeMessage(locale string, code string, params map[string]string, fallback string) string {
+	message, ok := i18n.Translate(locale, code, params)
+	if !ok {
+		return fallback
+	}
+	return message
+}
+
+// localizeFields translates field messages, the field names stay in their JSON form.
+func localizeFields(locale string, fields []apperror.FieldError) []apperror.FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+	localized := make([]apperror.FieldError, len(fields))
+	for i, field := range fields {
+		params := map[string]string{"field": i18n.FieldLabel(locale, field.Field)}
+		for k, v := range field.Params {
+			params[k] = v
+		}
+		localized[i] = field
+		localized[i].Message = localizeMessage(locale, field.Code, params, field.Message)
+	}
+	return localized
+}
+
+// StatusCode maps an error kind to its HTTP status code.
+func StatusCode(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindValidation:
+		return http.StatusBadRequest
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case apperror.KindUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case apperror.KindNotAcceptable:
+		return http.StatusNotAcceptable
+	case apperror.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case apperror.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case apperror.KindPayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperror.KindFailedDependency:
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
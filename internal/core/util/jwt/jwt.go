@@ -1,74 +1,315 @@
 package jwt
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/taninchot-work/backend-challenge/internal/core/config"
-	"log"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/keypair"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// AlgorithmHS256 signs tokens with the shared secret, the other algorithms are
+// those of the keypair package.
+const AlgorithmHS256 = "HS256"
+
+// ErrSessionRevoked is returned by CheckRevoked for a token issued before the
+// sessions of its user were revoked.
+var ErrSessionRevoked = errors.New("session revoked")
+
+// retiredKey is a replaced verification key, it verifies the tokens signed
+// with its signing key until they expire.
+type retiredKey struct {
+	algorithm string
+	key       interface{}
+	until     time.Time
+}
+
+var (
+	retiredMu   sync.Mutex
+	retiredKeys []retiredKey
+
+	// parsedKeys caches the parsed PEM keys by algorithm and PEM
+	parsedKeys sync.Map
+
+	revocationCheck atomic.Pointer[RevocationCheck]
+
+	revocationMu        sync.Mutex
+	revocations         = map[string]cachedRevocation{}
+	lastRevocationSweep time.Time
+)
+
+// cachedRevocation is the revocation time of a user read from the store.
+type cachedRevocation struct {
+	revokedAt time.Time
+	until     time.Time
+}
+
+// RevocationCheck returns when the sessions of userId were last revoked, zero
+// when they never were.
+type RevocationCheck func(ctx context.Context, userId string) (time.Time, error)
+
 type JwtInterface interface {
-	GenerateJwt(userId string) (string, error)
+	GenerateJwt(userId string, locale string, role string) (string, error)
 	ValidateJwt(tokenString string) (*JwtClaim, error)
 }
 
 type JwtClaim struct {
 	UserId string `json:"UserId"`
+	Locale string `json:"locale,omitempty"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJwt(userId string) (string, error) {
-	var jwtSecret = []byte(config.GetConfig().RestServer.Jwt.Secret)
-	var jwtExpireIn = config.GetConfig().RestServer.Jwt.ExpireIn
+// GenerateJwt issues an access token for userId, locale is the user's preferred
+// locale and role the user's role, both may be empty.
+func GenerateJwt(userId string, locale string, role string) (string, error) {
+	jwtConfig := config.GetConfig().RestServer.Jwt
+	method, key, err := signingKey(jwtConfig)
+	if err != nil {
+		log.Error("error loading signing key", "error", err)
+		return "", err
+	}
 	claim := JwtClaim{
 		UserId: userId,
+		Locale: locale,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userId,
-			Issuer:    config.GetConfig().RestServer.Jwt.Issuer,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtExpireIn) * time.Millisecond)),
+			Issuer:    jwtConfig.Issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(jwtConfig.ExpireIn) * time.Millisecond)),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	tokenString, err := token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(method, claim)
+	tokenString, err := token.SignedString(key)
 	if err != nil {
-		log.Println("Error signing token:", err)
+		log.Error("error signing token", "error", err)
 		return "", err
 	}
 	return tokenString, nil
 }
 
+// ValidateJwt verifies tokenString with the current key or a retired one.
 func ValidateJwt(tokenString string) (*JwtClaim, error) {
-	jwtSecret := []byte(config.GetConfig().RestServer.Jwt.Secret)
+	keys, algorithms := verificationKeys()
 
 	token, err := jwt.ParseWithClaims(tokenString, &JwtClaim{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+		return keys, nil
+	}, jwt.WithValidMethods(algorithms))
 	if err != nil {
-		log.Println("Error parsing token:", err)
+		log.Debug("error parsing token", "error", err)
 		return nil, err
 	}
 
 	claim, ok := token.Claims.(*JwtClaim)
 	if !ok || !token.Valid {
-		log.Println("Invalid token claims")
+		log.Debug("invalid token claims")
 		return nil, jwt.ErrInvalidKey
 	}
 
 	now := time.Now()
 	if claim.ExpiresAt.Time.Before(now) {
-		log.Println("Token has expired")
+		log.Debug("token has expired")
 		return nil, jwt.ErrTokenExpired
 	}
 	if claim.IssuedAt.Time.After(now) {
-		log.Println("Token is not yet valid (issued in future)")
+		log.Debug("token is not yet valid, issued in the future")
 		return nil, jwt.ErrTokenNotValidYet
 	}
 	if claim.NotBefore.Time.After(now) {
-		log.Println("Token is not yet valid (nbf claim)")
+		log.Debug("token is not yet valid, nbf claim")
 		return nil, jwt.ErrTokenNotValidYet
 	}
 
 	return claim, nil
 }
+
+// SetRevocationCheck makes CheckRevoked ask check, nil turns revocation off.
+// It empties the cache of revocation times.
+func SetRevocationCheck(check RevocationCheck) {
+	revocationMu.Lock()
+	clear(revocations)
+	revocationMu.Unlock()
+	if check == nil {
+		revocationCheck.Store(nil)
+		return
+	}
+	revocationCheck.Store(&check)
+}
+
+// CheckRevoked returns ErrSessionRevoked when claim was issued before, or in
+// the same second as, the last revocation of the sessions of its user. Token
+// times have whole seconds.
+//
+// The revocation time of a user is cached for restServer.jwt.revocationCacheTtl,
+// so a revocation reaches the tokens of the user up to that late. An error of
+// the check is returned and not cached, the token is refused while the store
+// is unreachable unless the time of its user is still cached.
+func CheckRevoked(ctx context.Context, claim *JwtClaim) error {
+	check := revocationCheck.Load()
+	if check == nil {
+		return nil
+	}
+	revokedAt, err := sessionsRevokedAt(ctx, *check, claim.UserId)
+	if err != nil {
+		return err
+	}
+	if !revokedAt.IsZero() && (claim.IssuedAt == nil || !claim.IssuedAt.Time.After(revokedAt)) {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// sessionsRevokedAt returns the revocation time of userId from the cache, or
+// asks check and caches it.
+func sessionsRevokedAt(ctx context.Context, check RevocationCheck, userId string) (time.Time, error) {
+	ttl := time.Duration(config.GetConfig().RestServer.Jwt.RevocationCacheTTL) * time.Millisecond
+	if ttl <= 0 {
+		return check(ctx, userId)
+	}
+
+	now := time.Now()
+	revocationMu.Lock()
+	cached, ok := revocations[userId]
+	revocationMu.Unlock()
+	if ok && cached.until.After(now) {
+		return cached.revokedAt, nil
+	}
+
+	revokedAt, err := check(ctx, userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	revocationMu.Lock()
+	defer revocationMu.Unlock()
+	sweepRevocations(now, ttl)
+	revocations[userId] = cachedRevocation{revokedAt: revokedAt, until: now.Add(ttl)}
+	return revokedAt, nil
+}
+
+// sweepRevocations drops the expired revocation times at most once per ttl to
+// keep the cache bounded by the users seen within it.
+func sweepRevocations(now time.Time, ttl time.Duration) {
+	if now.Sub(lastRevocationSweep) < ttl {
+		return
+	}
+	lastRevocationSweep = now
+	for userId, cached := range revocations {
+		if !cached.until.After(now) {
+			delete(revocations, userId)
+		}
+	}
+}
+
+// OnConfigReload is the config.Subscriber of the signing key. New tokens are
+// signed with the reloaded key at once, the replaced one keeps verifying the
+// tokens it signed until they expire.
+func OnConfigReload(previous *config.Config, current *config.Config) error {
+	if previous == nil || verifiedBy(previous.RestServer.Jwt) == verifiedBy(current.RestServer.Jwt) {
+		return nil
+	}
+	key, err := verificationKey(previous.RestServer.Jwt)
+	if err != nil {
+		return err
+	}
+	retiredMu.Lock()
+	defer retiredMu.Unlock()
+	retiredKeys = append(retiredKeys, retiredKey{
+		algorithm: algorithm(previous.RestServer.Jwt),
+		key:       key,
+		until:     time.Now().Add(time.Duration(previous.RestServer.Jwt.ExpireIn) * time.Millisecond),
+	})
+	log.Info("jwt key rotated, the previous key verifies tokens until they expire")
+	return nil
+}
+
+// verificationKeys returns the current key and the retired keys that did not
+// expire, dropping the others, and the algorithms of the keys.
+func verificationKeys() (jwt.VerificationKeySet, []string) {
+	jwtConfig := config.GetConfig().RestServer.Jwt
+	var keys jwt.VerificationKeySet
+	var algorithms []string
+	if key, err := verificationKey(jwtConfig); err == nil {
+		keys.Keys = append(keys.Keys, key)
+		algorithms = append(algorithms, algorithm(jwtConfig))
+	} else {
+		log.Error("error loading verification key", "error", err)
+	}
+
+	retiredMu.Lock()
+	defer retiredMu.Unlock()
+	now := time.Now()
+	kept := retiredKeys[:0]
+	for _, retired := range retiredKeys {
+		if retired.until.After(now) {
+			kept = append(kept, retired)
+			keys.Keys = append(keys.Keys, retired.key)
+			if !slices.Contains(algorithms, retired.algorithm) {
+				algorithms = append(algorithms, retired.algorithm)
+			}
+		}
+	}
+	retiredKeys = kept
+	return keys, algorithms
+}
+
+// signingKey returns the signing method and key of cfg.
+func signingKey(cfg config.JwtConfig) (jwt.SigningMethod, interface{}, error) {
+	method := jwt.GetSigningMethod(algorithm(cfg))
+	if method == nil {
+		return nil, nil, fmt.Errorf("unknown jwt algorithm %q", cfg.Algorithm)
+	}
+	if algorithm(cfg) == AlgorithmHS256 {
+		return method, []byte(cfg.Secret), nil
+	}
+	key, err := parseKey(cfg.Algorithm, cfg.PrivateKey, func(data string, algorithm string) (interface{}, error) {
+		return keypair.ParsePrivateKey(data, algorithm)
+	})
+	return method, key, err
+}
+
+// verificationKey returns the key that verifies the tokens of cfg.
+func verificationKey(cfg config.JwtConfig) (interface{}, error) {
+	if algorithm(cfg) == AlgorithmHS256 {
+		return []byte(cfg.Secret), nil
+	}
+	return parseKey(cfg.Algorithm, cfg.PublicKey, func(data string, algorithm string) (interface{}, error) {
+		return keypair.ParsePublicKey(data, algorithm)
+	})
+}
+
+// parseKey parses the PEM key data once and caches it.
+func parseKey(algorithm string, data string, parse func(data string, algorithm string) (interface{}, error)) (interface{}, error) {
+	cacheKey := algorithm + "\n" + data
+	if key, ok := parsedKeys.Load(cacheKey); ok {
+		return key, nil
+	}
+	key, err := parse(data, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	parsedKeys.Store(cacheKey, key)
+	return key, nil
+}
+
+// verifiedBy identifies the verification key of cfg.
+func verifiedBy(cfg config.JwtConfig) string {
+	if algorithm(cfg) == AlgorithmHS256 {
+		return AlgorithmHS256 + "\n" + cfg.Secret
+	}
+	return cfg.Algorithm + "\n" + cfg.PublicKey
+}
+
+// algorithm returns the algorithm of cfg, HS256 when it is not set.
+func algorithm(cfg config.JwtConfig) string {
+	if cfg.Algorithm == "" {
+		return AlgorithmHS256
+	}
+	return cfg.Algorithm
+}
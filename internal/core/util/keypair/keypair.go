@@ -0,0 +1,113 @@
+package keypair
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Algorithms of the key pairs, named like the JWT algorithms that sign with them.
+const (
+	EdDSA = "EdDSA"
+	ES256 = "ES256"
+	RS256 = "RS256"
+)
+
+// Algorithms lists every supported algorithm.
+var Algorithms = []string{EdDSA, ES256, RS256}
+
+const rsaBits = 3072
+
+// Generate returns a new private key of algorithm as PKCS #8 PEM and its
+// public key as PKIX PEM.
+func Generate(algorithm string) ([]byte, []byte, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case EdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case ES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case RS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	default:
+		return nil, nil, unknownAlgorithm(algorithm)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), nil
+}
+
+// ParsePrivateKey parses a PKCS #8 PEM private key of algorithm.
+func ParsePrivateKey(data string, algorithm string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || !fits(signer.Public(), algorithm) {
+		return nil, fmt.Errorf("not a private key of %s", algorithm)
+	}
+	return signer, nil
+}
+
+// ParsePublicKey parses a PKIX PEM public key of algorithm.
+func ParsePublicKey(data string, algorithm string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if !fits(key, algorithm) {
+		return nil, fmt.Errorf("not a public key of %s", algorithm)
+	}
+	return key, nil
+}
+
+// Match tells whether public is the public key of private.
+func Match(private crypto.Signer, public crypto.PublicKey) bool {
+	key, ok := private.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(public)
+}
+
+// fits tells whether key is a public key of algorithm.
+func fits(key crypto.PublicKey, algorithm string) bool {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return algorithm == EdDSA
+	case *ecdsa.PublicKey:
+		return algorithm == ES256 && key.Curve == elliptic.P256()
+	case *rsa.PublicKey:
+		return algorithm == RS256
+	}
+	return false
+}
+
+func unknownAlgorithm(algorithm string) error {
+	return fmt.Errorf("unknown key algorithm %q, use one of %v", algorithm, Algorithms)
+}
@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// report fields by their json name so clients can match them to the request body
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// locale accepts the locales that have a message catalog
+	_ = v.RegisterValidation("locale", func(fl validator.FieldLevel) bool {
+		return i18n.IsSupported(fl.Field().String())
+	})
+	// event_type accepts the user lifecycle events that webhooks can subscribe to
+	_ = v.RegisterValidation("event_type", func(fl validator.FieldLevel) bool {
+		return slices.Contains(entity.EventTypes(), fl.Field().String())
+	})
+	return v
+}
+
+// Struct validates s and returns a validation *apperror.Error with one entry per invalid field.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apperror.Internal(err)
+	}
+
+	fields := make([]apperror.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, fieldError(fieldErr))
+	}
+	return apperror.Validation(apperror.CodeValidationFailed, "validation failed", fields...)
+}
+
+func fieldError(err validator.FieldError) apperror.FieldError {
+	field := err.Field()
+	params := map[string]string{"param": err.Param()}
+	switch err.Tag() {
+	case "required":
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldRequired, Message: fmt.Sprintf("%s is required", field)}
+	case "email":
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldInvalidEmail, Message: fmt.Sprintf("%s must be a valid email address", field)}
+	case "min":
+		switch err.Kind() {
+		case reflect.Slice:
+			return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooSmall, Message: fmt.Sprintf("%s must have at least %s items", field, err.Param()), Params: params}
+		case reflect.Int:
+			return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooSmall, Message: fmt.Sprintf("%s must be at least %s", field, err.Param()), Params: params}
+		}
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooShort, Message: fmt.Sprintf("%s must be at least %s characters", field, err.Param()), Params: params}
+	case "max":
+		switch err.Kind() {
+		case reflect.Slice:
+			return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooLarge, Message: fmt.Sprintf("%s must have at most %s items", field, err.Param()), Params: params}
+		case reflect.Int:
+			return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooLarge, Message: fmt.Sprintf("%s must be at most %s", field, err.Param()), Params: params}
+		}
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldTooLong, Message: fmt.Sprintf("%s must be at most %s characters", field, err.Param()), Params: params}
+	case "locale":
+		params["param"] = strings.Join(i18n.SupportedLocales(), ", ")
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldUnsupportedLocale, Message: fmt.Sprintf("%s must be one of: %s", field, params["param"]), Params: params}
+	case "event_type":
+		params["param"] = strings.Join(entity.EventTypes(), ", ")
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldNotAllowed, Message: fmt.Sprintf("%s must be one of: %s", field, params["param"]), Params: params}
+	default:
+		return apperror.FieldError{Field: field, Code: apperror.CodeFieldInvalid, Message: fmt.Sprintf("%s is invalid", field)}
+	}
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import "time"
+
+// Backoff returns the delay before the next attempt after attempts failed
+// attempts. It doubles from initial and is capped at max.
+func Backoff(attempts int, initial time.Duration, max time.Duration) time.Duration {
+	delay := initial
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
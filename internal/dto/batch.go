@@ -0,0 +1,60 @@
+package dto
+
+import "github.com/taninchot-work/backend-challenge/internal/core/util/json"
+
+// Methods of a batch operation.
+const (
+	BatchMethodGet    = "get"
+	BatchMethodCreate = "create"
+	BatchMethodUpdate = "update"
+	BatchMethodDelete = "delete"
+)
+
+type BatchRequest struct {
+	// Atomic runs every operation in one transaction, nothing is changed when one of them fails.
+	Atomic     bool             `json:"atomic,omitempty"`
+	Operations []BatchOperation `json:"operations" validate:"required"`
+}
+
+type BatchOperation struct {
+	// ID is an optional client reference returned with the result.
+	ID      string     `json:"id,omitempty"`
+	Method  string     `json:"method" validate:"required,oneof=get create update delete"`
+	UserID  string     `json:"userId,omitempty"`
+	IfMatch string     `json:"ifMatch,omitempty"`
+	User    *BatchUser `json:"user,omitempty"`
+}
+
+// BatchUser is the user of a create or update operation, password is only used on create.
+type BatchUser struct {
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// BatchResult holds one result per operation, in the order of the operations.
+type BatchResult struct {
+	RolledBack bool
+	Results    []BatchOperationResult
+}
+
+type BatchOperationResult struct {
+	ID   string
+	User *UserGetMeResponse
+	Err  error
+}
+
+type BatchResponse struct {
+	// RolledBack is set when an atomic batch failed and none of its changes were kept.
+	RolledBack bool                `json:"rolledBack,omitempty"`
+	Results    []BatchResponseItem `json:"results"`
+}
+
+type BatchResponseItem struct {
+	ID     string             `json:"id,omitempty"`
+	Status int                `json:"status"`
+	ETag   string             `json:"etag,omitempty"`
+	User   *UserGetMeResponse `json:"user,omitempty"`
+	Error  *json.Problem      `json:"error,omitempty"`
+}
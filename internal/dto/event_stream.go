@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/taninchot-work/backend-challenge/internal/entity"
+
+// StreamEventReset tells a stream client that it missed events and has to reload its state.
+const StreamEventReset = "stream.reset"
+
+// StreamMessage is a WebSocket frame of the event stream.
+type StreamMessage struct {
+	ID    string        `json:"id,omitempty"`
+	Type  string        `json:"type"`
+	Event *entity.Event `json:"event,omitempty"`
+}
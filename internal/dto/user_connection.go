@@ -0,0 +1,29 @@
+package dto
+
+// UserConnectionRequest asks for a page of users after the cursor of the last
+// edge of the previous page.
+type UserConnectionRequest struct {
+	First  int
+	After  string
+	Filter UserListFilter
+}
+
+type UserListFilter struct {
+	Name  string
+	Email string
+}
+
+type UserConnectionResponse struct {
+	Edges    []UserEdge
+	PageInfo PageInfo
+}
+
+type UserEdge struct {
+	Cursor string
+	Node   UserListGetResponseItem
+}
+
+type PageInfo struct {
+	EndCursor   string
+	HasNextPage bool
+}
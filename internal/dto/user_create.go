@@ -4,6 +4,7 @@ type UserRegisterRequest struct {
 	Name     string `json:"name" validate:"required" minlength:"3" maxlength:"50"`
 	Email    string `json:"email" validate:"required,email" minlength:"5" maxlength:"100"`
 	Password string `json:"password" validate:"required" minlength:"8" maxlength:"50"`
+	Locale   string `json:"locale,omitempty" validate:"omitempty,locale"`
 }
 
 type UserRegisterResponse struct {
@@ -0,0 +1,5 @@
+package dto
+
+type UserDeleteRequest struct {
+	IfMatch string
+}
@@ -1,7 +1,10 @@
 package dto
 
 type UserGetMeResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Locale string `json:"locale,omitempty"`
+
+	Version int64 `json:"-"`
 }
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// UserRecord is a user as exported and imported by the admin CLI, with the
+// bcrypt hash of the password.
+type UserRecord struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name" validate:"required"`
+	Email        string    `json:"email" validate:"required,email"`
+	PasswordHash string    `json:"passwordHash" validate:"required"`
+	Locale       string    `json:"locale,omitempty" validate:"omitempty,locale"`
+	Role         string    `json:"role,omitempty" validate:"omitempty,oneof=user admin"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -1,12 +1,37 @@
 package dto
 
+import "time"
+
 type UserUpdateRequest struct {
-	Name  string `json:"name" validate:"required" minlength:"3" maxlength:"50"`
-	Email string `json:"email" validate:"required,email" minlength:"5" maxlength:"100"`
+	Name   string `json:"name" validate:"required" minlength:"3" maxlength:"50"`
+	Email  string `json:"email" validate:"required,email" minlength:"5" maxlength:"100"`
+	Locale string `json:"locale,omitempty" validate:"omitempty,locale"`
+
+	IfMatch string `json:"-"`
+	// ClearLocale removes the locale when Locale is empty, an update keeps it.
+	ClearLocale bool `json:"-"`
 }
 
 type UserUpdateResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Locale string `json:"locale,omitempty"`
+
+	Version int64 `json:"-"`
+}
+
+type UserPatchRequest struct {
+	ContentType string
+	Patch       []byte
+	IfMatch     string
+}
+
+// UserPatchDocument is the representation of a user that patch documents are applied to.
+type UserPatchDocument struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Locale    string    `json:"locale,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+type WebhookCreateRequest struct {
+	URL    string   `json:"url" validate:"required,http_url" maxlength:"2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,event_type"`
+	// Secret signs the deliveries, one is generated when it is empty.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+}
+
+type WebhookResponse struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryListRequest struct {
+	WebhookID string `json:"webhookId,omitempty"`
+	Status    string `json:"status,omitempty" validate:"omitempty,oneof=pending succeeded dead"`
+	Page      int    `json:"page" validate:"min=1"`
+	Limit     int    `json:"limit" validate:"min=1,max=100"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Page       int                       `json:"page"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhookId"`
+	EventID   string `json:"eventId"`
+	Event     string `json:"event"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	// NextAttemptAt is only set while the delivery is pending.
+	NextAttemptAt *time.Time               `json:"nextAttemptAt,omitempty"`
+	History       []WebhookAttemptResponse `json:"history"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	UpdatedAt     time.Time                `json:"updatedAt"`
+}
+
+type WebhookAttemptResponse struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
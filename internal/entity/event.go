@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Types of the user lifecycle events.
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+// EventTypes returns every event type that can be subscribed to.
+func EventTypes() []string {
+	return []string{EventUserCreated, EventUserUpdated, EventUserDeleted}
+}
+
+// Event is raised after a user was changed.
+type Event struct {
+	ID         string       `json:"id" bson:"_id"`
+	Type       string       `json:"type" bson:"type"`
+	OccurredAt time.Time    `json:"occurredAt" bson:"occurred_at"`
+	Data       UserSnapshot `json:"data" bson:"data"`
+}
+
+// UserSnapshot is the state of a user after the change, a deleted user only has its id.
+type UserSnapshot struct {
+	ID      string `json:"id" bson:"id"`
+	Name    string `json:"name,omitempty" bson:"name,omitempty"`
+	Email   string `json:"email,omitempty" bson:"email,omitempty"`
+	Locale  string `json:"locale,omitempty" bson:"locale,omitempty"`
+	Version int64  `json:"version,omitempty" bson:"version,omitempty"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// Statuses of an outbox event. A dead event has used up its publish attempts
+// and stays in the outbox without being published again.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxEvent is an event saved in the same transaction as the change that
+// raised it. The relay publishes it and removes it from the outbox afterwards.
+type OutboxEvent struct {
+	Event `bson:",inline"`
+	// AggregateID is the id of the changed user, events of one user are published in Sequence order.
+	AggregateID string `bson:"aggregate_id"`
+	// Sequence is the user version the change produced.
+	Sequence  int64     `bson:"sequence"`
+	CreatedAt time.Time `bson:"created_at"`
+	Status    string    `bson:"status"`
+	// Attempts and LastError record failed publishes, the event is not
+	// published again before NextAttemptAt.
+	Attempts      int       `bson:"attempts"`
+	LastError     string    `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+}
@@ -5,11 +5,42 @@ import (
 	"time"
 )
 
+// Roles of a user, a user without a role has RoleUser.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID        bson.ObjectID `json:"id" bson:"_id,omitempty"`
 	Name      string        `json:"name" bson:"name"`
 	Email     string        `json:"email" bson:"email" unique:"true"`
 	Password  string        `json:"password" bson:"password"`
+	Locale    string        `json:"locale" bson:"locale,omitempty"`
+	Role      string        `json:"role" bson:"role,omitempty"`
 	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at" bson:"updated_at"`
+	Version   int64         `json:"version" bson:"version"`
+	// SessionsRevokedAt invalidates the access tokens issued until then.
+	SessionsRevokedAt time.Time `json:"sessions_revoked_at" bson:"sessions_revoked_at,omitempty"`
+}
+
+// UserUpdate describes a partial update of a user, nil fields are left unchanged.
+type UserUpdate struct {
+	Name              *string
+	Email             *string
+	Password          *string
+	Locale            *string
+	SessionsRevokedAt *time.Time
+}
+
+func (u UserUpdate) IsEmpty() bool {
+	return u.Name == nil && u.Email == nil && u.Password == nil && u.Locale == nil && u.SessionsRevokedAt == nil
+}
+
+// UserFilter narrows a user list, empty fields match every user.
+type UserFilter struct {
+	// Name matches users whose name contains it, ignoring case.
+	Name  string
+	Email string
 }
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+// Statuses of a webhook delivery. A dead delivery has used up its attempts and
+// is only sent again when it is redelivered.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusDead      = "dead"
+)
+
+type Webhook struct {
+	ID        bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL       string        `json:"url" bson:"url"`
+	Secret    string        `json:"secret" bson:"secret"`
+	Events    []string      `json:"events" bson:"events"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" bson:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one webhook. Payload is the exact body
+// that is sent on every attempt.
+type WebhookDelivery struct {
+	ID        bson.ObjectID `json:"id" bson:"_id,omitempty"`
+	WebhookID bson.ObjectID `json:"webhook_id" bson:"webhook_id"`
+	EventID   string        `json:"event_id" bson:"event_id"`
+	EventType string        `json:"event_type" bson:"event_type"`
+	Payload   []byte        `json:"payload" bson:"payload"`
+	Status    string        `json:"status" bson:"status"`
+	// Attempts counts the attempts since the delivery was created or redelivered.
+	Attempts      int              `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time        `json:"next_attempt_at" bson:"next_attempt_at"`
+	History       []WebhookAttempt `json:"history" bson:"history"`
+	CreatedAt     time.Time        `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" bson:"updated_at"`
+}
+
+// WebhookAttempt records the outcome of one request to the webhook URL.
+type WebhookAttempt struct {
+	At         time.Time     `json:"at" bson:"at"`
+	StatusCode int           `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty" bson:"error,omitempty"`
+	Duration   time.Duration `json:"duration" bson:"duration"`
+}
+
+// WebhookDeliveryFilter narrows a delivery list, empty fields match every delivery.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    string
+}
@@ -0,0 +1,66 @@
+package graphqlserver
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+)
+
+var errUnauthorized = apperror.Unauthorized(apperror.CodeUnauthorized, "Unauthorized")
+
+// extendedError carries the error code and field errors in the extensions of a
+// GraphQL error, the same information a problem details body has.
+type extendedError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *extendedError) Error() string {
+	return e.message
+}
+
+func (e *extendedError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+func toGraphQLError(ctx context.Context, err error) error {
+	appErr := apperror.From(err)
+	if appErr.Kind == apperror.KindInternal {
+		log.ErrorContext(ctx, "internal error", "error", appErr.Err)
+	}
+
+	locale, ok := ctx.Value(constant.CONTEXT_KEY_LOCALE).(string)
+	if !ok || !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
+
+	extensions := map[string]interface{}{"code": appErr.Code}
+	if len(appErr.Fields) > 0 {
+		fields := make([]map[string]string, 0, len(appErr.Fields))
+		for _, field := range appErr.Fields {
+			params := map[string]string{"field": i18n.FieldLabel(locale, field.Field)}
+			for k, v := range field.Params {
+				params[k] = v
+			}
+			fields = append(fields, map[string]string{
+				"field":   field.Field,
+				"code":    field.Code,
+				"message": localize(locale, field.Code, params, field.Message),
+			})
+		}
+		extensions["fields"] = fields
+	}
+	return &extendedError{
+		message:    localize(locale, appErr.Code, appErr.Params, appErr.Message),
+		extensions: extensions,
+	}
+}
+
+func localize(locale string, code string, params map[string]string, fallback string) string {
+	message, ok := i18n.Translate(locale, code, params)
+	if !ok {
+		return fallback
+	}
+	return message
+}
@@ -0,0 +1,127 @@
+package graphqlserver
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+// Limits bound the cost of a single operation, zero disables a limit.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// maxAuthMutations is how many login and register mutations an operation may
+// run. Each hashes a password, aliasing them would multiply that work and the
+// password guesses of one request.
+const maxAuthMutations = 1
+
+// authMutations are the mutations that hash a password.
+var authMutations = map[string]bool{"login": true, "register": true}
+
+// operationCost measures an operation before it is executed. Every field costs
+// one, and the cost of the selections of a list field with a first argument is
+// multiplied by the requested page size. Introspection fields are not counted.
+type operationCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// authMutations counts the login and register fields of a mutation
+	authMutations int
+	mutation      bool
+}
+
+// measure returns the depth and complexity of the operation of doc, and how
+// many login and register mutations it runs.
+func measure(doc *ast.Document, operationName string, variables map[string]interface{}) (depth int, complexity int, auth int) {
+	cost := &operationCost{
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+	}
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			cost.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || (definition.Name != nil && definition.Name.Value == operationName)) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return 0, 0, 0
+	}
+	cost.mutation = operation.Operation == ast.OperationTypeMutation
+	depth, complexity = cost.selectionSet(operation.SelectionSet, map[string]bool{})
+	return depth, complexity, cost.authMutations
+}
+
+func (c *operationCost) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (depth int, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, n int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			if c.mutation && authMutations[selection.Name.Value] {
+				c.authMutations++
+			}
+			childDepth, childComplexity := c.selectionSet(selection.SelectionSet, visiting)
+			d = childDepth + 1
+			n = 1 + c.multiplier(selection)*childComplexity
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			// fragment cycles are rejected by validation, skip them here
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			d, n = c.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += n
+	}
+	return depth, complexity
+}
+
+// multiplier returns the page size requested with the first argument of field.
+func (c *operationCost) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(value.Value); err == nil && first > 0 {
+				return first
+			}
+		case *ast.Variable:
+			switch first := c.variables[value.Name.Value].(type) {
+			case int:
+				if first > 0 {
+					return first
+				}
+			case float64:
+				if first > 0 {
+					return int(first)
+				}
+			}
+		}
+		break
+	}
+	if field.Name.Value == "users" {
+		return defaultConnectionSize
+	}
+	return 1
+}
@@ -0,0 +1,77 @@
+package graphqlserver
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"sort"
+	"sync"
+)
+
+// userLoader batches the user lookups of one request. Resolvers call Load and
+// return the thunk, the executor resolves thunks after every field of a level
+// was visited, so the first thunk loads all ids requested so far in one call.
+type userLoader struct {
+	userService service.UserService
+
+	mu      sync.Mutex
+	pending []string
+	users   map[string]*dto.UserListGetResponseItem
+	errs    map[string]error
+}
+
+func newUserLoader(userService service.UserService) *userLoader {
+	return &userLoader{
+		userService: userService,
+		users:       map[string]*dto.UserListGetResponseItem{},
+		errs:        map[string]error{},
+	}
+}
+
+// Load returns a thunk resolving to the user with id, or nil when it does not exist.
+func (l *userLoader) Load(ctx context.Context, id string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, loaded := l.users[id]; !loaded {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.pending) > 0 {
+			l.flush(ctx)
+		}
+		if err := l.errs[id]; err != nil {
+			return nil, toGraphQLError(ctx, err)
+		}
+		user := l.users[id]
+		if user == nil {
+			return nil, nil
+		}
+		return *user, nil
+	}
+}
+
+func (l *userLoader) flush(ctx context.Context) {
+	ids := make([]string, 0, len(l.pending))
+	seen := map[string]bool{}
+	for _, id := range l.pending {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	l.pending = nil
+	// the executor visits fields in map order, sort so a request always makes the same call
+	sort.Strings(ids)
+
+	users, err := l.userService.GetUsersByIDs(ctx, ids)
+	for _, id := range ids {
+		l.users[id] = nil
+		l.errs[id] = err
+	}
+	for i := range users {
+		l.users[users[i].ID] = &users[i]
+	}
+}
@@ -0,0 +1,5 @@
+package graphqlserver
+
+import "github.com/taninchot-work/backend-challenge/internal/core/logger"
+
+var log = logger.New("graphqlserver")
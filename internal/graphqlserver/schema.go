@@ -0,0 +1,324 @@
+package graphqlserver
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"strings"
+)
+
+// defaultConnectionSize is the page size of users when first is omitted.
+const defaultConnectionSize = 20
+
+type resolver struct {
+	userService service.UserService
+}
+
+// NewSchema builds the GraphQL schema over the user operations of userService.
+func NewSchema(userService service.UserService) (graphql.Schema, error) {
+	r := &resolver{userService: userService}
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"locale": &graphql.Field{Type: graphql.String, Resolve: resolveLocale},
+			"version": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Version of the user, only known for the current user. Pass it as expectedVersion to reject concurrent changes.",
+				Resolve:     resolveVersion,
+			},
+		},
+	})
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"endCursor":   &graphql.Field{Type: graphql.String, Resolve: resolveEndCursor},
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+	userEdgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(userType)},
+		},
+	})
+	userConnectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "UserConnection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userEdgeType)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+	authPayloadType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AuthPayload",
+		Fields: graphql.Fields{
+			"accessToken": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"user": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	userFilterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UserFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":  &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Case-insensitive substring of the name"},
+			"email": &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Exact email address"},
+		},
+	})
+	registerInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "RegisterInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"locale":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	loginInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "LoginInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	updateMeInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdateMeInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"locale": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "The authenticated user",
+				Resolve:     r.me,
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.user,
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewNonNull(userConnectionType),
+				Args: graphql.FieldConfigArgument{
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultConnectionSize},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+					"filter": &graphql.ArgumentConfig{Type: userFilterInput},
+				},
+				Resolve: r.users,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"register": &graphql.Field{
+				Type: graphql.NewNonNull(authPayloadType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(registerInput)},
+				},
+				Resolve: r.register,
+			},
+			"login": &graphql.Field{
+				Type: graphql.NewNonNull(authPayloadType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(loginInput)},
+				},
+				Resolve: r.login,
+			},
+			"updateMe": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"input":           &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateMeInput)},
+					"expectedVersion": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: r.updateMe,
+			},
+			"deleteMe": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"expectedVersion": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: r.deleteMe,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func (r *resolver) me(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := currentUserID(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.userService.GetUserByID(p.Context, userId)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return response, nil
+}
+
+func (r *resolver) user(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	loader, ok := p.Context.Value(constant.CONTEXT_KEY_USER_LOADER).(*userLoader)
+	if !ok {
+		loader = newUserLoader(r.userService)
+	}
+	return loader.Load(p.Context, id), nil
+}
+
+func (r *resolver) users(p graphql.ResolveParams) (interface{}, error) {
+	req := dto.UserConnectionRequest{}
+	req.First, _ = p.Args["first"].(int)
+	req.After, _ = p.Args["after"].(string)
+	if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+		req.Filter.Name, _ = filter["name"].(string)
+		req.Filter.Email, _ = filter["email"].(string)
+	}
+	req.Filter.Email = strings.ToLower(req.Filter.Email)
+
+	response, err := r.userService.GetUserConnection(p.Context, req)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return response, nil
+}
+
+func (r *resolver) register(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	req := dto.UserRegisterRequest{}
+	req.Name, _ = input["name"].(string)
+	req.Email, _ = input["email"].(string)
+	req.Password, _ = input["password"].(string)
+	req.Locale, _ = input["locale"].(string)
+	if err := validation.Struct(req); err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	req.Email = strings.ToLower(req.Email)
+
+	response, err := r.userService.RegisterUser(p.Context, req)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return response, nil
+}
+
+func (r *resolver) login(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	req := dto.UserLoginRequest{}
+	req.Email, _ = input["email"].(string)
+	req.Password, _ = input["password"].(string)
+	if err := validation.Struct(req); err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	req.Email = strings.ToLower(req.Email)
+
+	response, err := r.userService.LoginUser(p.Context, req)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return response, nil
+}
+
+func (r *resolver) updateMe(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := currentUserID(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	input, _ := p.Args["input"].(map[string]interface{})
+	req := dto.UserUpdateRequest{}
+	req.Name, _ = input["name"].(string)
+	req.Email, _ = input["email"].(string)
+	req.Locale, _ = input["locale"].(string)
+	if err := validation.Struct(req); err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	req.Email = strings.ToLower(req.Email)
+	if version, ok := p.Args["expectedVersion"].(int); ok {
+		req.IfMatch = etag.FromVersion(int64(version))
+	}
+
+	response, err := r.userService.UpdateUser(p.Context, userId, req)
+	if err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return response, nil
+}
+
+func (r *resolver) deleteMe(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := currentUserID(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	req := dto.UserDeleteRequest{}
+	if version, ok := p.Args["expectedVersion"].(int); ok {
+		req.IfMatch = etag.FromVersion(int64(version))
+	}
+	if err := r.userService.DeleteUser(p.Context, userId, req); err != nil {
+		return nil, toGraphQLError(p.Context, err)
+	}
+	return true, nil
+}
+
+// currentUserID returns the user id that JwtMiddleware or OptionalJwtMiddleware stored in ctx.
+func currentUserID(ctx context.Context) (string, error) {
+	userId, ok := ctx.Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || userId == "" {
+		return "", toGraphQLError(ctx, errUnauthorized)
+	}
+	return userId, nil
+}
+
+// resolveLocale resolves an unset locale to null.
+func resolveLocale(p graphql.ResolveParams) (interface{}, error) {
+	var locale string
+	switch source := p.Source.(type) {
+	case dto.UserGetMeResponse:
+		locale = source.Locale
+	case dto.UserUpdateResponse:
+		locale = source.Locale
+	}
+	if locale == "" {
+		return nil, nil
+	}
+	return locale, nil
+}
+
+// resolveEndCursor resolves the cursor of an empty page to null.
+func resolveEndCursor(p graphql.ResolveParams) (interface{}, error) {
+	pageInfo, ok := p.Source.(dto.PageInfo)
+	if !ok || pageInfo.EndCursor == "" {
+		return nil, nil
+	}
+	return pageInfo.EndCursor, nil
+}
+
+func resolveVersion(p graphql.ResolveParams) (interface{}, error) {
+	switch source := p.Source.(type) {
+	case dto.UserGetMeResponse:
+		return source.Version, nil
+	case dto.UserUpdateResponse:
+		return source.Version, nil
+	}
+	return nil, nil
+}
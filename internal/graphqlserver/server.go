@@ -0,0 +1,140 @@
+package graphqlserver
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"strconv"
+)
+
+// GraphQLRequest is a GraphQL request as sent over HTTP.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is a GraphQL response, Errors carry the error code and field errors
+// in their extensions.
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError is one entry of the errors of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []ErrorLocation        `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type Server interface {
+	Execute(ctx context.Context, req GraphQLRequest) GraphQLResponse
+}
+
+type serverImpl struct {
+	schema      graphql.Schema
+	userService service.UserService
+	limits      Limits
+}
+
+func NewServer(userService service.UserService, limits Limits) (Server, error) {
+	schema, err := NewSchema(userService)
+	if err != nil {
+		return nil, err
+	}
+	return &serverImpl{
+		schema:      schema,
+		userService: userService,
+		limits:      limits,
+	}, nil
+}
+
+// Execute parses and validates req, rejects it when it exceeds the limits and
+// otherwise runs it with a user loader shared by all resolvers of the request.
+func (s *serverImpl) Execute(ctx context.Context, req GraphQLRequest) GraphQLResponse {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return toResponse(nil, gqlerrors.FormatErrors(err), apperror.CodeInvalidQuery)
+	}
+	if result := graphql.ValidateDocument(&s.schema, doc, nil); !result.IsValid {
+		return toResponse(nil, result.Errors, apperror.CodeInvalidQuery)
+	}
+
+	depth, complexity, auth := measure(doc, req.OperationName, req.Variables)
+	if s.limits.MaxDepth > 0 && depth > s.limits.MaxDepth {
+		return errorResponse(ctx, errQueryTooDeep(depth, s.limits.MaxDepth))
+	}
+	if s.limits.MaxComplexity > 0 && complexity > s.limits.MaxComplexity {
+		return errorResponse(ctx, errQueryTooComplex(complexity, s.limits.MaxComplexity))
+	}
+	if auth > maxAuthMutations {
+		return errorResponse(ctx, errTooManyAuthMutations(auth))
+	}
+
+	ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_LOADER, newUserLoader(s.userService))
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	return toResponse(result.Data, result.Errors, "")
+}
+
+func errQueryTooDeep(depth int, max int) *apperror.Error {
+	return apperror.Validation(apperror.CodeQueryTooDeep, "query is too deep").
+		WithParam("depth", strconv.Itoa(depth)).
+		WithParam("max", strconv.Itoa(max))
+}
+
+func errQueryTooComplex(complexity int, max int) *apperror.Error {
+	return apperror.Validation(apperror.CodeQueryTooComplex, "query is too complex").
+		WithParam("complexity", strconv.Itoa(complexity)).
+		WithParam("max", strconv.Itoa(max))
+}
+
+func errTooManyAuthMutations(count int) *apperror.Error {
+	return apperror.Validation(apperror.CodeTooManyAuthMutations, "an operation may run one login or register").
+		WithParam("count", strconv.Itoa(count)).
+		WithParam("max", strconv.Itoa(maxAuthMutations))
+}
+
+func errorResponse(ctx context.Context, err error) GraphQLResponse {
+	gqlErr := toGraphQLError(ctx, err)
+	return toResponse(nil, gqlerrors.FormatErrors(gqlerrors.NewError(gqlErr.Error(), nil, "", nil, nil, gqlErr)), "")
+}
+
+// toResponse converts the errors of graphql-go, errors without a code get defaultCode.
+func toResponse(data interface{}, errs []gqlerrors.FormattedError, defaultCode string) GraphQLResponse {
+	response := GraphQLResponse{Data: data}
+	for _, err := range errs {
+		converted := GraphQLError{
+			Message:    err.Message,
+			Path:       err.Path,
+			Extensions: err.Extensions,
+		}
+		for _, location := range err.Locations {
+			converted.Locations = append(converted.Locations, ErrorLocation{Line: location.Line, Column: location.Column})
+		}
+		if converted.Extensions == nil && defaultCode != "" {
+			converted.Extensions = map[string]interface{}{"code": defaultCode}
+		}
+		response.Errors = append(response.Errors, converted)
+	}
+	return response
+}
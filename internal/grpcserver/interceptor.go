@@ -0,0 +1,155 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"math"
+	"net"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecoveryInterceptor turns a panic in a handler into an Internal status.
+func RecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.ErrorContext(ctx, "recovered from panic", "panic", recovered, "stack", string(debug.Stack()))
+			err = toStatus(ctx, apperror.Internal(fmt.Errorf("panic: %v", recovered)))
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// LoggingInterceptor gives every call a logger context with a request id, taken
+// from the x-request-id metadata when present, and logs the call with its
+// status code and duration.
+func LoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	requestID := firstMetadata(ctx, "x-request-id")
+	if requestID == "" {
+		b := make([]byte, 16)
+		_, _ = rand.Read(b)
+		requestID = hex.EncodeToString(b)
+	}
+	ctx = logger.NewContext(ctx, requestID, "grpc")
+	logger.SetRoute(ctx, info.FullMethod)
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	log.LogAttrs(ctx, level, "call completed",
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+	return resp, err
+}
+
+// LocaleInterceptor negotiates the locale from the accept-language metadata.
+func LocaleInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	locale := i18n.Negotiate(firstMetadata(ctx, "accept-language"))
+	return handler(context.WithValue(ctx, constant.CONTEXT_KEY_LOCALE, locale), req)
+}
+
+// RateLimitInterceptor is the gRPC equivalent of RateLimitMiddleware. A call is
+// matched to a policy by its full method and keyed by the peer address, a call
+// over the budget fails with ResourceExhausted and a retry-after header in
+// seconds. The store failing never blocks calls.
+func RateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		policy, ok := limiter.MatchMethod(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		result, err := limiter.Take(ctx, policy, "ip:"+peerHost(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "rate limit store failed", "error", err)
+			return handler(ctx, req)
+		}
+		if !result.Allowed {
+			retryAfter := strconv.Itoa(int(math.Ceil(max(result.RetryAfter, 0).Seconds())))
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+			return nil, toStatus(ctx, errRateLimitExceeded)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// peerHost returns the IP of the peer of ctx, or its address when it has no port.
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// AuthInterceptor is the gRPC equivalent of JwtMiddleware. Calls to the
+// protected methods need a valid "authorization: Bearer <token>" metadata entry,
+// every other method, including health and reflection, is public.
+func AuthInterceptor(protected map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !protected[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		token, ok := strings.CutPrefix(firstMetadata(ctx, "authorization"), "Bearer ")
+		if !ok || token == "" {
+			log.DebugContext(ctx, "missing or malformed authorization metadata")
+			return nil, toStatus(ctx, errUnauthorized)
+		}
+		claim, err := jwt.ValidateJwt(token)
+		if err != nil {
+			log.DebugContext(ctx, "token validation failed", "error", err)
+			return nil, toStatus(ctx, errUnauthorized)
+		}
+		if err := jwt.CheckRevoked(ctx, claim); err != nil {
+			log.DebugContext(ctx, "token revoked", "error", err)
+			return nil, toStatus(ctx, errUnauthorized)
+		}
+
+		logger.SetUserID(ctx, claim.UserId)
+		ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_ID, claim.UserId)
+		ctx = context.WithValue(ctx, constant.CONTEXT_KEY_USER_ROLE, claim.Role)
+		// the user's saved preference wins over the negotiated accept-language
+		if i18n.IsSupported(claim.Locale) {
+			ctx = context.WithValue(ctx, constant.CONTEXT_KEY_LOCALE, claim.Locale)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,80 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/grpcserver/userpb"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+)
+
+// protectedMethods require an access token, like the routes wrapped in JwtMiddleware.
+var protectedMethods = map[string]bool{
+	userpb.UserService_GetMe_FullMethodName:      true,
+	userpb.UserService_UpdateUser_FullMethodName: true,
+	userpb.UserService_DeleteUser_FullMethodName: true,
+}
+
+// Server is the gRPC server with the user, health and reflection services.
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+// NewServer returns the server of svc. limiter applies the rate-limit policies
+// to the calls, nil turns rate limiting off.
+func NewServer(svc *service.Service, limiter *ratelimit.Limiter) *Server {
+	interceptors := []grpc.UnaryServerInterceptor{
+		LoggingInterceptor,
+		RecoveryInterceptor,
+		LocaleInterceptor,
+	}
+	if limiter != nil {
+		interceptors = append(interceptors, RateLimitInterceptor(limiter))
+	}
+	interceptors = append(interceptors, AuthInterceptor(protectedMethods))
+	server := grpc.NewServer(
+		// continues the trace of the traceparent metadata, before the interceptors run
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(interceptors...),
+	)
+	healthServer := health.NewServer()
+
+	userpb.RegisterUserServiceServer(server, NewUserServer(svc.UserService))
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	healthServer.SetServingStatus(userpb.UserService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	return &Server{
+		server: server,
+		health: healthServer,
+	}
+}
+
+// Serve accepts connections on lis until Shutdown is called.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// Shutdown reports NOT_SERVING, waits for running calls to finish and stops the
+// server immediately when ctx is done first.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+		<-stopped
+	}
+}
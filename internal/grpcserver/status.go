@@ -0,0 +1,105 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"maps"
+)
+
+const errorDomain = "user.v1"
+
+var (
+	errUnauthorized      = apperror.Unauthorized(apperror.CodeUnauthorized, "Unauthorized")
+	errRateLimitExceeded = apperror.New(apperror.KindTooManyRequests, apperror.CodeRateLimitExceeded, "too many requests, please retry later")
+)
+
+// toStatus converts err to a gRPC status the same way json.ResponseWithProblem
+// converts it to problem details: the code travels as ErrorInfo reason, the
+// trace id in its metadata, field errors as BadRequest violations and messages
+// are localized.
+func toStatus(ctx context.Context, err error) error {
+	appErr := apperror.From(err)
+	if appErr.Kind == apperror.KindInternal {
+		log.ErrorContext(ctx, "internal error", "error", appErr.Err)
+	}
+
+	locale, ok := ctx.Value(constant.CONTEXT_KEY_LOCALE).(string)
+	if !ok || !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
+
+	metadata := appErr.Params
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		metadata = maps.Clone(appErr.Params)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata["traceId"] = traceID
+	}
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: appErr.Code, Domain: errorDomain, Metadata: metadata},
+	}
+	if len(appErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range appErr.Fields {
+			params := map[string]string{"field": i18n.FieldLabel(locale, field.Field)}
+			for k, v := range field.Params {
+				params[k] = v
+			}
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Reason:      field.Code,
+				Description: localize(locale, field.Code, params, field.Message),
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st := status.New(Code(appErr), localize(locale, appErr.Code, appErr.Params, appErr.Message))
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// Code maps the kind of err to a gRPC status code.
+func Code(err *apperror.Error) codes.Code {
+	switch err.Kind {
+	case apperror.KindValidation, apperror.KindUnsupportedMediaType, apperror.KindNotAcceptable:
+		return codes.InvalidArgument
+	case apperror.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperror.KindForbidden:
+		return codes.PermissionDenied
+	case apperror.KindNotFound:
+		return codes.NotFound
+	case apperror.KindConflict:
+		if err.Code == apperror.CodeConcurrentModification {
+			return codes.Aborted
+		}
+		return codes.AlreadyExists
+	case apperror.KindPreconditionFailed, apperror.KindUnprocessable:
+		return codes.FailedPrecondition
+	case apperror.KindTooManyRequests, apperror.KindPayloadTooLarge:
+		return codes.ResourceExhausted
+	case apperror.KindFailedDependency:
+		return codes.Aborted
+	default:
+		return codes.Internal
+	}
+}
+
+func localize(locale string, code string, params map[string]string, fallback string) string {
+	message, ok := i18n.Translate(locale, code, params)
+	if !ok {
+		return fallback
+	}
+	return message
+}
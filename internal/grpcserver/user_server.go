@@ -0,0 +1,164 @@
+package grpcserver
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/grpcserver/userpb"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"strings"
+)
+
+type userServer struct {
+	userpb.UnimplementedUserServiceServer
+	userService service.UserService
+}
+
+// NewUserServer adapts userService to the generated gRPC service interface.
+func NewUserServer(userService service.UserService) userpb.UserServiceServer {
+	return &userServer{
+		userService: userService,
+	}
+}
+
+func (s *userServer) GetMe(ctx context.Context, req *userpb.GetMeRequest) (*userpb.GetMeResponse, error) {
+	userId, ok := ctx.Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || userId == "" {
+		return nil, toStatus(ctx, errUnauthorized)
+	}
+	response, err := s.userService.GetUserByID(ctx, userId)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.GetMeResponse{
+		User: &userpb.User{
+			Id:      response.ID,
+			Name:    response.Name,
+			Email:   response.Email,
+			Locale:  response.Locale,
+			Version: response.Version,
+		},
+	}, nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	listReq := dto.UserListGetRequest{Page: int(req.GetPage()), Limit: int(req.GetLimit())}
+	if listReq.Page < 1 || listReq.Limit < 1 {
+		listReq.Page = 1
+		listReq.Limit = 20
+	}
+	response, err := s.userService.GetUserList(ctx, listReq)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	users := make([]*userpb.User, 0, len(response.Users))
+	for _, user := range response.Users {
+		users = append(users, &userpb.User{
+			Id:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+		})
+	}
+	return &userpb.ListUsersResponse{Users: users, Page: int32(response.Page)}, nil
+}
+
+func (s *userServer) RegisterUser(ctx context.Context, req *userpb.RegisterUserRequest) (*userpb.RegisterUserResponse, error) {
+	registerReq := dto.UserRegisterRequest{
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Locale:   req.GetLocale(),
+	}
+	if err := validation.Struct(registerReq); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	registerReq.Email = strings.ToLower(registerReq.Email)
+
+	response, err := s.userService.RegisterUser(ctx, registerReq)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.RegisterUserResponse{
+		User: &userpb.User{
+			Id:     response.ID,
+			Name:   response.Name,
+			Email:  response.Email,
+			Locale: registerReq.Locale,
+		},
+		AccessToken: response.AccessToken,
+	}, nil
+}
+
+func (s *userServer) LoginUser(ctx context.Context, req *userpb.LoginUserRequest) (*userpb.LoginUserResponse, error) {
+	loginReq := dto.UserLoginRequest{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+	if err := validation.Struct(loginReq); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	loginReq.Email = strings.ToLower(loginReq.Email)
+
+	response, err := s.userService.LoginUser(ctx, loginReq)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.LoginUserResponse{
+		User: &userpb.User{
+			Id:    response.ID,
+			Name:  response.Name,
+			Email: response.Email,
+		},
+		AccessToken: response.AccessToken,
+	}, nil
+}
+
+func (s *userServer) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	userId, ok := ctx.Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || userId == "" {
+		return nil, toStatus(ctx, errUnauthorized)
+	}
+	updateReq := dto.UserUpdateRequest{
+		Name:   req.GetName(),
+		Email:  req.GetEmail(),
+		Locale: req.GetLocale(),
+	}
+	if err := validation.Struct(updateReq); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	updateReq.Email = strings.ToLower(updateReq.Email)
+	if req.ExpectedVersion != nil {
+		updateReq.IfMatch = etag.FromVersion(req.GetExpectedVersion())
+	}
+
+	response, err := s.userService.UpdateUser(ctx, userId, updateReq)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.UpdateUserResponse{
+		User: &userpb.User{
+			Id:      response.ID,
+			Name:    response.Name,
+			Email:   response.Email,
+			Locale:  response.Locale,
+			Version: response.Version,
+		},
+	}, nil
+}
+
+func (s *userServer) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	userId, ok := ctx.Value(constant.CONTEXT_KEY_USER_ID).(string)
+	if !ok || userId == "" {
+		return nil, toStatus(ctx, errUnauthorized)
+	}
+	var deleteReq dto.UserDeleteRequest
+	if req.ExpectedVersion != nil {
+		deleteReq.IfMatch = etag.FromVersion(req.GetExpectedVersion())
+	}
+	if err := s.userService.DeleteUser(ctx, userId, deleteReq); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &userpb.DeleteUserResponse{}, nil
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"time"
+)
+
+// startOperation starts the span of a repository method. Call the returned
+// function deferred with the address of the named error result, it ends the
+// span and records the latency. Outcomes callers expect, like a missing document
+// or a duplicate email, do not count as errors.
+func startOperation(ctx context.Context, repository string, method string) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := tracing.StartChild(ctx, repository+"."+method)
+	return ctx, func(err *error) {
+		failed := *err != nil &&
+			!errors.Is(*err, mongo.ErrNoDocuments) &&
+			!errors.Is(*err, ErrNotFound) &&
+			!errors.Is(*err, ErrInvalidID) &&
+			!errors.Is(*err, ErrVersionConflict) &&
+			!errors.Is(*err, ErrDuplicateEmail) &&
+			!mongo.IsDuplicateKeyError(*err)
+		metrics.ObserveDBOperation(repository, method, start, failed)
+		if failed {
+			tracing.End(span, *err)
+		} else {
+			span.End()
+		}
+	}
+}
+
+type instrumentedUserRepository struct {
+	next UserRepository
+}
+
+func (r instrumentedUserRepository) GetUserById(ctx context.Context, id string) (user entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "GetUserById")
+	defer done(&err)
+	return r.next.GetUserById(ctx, id)
+}
+
+func (r instrumentedUserRepository) GetUserList(ctx context.Context, filter entity.UserFilter, offset int, limit int) (users []entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "GetUserList")
+	defer done(&err)
+	return r.next.GetUserList(ctx, filter, offset, limit)
+}
+
+func (r instrumentedUserRepository) GetUsersByIds(ctx context.Context, ids []string) (users []entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "GetUsersByIds")
+	defer done(&err)
+	return r.next.GetUsersByIds(ctx, ids)
+}
+
+func (r instrumentedUserRepository) SaveUser(ctx context.Context, user entity.User) (saved entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "SaveUser")
+	defer done(&err)
+	return r.next.SaveUser(ctx, user)
+}
+
+func (r instrumentedUserRepository) GetUserByEmail(ctx context.Context, email string) (user entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "GetUserByEmail")
+	defer done(&err)
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r instrumentedUserRepository) UpdateUser(ctx context.Context, id string, version int64, update entity.UserUpdate) (user entity.User, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "UpdateUser")
+	defer done(&err)
+	return r.next.UpdateUser(ctx, id, version, update)
+}
+
+func (r instrumentedUserRepository) DeleteUser(ctx context.Context, id string, version int64) (err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "DeleteUser")
+	defer done(&err)
+	return r.next.DeleteUser(ctx, id, version)
+}
+
+func (r instrumentedUserRepository) CountUsers(ctx context.Context) (count int64, err error) {
+	ctx, done := startOperation(ctx, "UserRepository", "CountUsers")
+	defer done(&err)
+	return r.next.CountUsers(ctx)
+}
+
+type instrumentedWebhookRepository struct {
+	next WebhookRepository
+}
+
+func (r instrumentedWebhookRepository) SaveWebhook(ctx context.Context, webhook entity.Webhook) (saved entity.Webhook, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "SaveWebhook")
+	defer done(&err)
+	return r.next.SaveWebhook(ctx, webhook)
+}
+
+func (r instrumentedWebhookRepository) GetWebhookById(ctx context.Context, id string) (webhook entity.Webhook, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "GetWebhookById")
+	defer done(&err)
+	return r.next.GetWebhookById(ctx, id)
+}
+
+func (r instrumentedWebhookRepository) GetWebhookList(ctx context.Context) (webhooks []entity.Webhook, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "GetWebhookList")
+	defer done(&err)
+	return r.next.GetWebhookList(ctx)
+}
+
+func (r instrumentedWebhookRepository) GetWebhooksByEvent(ctx context.Context, eventType string) (webhooks []entity.Webhook, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "GetWebhooksByEvent")
+	defer done(&err)
+	return r.next.GetWebhooksByEvent(ctx, eventType)
+}
+
+func (r instrumentedWebhookRepository) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "DeleteWebhook")
+	defer done(&err)
+	return r.next.DeleteWebhook(ctx, id)
+}
+
+func (r instrumentedWebhookRepository) SaveDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) (err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "SaveDeliveries")
+	defer done(&err)
+	return r.next.SaveDeliveries(ctx, deliveries)
+}
+
+func (r instrumentedWebhookRepository) GetDeliveryById(ctx context.Context, id string) (delivery entity.WebhookDelivery, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "GetDeliveryById")
+	defer done(&err)
+	return r.next.GetDeliveryById(ctx, id)
+}
+
+func (r instrumentedWebhookRepository) GetDeliveryList(ctx context.Context, filter entity.WebhookDeliveryFilter, offset int, limit int) (deliveries []entity.WebhookDelivery, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "GetDeliveryList")
+	defer done(&err)
+	return r.next.GetDeliveryList(ctx, filter, offset, limit)
+}
+
+func (r instrumentedWebhookRepository) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (delivery entity.WebhookDelivery, err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "ClaimDueDelivery")
+	defer done(&err)
+	return r.next.ClaimDueDelivery(ctx, now, lease)
+}
+
+func (r instrumentedWebhookRepository) UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) (err error) {
+	ctx, done := startOperation(ctx, "WebhookRepository", "UpdateDelivery")
+	defer done(&err)
+	return r.next.UpdateDelivery(ctx, delivery)
+}
+
+type instrumentedOutboxRepository struct {
+	next OutboxRepository
+}
+
+func (r instrumentedOutboxRepository) SaveEvent(ctx context.Context, event entity.OutboxEvent) (err error) {
+	ctx, done := startOperation(ctx, "OutboxRepository", "SaveEvent")
+	defer done(&err)
+	return r.next.SaveEvent(ctx, event)
+}
+
+func (r instrumentedOutboxRepository) GetPendingEvents(ctx context.Context, now time.Time, limit int) (events []entity.OutboxEvent, err error) {
+	ctx, done := startOperation(ctx, "OutboxRepository", "GetPendingEvents")
+	defer done(&err)
+	return r.next.GetPendingEvents(ctx, now, limit)
+}
+
+func (r instrumentedOutboxRepository) DeleteEvents(ctx context.Context, ids []string) (err error) {
+	ctx, done := startOperation(ctx, "OutboxRepository", "DeleteEvents")
+	defer done(&err)
+	return r.next.DeleteEvents(ctx, ids)
+}
+
+func (r instrumentedOutboxRepository) RecordFailure(ctx context.Context, id string, message string, nextAttemptAt time.Time) (err error) {
+	ctx, done := startOperation(ctx, "OutboxRepository", "RecordFailure")
+	defer done(&err)
+	return r.next.RecordFailure(ctx, id, message, nextAttemptAt)
+}
+
+func (r instrumentedOutboxRepository) MarkDead(ctx context.Context, id string, message string) (err error) {
+	ctx, done := startOperation(ctx, "OutboxRepository", "MarkDead")
+	defer done(&err)
+	return r.next.MarkDead(ctx, id, message)
+}
+
+type instrumentedLockRepository struct {
+	next LockRepository
+}
+
+func (r instrumentedLockRepository) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (acquired bool, err error) {
+	ctx, done := startOperation(ctx, "LockRepository", "AcquireLease")
+	defer done(&err)
+	return r.next.AcquireLease(ctx, name, owner, ttl)
+}
+
+func (r instrumentedLockRepository) ReleaseLease(ctx context.Context, name string, owner string) (err error) {
+	ctx, done := startOperation(ctx, "LockRepository", "ReleaseLease")
+	defer done(&err)
+	return r.next.ReleaseLease(ctx, name, owner)
+}
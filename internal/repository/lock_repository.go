@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+// LockRepository hands out named leases so a job runs on one replica at a time.
+type LockRepository interface {
+	// AcquireLease takes or renews the lease name for owner until ttl from now.
+	// It returns false while another owner holds an unexpired lease.
+	AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+	// ReleaseLease ends the lease name when owner holds it, so another owner
+	// does not wait for it to expire.
+	ReleaseLease(ctx context.Context, name string, owner string) error
+}
+
+type lockRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewLockRepository(collection *mongo.Collection) LockRepository {
+	return &lockRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *lockRepositoryImpl) AcquireLease(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"owner": owner},
+			bson.M{"expires_at": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(ttl)}}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		// the upsert collides with the lease of another owner
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		log.DebugContext(ctx, "error acquiring lease", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *lockRepositoryImpl) ReleaseLease(ctx context.Context, name string, owner string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": name, "owner": owner})
+	if err != nil {
+		log.DebugContext(ctx, "error releasing lease", "error", err)
+		return err
+	}
+	return nil
+}
@@ -38,17 +38,71 @@ func (_m *UserRepository) EXPECT() *UserRepository_Expecter {
 	return &UserRepository_Expecter{mock: &_m.Mock}
 }
 
+// CountUsers provides a mock function for the type UserRepository
+func (_mock *UserRepository) CountUsers(ctx context.Context) (int64, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserRepository_CountUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountUsers'
+type UserRepository_CountUsers_Call struct {
+	*mock.Call
+}
+
+// CountUsers is a helper method to define mock.On call
+//   - ctx
+func (_e *UserRepository_Expecter) CountUsers(ctx interface{}) *UserRepository_CountUsers_Call {
+	return &UserRepository_CountUsers_Call{Call: _e.mock.On("CountUsers", ctx)}
+}
+
+func (_c *UserRepository_CountUsers_Call) Run(run func(ctx context.Context)) *UserRepository_CountUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *UserRepository_CountUsers_Call) Return(n int64, err error) *UserRepository_CountUsers_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *UserRepository_CountUsers_Call) RunAndReturn(run func(ctx context.Context) (int64, error)) *UserRepository_CountUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteUser provides a mock function for the type UserRepository
-func (_mock *UserRepository) DeleteUser(ctx context.Context, id string) error {
-	ret := _mock.Called(ctx, id)
+func (_mock *UserRepository) DeleteUser(ctx context.Context, id string, version int64) error {
+	ret := _mock.Called(ctx, id, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUser")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = returnFunc(ctx, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -63,13 +117,14 @@ type UserRepository_DeleteUser_Call struct {
 // DeleteUser is a helper method to define mock.On call
 //   - ctx
 //   - id
-func (_e *UserRepository_Expecter) DeleteUser(ctx interface{}, id interface{}) *UserRepository_DeleteUser_Call {
-	return &UserRepository_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id)}
+//   - version
+func (_e *UserRepository_Expecter) DeleteUser(ctx interface{}, id interface{}, version interface{}) *UserRepository_DeleteUser_Call {
+	return &UserRepository_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id, version)}
 }
 
-func (_c *UserRepository_DeleteUser_Call) Run(run func(ctx context.Context, id string)) *UserRepository_DeleteUser_Call {
+func (_c *UserRepository_DeleteUser_Call) Run(run func(ctx context.Context, id string, version int64)) *UserRepository_DeleteUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(int64))
 	})
 	return _c
 }
@@ -79,7 +134,7 @@ func (_c *UserRepository_DeleteUser_Call) Return(err error) *UserRepository_Dele
 	return _c
 }
 
-func (_c *UserRepository_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id string) error) *UserRepository_DeleteUser_Call {
+func (_c *UserRepository_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id string, version int64) error) *UserRepository_DeleteUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -195,8 +250,8 @@ func (_c *UserRepository_GetUserById_Call) RunAndReturn(run func(ctx context.Con
 }
 
 // GetUserList provides a mock function for the type UserRepository
-func (_mock *UserRepository) GetUserList(ctx context.Context, offset int, limit int) ([]entity.User, error) {
-	ret := _mock.Called(ctx, offset, limit)
+func (_mock *UserRepository) GetUserList(ctx context.Context, filter entity.UserFilter, offset int, limit int) ([]entity.User, error) {
+	ret := _mock.Called(ctx, filter, offset, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserList")
@@ -204,18 +259,18 @@ func (_mock *UserRepository) GetUserList(ctx context.Context, offset int, limit
 
 	var r0 []entity.User
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) ([]entity.User, error)); ok {
-		return returnFunc(ctx, offset, limit)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entity.UserFilter, int, int) ([]entity.User, error)); ok {
+		return returnFunc(ctx, filter, offset, limit)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) []entity.User); ok {
-		r0 = returnFunc(ctx, offset, limit)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entity.UserFilter, int, int) []entity.User); ok {
+		r0 = returnFunc(ctx, filter, offset, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.User)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = returnFunc(ctx, offset, limit)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entity.UserFilter, int, int) error); ok {
+		r1 = returnFunc(ctx, filter, offset, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -229,15 +284,16 @@ type UserRepository_GetUserList_Call struct {
 
 // GetUserList is a helper method to define mock.On call
 //   - ctx
+//   - filter
 //   - offset
 //   - limit
-func (_e *UserRepository_Expecter) GetUserList(ctx interface{}, offset interface{}, limit interface{}) *UserRepository_GetUserList_Call {
-	return &UserRepository_GetUserList_Call{Call: _e.mock.On("GetUserList", ctx, offset, limit)}
+func (_e *UserRepository_Expecter) GetUserList(ctx interface{}, filter interface{}, offset interface{}, limit interface{}) *UserRepository_GetUserList_Call {
+	return &UserRepository_GetUserList_Call{Call: _e.mock.On("GetUserList", ctx, filter, offset, limit)}
 }
 
-func (_c *UserRepository_GetUserList_Call) Run(run func(ctx context.Context, offset int, limit int)) *UserRepository_GetUserList_Call {
+func (_c *UserRepository_GetUserList_Call) Run(run func(ctx context.Context, filter entity.UserFilter, offset int, limit int)) *UserRepository_GetUserList_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(entity.UserFilter), args[2].(int), args[3].(int))
 	})
 	return _c
 }
@@ -247,7 +303,64 @@ func (_c *UserRepository_GetUserList_Call) Return(users []entity.User, err error
 	return _c
 }
 
-func (_c *UserRepository_GetUserList_Call) RunAndReturn(run func(ctx context.Context, offset int, limit int) ([]entity.User, error)) *UserRepository_GetUserList_Call {
+func (_c *UserRepository_GetUserList_Call) RunAndReturn(run func(ctx context.Context, filter entity.UserFilter, offset int, limit int) ([]entity.User, error)) *UserRepository_GetUserList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUsersByIds provides a mock function for the type UserRepository
+func (_mock *UserRepository) GetUsersByIds(ctx context.Context, ids []string) ([]entity.User, error) {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIds")
+	}
+
+	var r0 []entity.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]entity.User, error)); ok {
+		return returnFunc(ctx, ids)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []entity.User); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserRepository_GetUsersByIds_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsersByIds'
+type UserRepository_GetUsersByIds_Call struct {
+	*mock.Call
+}
+
+// GetUsersByIds is a helper method to define mock.On call
+//   - ctx
+//   - ids
+func (_e *UserRepository_Expecter) GetUsersByIds(ctx interface{}, ids interface{}) *UserRepository_GetUsersByIds_Call {
+	return &UserRepository_GetUsersByIds_Call{Call: _e.mock.On("GetUsersByIds", ctx, ids)}
+}
+
+func (_c *UserRepository_GetUsersByIds_Call) Run(run func(ctx context.Context, ids []string)) *UserRepository_GetUsersByIds_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_GetUsersByIds_Call) Return(users []entity.User, err error) *UserRepository_GetUsersByIds_Call {
+	_c.Call.Return(users, err)
+	return _c
+}
+
+func (_c *UserRepository_GetUsersByIds_Call) RunAndReturn(run func(ctx context.Context, ids []string) ([]entity.User, error)) *UserRepository_GetUsersByIds_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -308,8 +421,8 @@ func (_c *UserRepository_SaveUser_Call) RunAndReturn(run func(ctx context.Contex
 }
 
 // UpdateUser provides a mock function for the type UserRepository
-func (_mock *UserRepository) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
-	ret := _mock.Called(ctx, user)
+func (_mock *UserRepository) UpdateUser(ctx context.Context, id string, version int64, update entity.UserUpdate) (entity.User, error) {
+	ret := _mock.Called(ctx, id, version, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateUser")
@@ -317,16 +430,16 @@ func (_mock *UserRepository) UpdateUser(ctx context.Context, user entity.User) (
 
 	var r0 entity.User
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, entity.User) (entity.User, error)); ok {
-		return returnFunc(ctx, user)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, entity.UserUpdate) (entity.User, error)); ok {
+		return returnFunc(ctx, id, version, update)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, entity.User) entity.User); ok {
-		r0 = returnFunc(ctx, user)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, entity.UserUpdate) entity.User); ok {
+		r0 = returnFunc(ctx, id, version, update)
 	} else {
 		r0 = ret.Get(0).(entity.User)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, entity.User) error); ok {
-		r1 = returnFunc(ctx, user)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int64, entity.UserUpdate) error); ok {
+		r1 = returnFunc(ctx, id, version, update)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -340,24 +453,26 @@ type UserRepository_UpdateUser_Call struct {
 
 // UpdateUser is a helper method to define mock.On call
 //   - ctx
-//   - user
-func (_e *UserRepository_Expecter) UpdateUser(ctx interface{}, user interface{}) *UserRepository_UpdateUser_Call {
-	return &UserRepository_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, user)}
+//   - id
+//   - version
+//   - update
+func (_e *UserRepository_Expecter) UpdateUser(ctx interface{}, id interface{}, version interface{}, update interface{}) *UserRepository_UpdateUser_Call {
+	return &UserRepository_UpdateUser_Call{Call: _e.mock.On("UpdateUser", ctx, id, version, update)}
 }
 
-func (_c *UserRepository_UpdateUser_Call) Run(run func(ctx context.Context, user entity.User)) *UserRepository_UpdateUser_Call {
+func (_c *UserRepository_UpdateUser_Call) Run(run func(ctx context.Context, id string, version int64, update entity.UserUpdate)) *UserRepository_UpdateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(entity.User))
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(entity.UserUpdate))
 	})
 	return _c
 }
 
-func (_c *UserRepository_UpdateUser_Call) Return(user1 entity.User, err error) *UserRepository_UpdateUser_Call {
-	_c.Call.Return(user1, err)
+func (_c *UserRepository_UpdateUser_Call) Return(user entity.User, err error) *UserRepository_UpdateUser_Call {
+	_c.Call.Return(user, err)
 	return _c
 }
 
-func (_c *UserRepository_UpdateUser_Call) RunAndReturn(run func(ctx context.Context, user entity.User) (entity.User, error)) *UserRepository_UpdateUser_Call {
+func (_c *UserRepository_UpdateUser_Call) RunAndReturn(run func(ctx context.Context, id string, version int64, update entity.UserUpdate) (entity.User, error)) *UserRepository_UpdateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type OutboxRepository interface {
+	SaveEvent(ctx context.Context, event entity.OutboxEvent) error
+	// GetPendingEvents returns the oldest limit events that are due at now.
+	// Events of a user with an event waiting for its next attempt after now are
+	// left out, they are published in order once that one is.
+	GetPendingEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error)
+	DeleteEvents(ctx context.Context, ids []string) error
+	RecordFailure(ctx context.Context, id string, message string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id string, message string) error
+}
+
+type outboxRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxRepository(collection *mongo.Collection) OutboxRepository {
+	return &outboxRepositoryImpl{
+		collection: collection,
+	}
+}
+
+// SaveEvent inserts event, call it with the ctx of the transaction that saves the change.
+func (r *outboxRepositoryImpl) SaveEvent(ctx context.Context, event entity.OutboxEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		log.DebugContext(ctx, "error saving outbox event", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *outboxRepositoryImpl) GetPendingEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error) {
+	// the users whose next event waits, their later events wait behind it
+	waitingAggregates := []string{}
+	err := r.collection.Distinct(ctx, "aggregate_id", bson.M{
+		"status":          bson.M{"$ne": entity.OutboxStatusDead},
+		"next_attempt_at": bson.M{"$gt": now},
+	}).Decode(&waitingAggregates)
+	if err != nil {
+		log.DebugContext(ctx, "error finding waiting outbox events", "error", err)
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	filter := bson.M{
+		"status":       bson.M{"$ne": entity.OutboxStatusDead},
+		"aggregate_id": bson.M{"$nin": waitingAggregates},
+	}
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		log.DebugContext(ctx, "error finding outbox events", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []entity.OutboxEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		log.DebugContext(ctx, "error decoding outbox events", "error", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *outboxRepositoryImpl) DeleteEvents(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		log.DebugContext(ctx, "error deleting outbox events", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *outboxRepositoryImpl) RecordFailure(ctx context.Context, id string, message string, nextAttemptAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"last_error": message, "next_attempt_at": nextAttemptAt},
+	})
+	if err != nil {
+		log.DebugContext(ctx, "error recording outbox failure", "error", err)
+		return err
+	}
+	return nil
+}
+
+// MarkDead records the last failed publish of the event with id and stops publishing it.
+func (r *outboxRepositoryImpl) MarkDead(ctx context.Context, id string, message string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"last_error": message, "status": entity.OutboxStatusDead},
+	})
+	if err != nil {
+		log.DebugContext(ctx, "error marking outbox event dead", "error", err)
+		return err
+	}
+	return nil
+}
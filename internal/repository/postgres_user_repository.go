@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+)
+
+// uniqueViolation is the SQLSTATE of a duplicate key in PostgreSQL.
+const uniqueViolation = "23505"
+
+// NewPostgresUserRepository returns a UserRepository on the users table of a
+// PostgreSQL database, created by the postgres SQL migrations.
+func NewPostgresUserRepository(database *sql.DB) UserRepository {
+	return &sqlUserRepository{
+		database:  database,
+		backend:   config.UserStorePostgres,
+		nameMatch: "ILIKE",
+		isUniqueViolation: func(err error) bool {
+			var pgErr *pgconn.PgError
+			return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+		},
+	}
+}
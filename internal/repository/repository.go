@@ -1,16 +1,41 @@
 package repository
 
 import (
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
 	"github.com/taninchot-work/backend-challenge/internal/core/db"
 )
 
 type Repository struct {
-	UserRepository UserRepository
+	UserRepository    UserRepository
+	WebhookRepository WebhookRepository
+	OutboxRepository  OutboxRepository
+	LockRepository    LockRepository
+	Transactor        Transactor
 }
 
+// NewRepository returns the MongoDB repositories, and the user and outbox
+// repositories of the configured user store, instrumented with the operation
+// metrics and spans. A SQL user store keeps the outbox in its database, so a
+// user and its event commit in one transaction.
 func NewRepository() *Repository {
 	mongoDatabase := db.GetDatabase()
-	return &Repository{
-		UserRepository: NewUserRepository(mongoDatabase.Collection("users")),
+	repositories := &Repository{
+		UserRepository:    instrumentedUserRepository{NewUserRepository(mongoDatabase.Collection("users"))},
+		WebhookRepository: instrumentedWebhookRepository{NewWebhookRepository(mongoDatabase.Collection("webhooks"), mongoDatabase.Collection("webhook_deliveries"))},
+		OutboxRepository:  instrumentedOutboxRepository{NewOutboxRepository(mongoDatabase.Collection("outbox"))},
+		LockRepository:    instrumentedLockRepository{NewLockRepository(mongoDatabase.Collection("locks"))},
+		Transactor:        NewMongoTransactor(db.GetClient()),
 	}
+	backend := config.GetConfig().UserStore.Backend
+	switch backend {
+	case config.UserStorePostgres:
+		repositories.UserRepository = instrumentedUserRepository{NewPostgresUserRepository(db.GetSQL())}
+	case config.UserStoreSQLite:
+		repositories.UserRepository = instrumentedUserRepository{NewSQLiteUserRepository(db.GetSQL())}
+	default:
+		return repositories
+	}
+	repositories.OutboxRepository = instrumentedOutboxRepository{NewSQLOutboxRepository(db.GetSQL(), backend)}
+	repositories.Transactor = NewSQLTransactor(db.GetSQL())
+	return repositories
 }
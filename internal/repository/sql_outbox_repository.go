@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"strings"
+	"time"
+)
+
+const outboxColumns = "id, type, occurred_at, data, aggregate_id, sequence, created_at, status, attempts, last_error, next_attempt_at"
+
+// sqlOutboxRepository keeps the outbox in the outbox table of the SQL user
+// store, so a user and its event commit in one transaction of the database.
+type sqlOutboxRepository struct {
+	database *sql.DB
+	backend  string
+}
+
+// NewSQLOutboxRepository returns an OutboxRepository on the outbox table of
+// database, created by the SQL migrations of backend, postgres or sqlite.
+func NewSQLOutboxRepository(database *sql.DB, backend string) OutboxRepository {
+	return &sqlOutboxRepository{
+		database: database,
+		backend:  backend,
+	}
+}
+
+// SaveEvent inserts event, call it with the ctx of the transaction that saves the change.
+func (r *sqlOutboxRepository) SaveEvent(ctx context.Context, event entity.OutboxEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = sqlQuerierOf(ctx, r.database).ExecContext(ctx, r.rebind("INSERT INTO outbox ("+outboxColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		event.ID, event.Type, event.OccurredAt.UTC(), string(data), event.AggregateID, event.Sequence,
+		event.CreatedAt.UTC(), event.Status, event.Attempts, event.LastError, nullTime(event.NextAttemptAt))
+	if err != nil {
+		log.DebugContext(ctx, "error saving outbox event", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *sqlOutboxRepository) GetPendingEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error) {
+	rows, err := sqlQuerierOf(ctx, r.database).QueryContext(ctx, r.rebind("SELECT "+outboxColumns+" FROM outbox"+
+		" WHERE status <> ? AND aggregate_id NOT IN (SELECT aggregate_id FROM outbox WHERE status <> ? AND next_attempt_at > ?)"+
+		" ORDER BY created_at, id LIMIT ?"),
+		entity.OutboxStatusDead, entity.OutboxStatusDead, now.UTC(), limit)
+	if err != nil {
+		log.DebugContext(ctx, "error finding outbox events", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []entity.OutboxEvent{}
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			log.DebugContext(ctx, "error decoding outbox events", "error", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *sqlOutboxRepository) DeleteEvents(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	_, err := sqlQuerierOf(ctx, r.database).ExecContext(ctx, r.rebind("DELETE FROM outbox WHERE id IN ("+placeholders+")"), args...)
+	if err != nil {
+		log.DebugContext(ctx, "error deleting outbox events", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *sqlOutboxRepository) RecordFailure(ctx context.Context, id string, message string, nextAttemptAt time.Time) error {
+	_, err := sqlQuerierOf(ctx, r.database).ExecContext(ctx,
+		r.rebind("UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?"),
+		message, nullTime(nextAttemptAt), id)
+	if err != nil {
+		log.DebugContext(ctx, "error recording outbox failure", "error", err)
+		return err
+	}
+	return nil
+}
+
+// MarkDead records the last failed publish of the event with id and stops publishing it.
+func (r *sqlOutboxRepository) MarkDead(ctx context.Context, id string, message string) error {
+	_, err := sqlQuerierOf(ctx, r.database).ExecContext(ctx,
+		r.rebind("UPDATE outbox SET attempts = attempts + 1, last_error = ?, status = ? WHERE id = ?"),
+		message, entity.OutboxStatusDead, id)
+	if err != nil {
+		log.DebugContext(ctx, "error marking outbox event dead", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *sqlOutboxRepository) rebind(query string) string {
+	return db.Rebind(r.backend, query)
+}
+
+// scanOutboxEvent reads the outboxColumns of row.
+func scanOutboxEvent(row interface{ Scan(dest ...any) error }) (entity.OutboxEvent, error) {
+	var event entity.OutboxEvent
+	var data string
+	var nextAttemptAt sql.NullTime
+	err := row.Scan(&event.ID, &event.Type, &event.OccurredAt, &data, &event.AggregateID, &event.Sequence,
+		&event.CreatedAt, &event.Status, &event.Attempts, &event.LastError, &nextAttemptAt)
+	if err != nil {
+		return entity.OutboxEvent{}, err
+	}
+	if err := json.Unmarshal([]byte(data), &event.Data); err != nil {
+		return entity.OutboxEvent{}, err
+	}
+	event.AggregateID = strings.TrimSpace(event.AggregateID)
+	event.OccurredAt = event.OccurredAt.UTC()
+	event.CreatedAt = event.CreatedAt.UTC()
+	if nextAttemptAt.Valid {
+		event.NextAttemptAt = nextAttemptAt.Time.UTC()
+	}
+	return event, nil
+}
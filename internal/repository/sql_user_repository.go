@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"strings"
+	"time"
+)
+
+const userColumns = "id, name, email, password, locale, role, created_at, updated_at, version, sessions_revoked_at"
+
+// sqlQuerier runs queries on the database or in a transaction.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqlUserRepository keeps the users in the users table of a SQL database. The
+// ids are the hex of ObjectIDs, like in MongoDB, so users move between the
+// backends with export and import.
+type sqlUserRepository struct {
+	database *sql.DB
+	backend  string
+	// nameMatch is the case-insensitive LIKE of the backend
+	nameMatch string
+	// isUniqueViolation tells whether err is the violation of a unique index
+	isUniqueViolation func(err error) bool
+}
+
+func (r *sqlUserRepository) GetUserById(ctx context.Context, id string) (entity.User, error) {
+	if _, err := bson.ObjectIDFromHex(id); err != nil {
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return entity.User{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+	user, err := scanUser(r.querier(ctx).QueryRowContext(ctx, r.rebind("SELECT "+userColumns+" FROM users WHERE id = ?"), id))
+	if err != nil {
+		log.DebugContext(ctx, "error finding user by ID", "error", err)
+		return entity.User{}, r.error(err)
+	}
+	return user, nil
+}
+
+func (r *sqlUserRepository) GetUserList(ctx context.Context, userFilter entity.UserFilter, offset int, limit int) ([]entity.User, error) {
+	var conditions []string
+	var args []any
+	if userFilter.Name != "" {
+		conditions = append(conditions, "name "+r.nameMatch+" ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(userFilter.Name)+"%")
+	}
+	if userFilter.Email != "" {
+		conditions = append(conditions, "email = ?")
+		args = append(args, userFilter.Email)
+	}
+	query := "SELECT " + userColumns + " FROM users"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// ids sort like ObjectIDs, the order of MongoDB
+	query += " ORDER BY id LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	users, err := r.queryUsers(ctx, query, args...)
+	if err != nil {
+		log.DebugContext(ctx, "error finding users", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByIds returns the users with the given ids in one query. Unknown and
+// malformed ids are skipped, so the result may be shorter than ids.
+func (r *sqlUserRepository) GetUsersByIds(ctx context.Context, ids []string) ([]entity.User, error) {
+	var args []any
+	for _, id := range ids {
+		if _, err := bson.ObjectIDFromHex(id); err == nil {
+			args = append(args, id)
+		}
+	}
+	if len(args) == 0 {
+		return []entity.User{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	users, err := r.queryUsers(ctx, "SELECT "+userColumns+" FROM users WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		log.DebugContext(ctx, "error finding users by IDs", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *sqlUserRepository) SaveUser(ctx context.Context, user entity.User) (entity.User, error) {
+	if user.ID.IsZero() {
+		user.ID = bson.NewObjectID()
+	}
+	_, err := r.querier(ctx).ExecContext(ctx, r.rebind("INSERT INTO users ("+userColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		user.ID.Hex(), user.Name, user.Email, user.Password, user.Locale, user.Role,
+		user.CreatedAt.UTC(), user.UpdatedAt.UTC(), user.Version, nullTime(user.SessionsRevokedAt))
+	if err != nil {
+		log.DebugContext(ctx, "error creating user", "error", err)
+		return entity.User{}, r.error(err)
+	}
+	return user, nil
+}
+
+func (r *sqlUserRepository) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	user, err := scanUser(r.querier(ctx).QueryRowContext(ctx, r.rebind("SELECT "+userColumns+" FROM users WHERE email = ?"), email))
+	if err != nil {
+		log.DebugContext(ctx, "error finding user by email", "error", err)
+		return entity.User{}, r.error(err)
+	}
+	return user, nil
+}
+
+func (r *sqlUserRepository) UpdateUser(ctx context.Context, id string, version int64, update entity.UserUpdate) (entity.User, error) {
+	if _, err := bson.ObjectIDFromHex(id); err != nil {
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return entity.User{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	// only set the fields that were changed
+	assignments := []string{"updated_at = ?", "version = version + 1"}
+	args := []any{time.Now().UTC()}
+	if update.Name != nil {
+		assignments = append(assignments, "name = ?")
+		args = append(args, *update.Name)
+	}
+	if update.Email != nil {
+		assignments = append(assignments, "email = ?")
+		args = append(args, *update.Email)
+	}
+	if update.Password != nil {
+		assignments = append(assignments, "password = ?")
+		args = append(args, *update.Password)
+	}
+	if update.Locale != nil {
+		assignments = append(assignments, "locale = ?")
+		args = append(args, *update.Locale)
+	}
+	if update.SessionsRevokedAt != nil {
+		assignments = append(assignments, "sessions_revoked_at = ?")
+		args = append(args, nullTime(*update.SessionsRevokedAt))
+	}
+
+	// the version in the condition makes the update fail if someone else changed the user in between
+	query := "UPDATE users SET " + strings.Join(assignments, ", ") + " WHERE id = ? AND version = ? RETURNING " + userColumns
+	args = append(args, id, version)
+	user, err := scanUser(r.querier(ctx).QueryRowContext(ctx, r.rebind(query), args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.User{}, r.versionConflictOrNotFound(ctx, id)
+		}
+		log.DebugContext(ctx, "error updating user", "error", err)
+		return entity.User{}, r.error(err)
+	}
+	return user, nil
+}
+
+func (r *sqlUserRepository) DeleteUser(ctx context.Context, id string, version int64) error {
+	if _, err := bson.ObjectIDFromHex(id); err != nil {
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	result, err := r.querier(ctx).ExecContext(ctx, r.rebind("DELETE FROM users WHERE id = ? AND version = ?"), id, version)
+	if err != nil {
+		log.DebugContext(ctx, "error deleting user", "error", err)
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return r.versionConflictOrNotFound(ctx, id)
+	}
+	return nil
+}
+
+func (r *sqlUserRepository) CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.querier(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		log.DebugContext(ctx, "error counting users", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// versionConflictOrNotFound tells apart a missing user from a stale version after a write matched nothing.
+func (r *sqlUserRepository) versionConflictOrNotFound(ctx context.Context, id string) error {
+	var count int64
+	if err := r.querier(ctx).QueryRowContext(ctx, r.rebind("SELECT COUNT(*) FROM users WHERE id = ?"), id).Scan(&count); err != nil {
+		log.DebugContext(ctx, "error checking user existence", "error", err)
+		return err
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return ErrNotFound
+}
+
+// queryUsers returns the users of query.
+func (r *sqlUserRepository) queryUsers(ctx context.Context, query string, args ...any) ([]entity.User, error) {
+	rows, err := r.querier(ctx).QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := []entity.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// querier returns the transaction of ctx, or the database outside of one.
+func (r *sqlUserRepository) querier(ctx context.Context) sqlQuerier {
+	return sqlQuerierOf(ctx, r.database)
+}
+
+// sqlQuerierOf returns the transaction of ctx, or database outside of one.
+func sqlQuerierOf(ctx context.Context, database *sql.DB) sqlQuerier {
+	if tx := sqlTx(ctx); tx != nil {
+		return tx
+	}
+	return database
+}
+
+func (r *sqlUserRepository) rebind(query string) string {
+	return db.Rebind(r.backend, query)
+}
+
+// error returns the repository error of err, like ErrNotFound for
+// sql.ErrNoRows, or err.
+func (r *sqlUserRepository) error(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case r.isUniqueViolation(err):
+		return fmt.Errorf("%w: %v", ErrDuplicateEmail, err)
+	}
+	return err
+}
+
+// scanUser reads the userColumns of row.
+func scanUser(row interface{ Scan(dest ...any) error }) (entity.User, error) {
+	var user entity.User
+	var id string
+	var sessionsRevokedAt sql.NullTime
+	err := row.Scan(&id, &user.Name, &user.Email, &user.Password, &user.Locale, &user.Role,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version, &sessionsRevokedAt)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if user.ID, err = bson.ObjectIDFromHex(strings.TrimSpace(id)); err != nil {
+		return entity.User{}, fmt.Errorf("user %q has an invalid id: %w", id, err)
+	}
+	user.CreatedAt = user.CreatedAt.UTC()
+	user.UpdatedAt = user.UpdatedAt.UTC()
+	if sessionsRevokedAt.Valid {
+		user.SessionsRevokedAt = sessionsRevokedAt.Time.UTC()
+	}
+	return user, nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern with a backslash.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/mattn/go-sqlite3"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+)
+
+// NewSQLiteUserRepository returns a UserRepository on the users table of a
+// SQLite database, created by the sqlite SQL migrations. Names match ignoring
+// the case of ASCII letters only.
+func NewSQLiteUserRepository(database *sql.DB) UserRepository {
+	return &sqlUserRepository{
+		database:  database,
+		backend:   config.UserStoreSQLite,
+		nameMatch: "LIKE",
+		isUniqueViolation: func(err error) bool {
+			var sqliteErr sqlite3.Error
+			return errors.As(err, &sqliteErr) &&
+				(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+		},
+	}
+}
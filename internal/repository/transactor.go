@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// transientTransactionError is the label of a MongoDB error that aborted the
+// whole transaction, like a write conflict, and goes away when it is retried.
+const transientTransactionError = "TransientTransactionError"
+
+// Transactor runs fn in a transaction. Repository calls made with the ctx
+// passed to fn are part of it, the transaction commits when fn returns nil and
+// is aborted otherwise. fn may be retried on transient errors. Called within a
+// transaction, fn joins it.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// IsTransient reports whether err aborted a transaction that WithTransaction
+// retries when fn returns it. fn must return such an error as it is, wrapped or
+// not, instead of turning it into its own failure.
+func IsTransient(err error) bool {
+	var labeled mongo.LabeledError
+	return errors.As(err, &labeled) && labeled.HasErrorLabel(transientTransactionError)
+}
+
+type mongoTransactor struct {
+	client *mongo.Client
+}
+
+// NewMongoTransactor returns a Transactor using sessions of client. MongoDB only
+// supports transactions on replica sets and sharded clusters.
+func NewMongoTransactor(client *mongo.Client) Transactor {
+	return &mongoTransactor{
+		client: client,
+	}
+}
+
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if session := mongo.SessionFromContext(ctx); session != nil && session.ClientSession().TransactionRunning() {
+		return fn(ctx)
+	}
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+// sqlTxKey is the context key of the transaction of a sqlTransactor.
+type sqlTxKey struct{}
+
+type sqlTransactor struct {
+	database *sql.DB
+}
+
+// NewSQLTransactor returns a Transactor using transactions of database, the
+// SQL user and outbox repositories join them.
+func NewSQLTransactor(database *sql.DB) Transactor {
+	return &sqlTransactor{
+		database: database,
+	}
+}
+
+func (t *sqlTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if sqlTx(ctx) != nil {
+		return fn(ctx)
+	}
+	tx, err := t.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(context.WithValue(ctx, sqlTxKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqlTx returns the transaction of ctx, nil outside of one.
+func sqlTx(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(sqlTxKey{}).(*sql.Tx)
+	return tx
+}
@@ -2,24 +2,43 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
-	"log"
+	"regexp"
 	"time"
 )
 
+// UserRepository stores the users. Every implementation returns ErrNotFound for
+// a missing user, ErrDuplicateEmail when the email is taken, ErrVersionConflict
+// for a stale version and ErrInvalidID for an id that is not an ObjectID.
 type UserRepository interface {
 	GetUserById(ctx context.Context, id string) (entity.User, error)
-	GetUserList(ctx context.Context, offset int, limit int) ([]entity.User, error)
+	GetUserList(ctx context.Context, filter entity.UserFilter, offset int, limit int) ([]entity.User, error)
+	GetUsersByIds(ctx context.Context, ids []string) ([]entity.User, error)
 	SaveUser(ctx context.Context, user entity.User) (entity.User, error)
 	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
-	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
-	DeleteUser(ctx context.Context, id string) error
+	UpdateUser(ctx context.Context, id string, version int64, update entity.UserUpdate) (entity.User, error)
+	DeleteUser(ctx context.Context, id string, version int64) error
+	CountUsers(ctx context.Context) (int64, error)
 }
 
+var (
+	// ErrNotFound is returned when there is no user, webhook or delivery with
+	// the id, or no user with the email.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicateEmail is returned when another user has the email. SaveUser
+	// also returns it for a taken id, which only an import can cause.
+	ErrDuplicateEmail = errors.New("email already exists")
+	// ErrVersionConflict is returned when a user was changed since the given version was read.
+	ErrVersionConflict = errors.New("user version conflict")
+	// ErrInvalidID is returned when an id is not a valid user id.
+	ErrInvalidID = errors.New("invalid user ID format")
+)
+
 type userRepositoryImpl struct {
 	mongoCollection *mongo.Collection
 }
@@ -34,36 +53,73 @@ func (r *userRepositoryImpl) GetUserById(ctx context.Context, id string) (entity
 	var user entity.User
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("Error converting ID string '%s' to ObjectID: %v", id, err)
-		return entity.User{}, fmt.Errorf("invalid user ID format: %w", err)
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return entity.User{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
 	}
 
 	filter := bson.M{"_id": objectID}
 
 	err = r.mongoCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		log.Println("Error finding user by ID:", err)
-		return entity.User{}, err
+		log.DebugContext(ctx, "error finding user by ID", "error", err)
+		return entity.User{}, mongoError(err)
 	}
 	return user, nil
 }
 
-func (r *userRepositoryImpl) GetUserList(ctx context.Context, offset int, limit int) ([]entity.User, error) {
+func (r *userRepositoryImpl) GetUserList(ctx context.Context, userFilter entity.UserFilter, offset int, limit int) ([]entity.User, error) {
 	var users []entity.User
 	filter := bson.M{}
+	if userFilter.Name != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(userFilter.Name), "$options": "i"}
+	}
+	if userFilter.Email != "" {
+		filter["email"] = userFilter.Email
+	}
 	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "_id", Value: 1}}) // stable order for paging
 	findOptions.SetSkip(int64(offset))
 	findOptions.SetLimit(int64(limit))
 
 	cursor, err := r.mongoCollection.Find(ctx, filter, findOptions)
 	if err != nil {
-		log.Println("Error finding users:", err)
+		log.DebugContext(ctx, "error finding users", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &users); err != nil {
+		log.DebugContext(ctx, "error decoding users", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByIds returns the users with the given ids in one query. Unknown and
+// malformed ids are skipped, so the result may be shorter than ids.
+func (r *userRepositoryImpl) GetUsersByIds(ctx context.Context, ids []string) ([]entity.User, error) {
+	objectIDs := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	users := []entity.User{}
+	if len(objectIDs) == 0 {
+		return users, nil
+	}
+
+	cursor, err := r.mongoCollection.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		log.DebugContext(ctx, "error finding users by IDs", "error", err)
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	if err = cursor.All(ctx, &users); err != nil {
-		log.Println("Error decoding users:", err)
+		log.DebugContext(ctx, "error decoding users", "error", err)
 		return nil, err
 	}
 	return users, nil
@@ -72,8 +128,8 @@ func (r *userRepositoryImpl) GetUserList(ctx context.Context, offset int, limit
 func (r *userRepositoryImpl) SaveUser(ctx context.Context, user entity.User) (entity.User, error) {
 	_, err := r.mongoCollection.InsertOne(ctx, user)
 	if err != nil {
-		log.Println("Error creating user:", err)
-		return entity.User{}, err
+		log.DebugContext(ctx, "error creating user", "error", err)
+		return entity.User{}, mongoError(err)
 	}
 	return user, nil
 }
@@ -84,43 +140,116 @@ func (r *userRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (
 
 	err := r.mongoCollection.FindOne(ctx, filter).Decode(&user)
 	if err != nil {
-		log.Println("Error finding user by email:", err)
-		return entity.User{}, err
+		log.DebugContext(ctx, "error finding user by email", "error", err)
+		return entity.User{}, mongoError(err)
 	}
 	return user, nil
 }
 
-func (r *userRepositoryImpl) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
-	filter := bson.M{"_id": user.ID}
-	update := bson.M{"$set": bson.M{
-		"name":       user.Name,
-		"email":      user.Email,
-		"password":   user.Password,
-		"updated_at": time.Now(),
-	}}
+func (r *userRepositoryImpl) UpdateUser(ctx context.Context, id string, version int64, update entity.UserUpdate) (entity.User, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return entity.User{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	// only set the fields that were changed
+	fields := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		fields["name"] = *update.Name
+	}
+	if update.Email != nil {
+		fields["email"] = *update.Email
+	}
+	if update.Password != nil {
+		fields["password"] = *update.Password
+	}
+	if update.Locale != nil {
+		fields["locale"] = *update.Locale
+	}
+	if update.SessionsRevokedAt != nil {
+		fields["sessions_revoked_at"] = *update.SessionsRevokedAt
+	}
+
+	// the version in the filter makes the update fail if someone else changed the user in between
+	filter := versionFilter(objectID, version)
+	changes := bson.M{
+		"$set": fields,
+		"$inc": bson.M{"version": 1},
+	}
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
 
-	_, err := r.mongoCollection.UpdateOne(ctx, filter, update)
+	var user entity.User
+	err = r.mongoCollection.FindOneAndUpdate(ctx, filter, changes, updateOptions).Decode(&user)
 	if err != nil {
-		log.Println("Error updating user:", err)
-		return entity.User{}, err
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entity.User{}, r.versionConflictOrNotFound(ctx, objectID)
+		}
+		log.DebugContext(ctx, "error updating user", "error", err)
+		return entity.User{}, mongoError(err)
 	}
 
 	return user, nil
 }
 
-func (r *userRepositoryImpl) DeleteUser(ctx context.Context, id string) error {
+func (r *userRepositoryImpl) DeleteUser(ctx context.Context, id string, version int64) error {
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("Error converting ID string '%s' to ObjectID: %v", id, err)
-		return fmt.Errorf("invalid user ID format: %w", err)
+		log.DebugContext(ctx, "invalid object id", "id", id, "error", err)
+		return fmt.Errorf("%w: %v", ErrInvalidID, err)
 	}
 
-	filter := bson.M{"_id": objectID}
+	filter := versionFilter(objectID, version)
 
-	_, err = r.mongoCollection.DeleteOne(ctx, filter)
+	result, err := r.mongoCollection.DeleteOne(ctx, filter)
 	if err != nil {
-		log.Println("Error deleting user:", err)
+		log.DebugContext(ctx, "error deleting user", "error", err)
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return r.versionConflictOrNotFound(ctx, objectID)
+	}
 	return nil
 }
+
+func (r *userRepositoryImpl) CountUsers(ctx context.Context) (int64, error) {
+	count, err := r.mongoCollection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		log.DebugContext(ctx, "error counting users", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func versionFilter(objectID bson.ObjectID, version int64) bson.M {
+	if version == 0 {
+		// users created before versioning have no version field yet
+		return bson.M{"_id": objectID, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": objectID, "version": version}
+}
+
+// versionConflictOrNotFound tells apart a missing user from a stale version after a write matched nothing.
+func (r *userRepositoryImpl) versionConflictOrNotFound(ctx context.Context, objectID bson.ObjectID) error {
+	count, err := r.mongoCollection.CountDocuments(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		log.DebugContext(ctx, "error checking user existence", "error", err)
+		return err
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return ErrNotFound
+}
+
+// mongoError returns the repository error of err, like ErrNotFound for
+// mongo.ErrNoDocuments, or err.
+func mongoError(err error) error {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return fmt.Errorf("%w: %v", ErrDuplicateEmail, err)
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+// WebhookRepository stores the webhooks and their deliveries. It returns
+// ErrNotFound for a missing webhook or delivery and ErrInvalidID for an id that
+// is not an ObjectID.
+type WebhookRepository interface {
+	SaveWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	GetWebhookById(ctx context.Context, id string) (entity.Webhook, error)
+	GetWebhookList(ctx context.Context) ([]entity.Webhook, error)
+	GetWebhooksByEvent(ctx context.Context, eventType string) ([]entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	SaveDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	GetDeliveryById(ctx context.Context, id string) (entity.WebhookDelivery, error)
+	GetDeliveryList(ctx context.Context, filter entity.WebhookDeliveryFilter, offset int, limit int) ([]entity.WebhookDelivery, error)
+	ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) error
+}
+
+type webhookRepositoryImpl struct {
+	webhookCollection  *mongo.Collection
+	deliveryCollection *mongo.Collection
+}
+
+func NewWebhookRepository(webhookCollection *mongo.Collection, deliveryCollection *mongo.Collection) WebhookRepository {
+	return &webhookRepositoryImpl{
+		webhookCollection:  webhookCollection,
+		deliveryCollection: deliveryCollection,
+	}
+}
+
+func (r *webhookRepositoryImpl) SaveWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	_, err := r.webhookCollection.InsertOne(ctx, webhook)
+	if err != nil {
+		log.DebugContext(ctx, "error creating webhook", "error", err)
+		return entity.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepositoryImpl) GetWebhookById(ctx context.Context, id string) (entity.Webhook, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return entity.Webhook{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	var webhook entity.Webhook
+	err = r.webhookCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&webhook)
+	if err != nil {
+		log.DebugContext(ctx, "error finding webhook by ID", "error", err)
+		return entity.Webhook{}, notFoundError(err)
+	}
+	return webhook, nil
+}
+
+func (r *webhookRepositoryImpl) GetWebhookList(ctx context.Context) ([]entity.Webhook, error) {
+	return r.findWebhooks(ctx, bson.M{})
+}
+
+func (r *webhookRepositoryImpl) GetWebhooksByEvent(ctx context.Context, eventType string) ([]entity.Webhook, error) {
+	return r.findWebhooks(ctx, bson.M{"events": eventType})
+}
+
+func (r *webhookRepositoryImpl) findWebhooks(ctx context.Context, filter bson.M) ([]entity.Webhook, error) {
+	cursor, err := r.webhookCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		log.DebugContext(ctx, "error finding webhooks", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	webhooks := []entity.Webhook{}
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		log.DebugContext(ctx, "error decoding webhooks", "error", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes the webhook, its deliveries are kept for the delivery log.
+func (r *webhookRepositoryImpl) DeleteWebhook(ctx context.Context, id string) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	result, err := r.webhookCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		log.DebugContext(ctx, "error deleting webhook", "error", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SaveDeliveries inserts deliveries. A delivery of an event that was already
+// saved for the same webhook is skipped, so publishing an event twice is safe.
+func (r *webhookRepositoryImpl) SaveDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	_, err := r.deliveryCollection.InsertMany(ctx, deliveries, options.InsertMany().SetOrdered(false))
+	if err != nil && !onlyDuplicateKeyErrors(err) {
+		log.DebugContext(ctx, "error creating webhook deliveries", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *webhookRepositoryImpl) GetDeliveryById(ctx context.Context, id string) (entity.WebhookDelivery, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return entity.WebhookDelivery{}, fmt.Errorf("%w: %v", ErrInvalidID, err)
+	}
+
+	var delivery entity.WebhookDelivery
+	err = r.deliveryCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&delivery)
+	if err != nil {
+		log.DebugContext(ctx, "error finding webhook delivery by ID", "error", err)
+		return entity.WebhookDelivery{}, notFoundError(err)
+	}
+	return delivery, nil
+}
+
+// GetDeliveryList returns deliveries newest first.
+func (r *webhookRepositoryImpl) GetDeliveryList(ctx context.Context, deliveryFilter entity.WebhookDeliveryFilter, offset int, limit int) ([]entity.WebhookDelivery, error) {
+	filter := bson.M{}
+	if deliveryFilter.WebhookID != "" {
+		webhookID, err := bson.ObjectIDFromHex(deliveryFilter.WebhookID)
+		if err != nil {
+			return []entity.WebhookDelivery{}, nil
+		}
+		filter["webhook_id"] = webhookID
+	}
+	if deliveryFilter.Status != "" {
+		filter["status"] = deliveryFilter.Status
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.deliveryCollection.Find(ctx, filter, findOptions)
+	if err != nil {
+		log.DebugContext(ctx, "error finding webhook deliveries", "error", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := []entity.WebhookDelivery{}
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		log.DebugContext(ctx, "error decoding webhook deliveries", "error", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDueDelivery returns the oldest pending delivery whose next attempt is due
+// and pushes its next attempt back by lease, so other workers skip it while it
+// is being sent. It returns ErrNotFound when nothing is due.
+func (r *webhookRepositoryImpl) ClaimDueDelivery(ctx context.Context, now time.Time, lease time.Duration) (entity.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":          entity.DeliveryStatusPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	claimOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.Before)
+
+	var delivery entity.WebhookDelivery
+	err := r.deliveryCollection.FindOneAndUpdate(ctx, filter, update, claimOptions).Decode(&delivery)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.DebugContext(ctx, "error claiming webhook delivery", "error", err)
+		}
+		return entity.WebhookDelivery{}, notFoundError(err)
+	}
+	return delivery, nil
+}
+
+func (r *webhookRepositoryImpl) UpdateDelivery(ctx context.Context, delivery entity.WebhookDelivery) error {
+	result, err := r.deliveryCollection.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+	if err != nil {
+		log.DebugContext(ctx, "error updating webhook delivery", "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// notFoundError returns ErrNotFound for mongo.ErrNoDocuments, or err.
+func notFoundError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// onlyDuplicateKeyErrors reports whether every write of a bulk insert failed on a unique index.
+func onlyDuplicateKeyErrors(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package background
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"time"
+)
+
+// StartOutboxRelay publishes the pending outbox events every interval until ctx is done,
+// beating heartbeat after every round.
+func StartOutboxRelay(ctx context.Context, outboxRelay service.OutboxRelay, interval time.Duration, heartbeat *health.Heartbeat) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.InfoContext(ctx, "starting background outbox relay")
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := outboxRelay.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				log.ErrorContext(ctx, "error relaying outbox events", "error", err)
+			}
+			heartbeat.Beat()
+		case <-ctx.Done():
+			log.InfoContext(ctx, "stopping background outbox relay")
+			return
+		}
+	}
+}
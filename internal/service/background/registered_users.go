@@ -0,0 +1,40 @@
+package background
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"time"
+)
+
+// StartRegisteredUsersGauge refreshes the registered users gauge every interval until ctx is done.
+func StartRegisteredUsersGauge(ctx context.Context, userRepository repository.UserRepository, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.InfoContext(ctx, "starting background registered users gauge")
+	for {
+		refreshRegisteredUsers(ctx, userRepository)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.InfoContext(ctx, "stopping background registered users gauge")
+			return
+		}
+	}
+}
+
+func refreshRegisteredUsers(ctx context.Context, userRepository repository.UserRepository) {
+	countCtx, cancel := context.WithTimeout(ctx, 5*time.Second) // timeout for database operations
+	defer cancel()
+
+	count, err := userRepository.CountUsers(countCtx)
+	if err != nil {
+		log.ErrorContext(ctx, "error counting users", "error", err)
+		return
+	}
+	metrics.RegisteredUsers.Set(float64(count))
+}
@@ -0,0 +1,36 @@
+package background
+
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"time"
+)
+
+// StartWebhookDispatcher sends the due webhook deliveries every interval until ctx is done,
+// beating heartbeat after every round.
+func StartWebhookDispatcher(ctx context.Context, webhookService service.WebhookService, interval time.Duration, heartbeat *health.Heartbeat) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.InfoContext(ctx, "starting background webhook dispatcher")
+	for {
+		select {
+		case <-ticker.C:
+			sent, err := webhookService.DeliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.ErrorContext(ctx, "error sending webhook deliveries", "error", err)
+			}
+			if sent > 0 {
+				log.InfoContext(ctx, "sent webhook deliveries", "count", sent)
+			}
+			heartbeat.Beat()
+		case <-ctx.Done():
+			log.InfoContext(ctx, "stopping background webhook dispatcher")
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"strings"
+)
+
+// defaultMaxBatchOperations is used when no batch size limit is configured.
+const defaultMaxBatchOperations = 100
+
+type BatchService interface {
+	ExecuteBatch(ctx context.Context, req dto.BatchRequest) (dto.BatchResult, error)
+}
+
+type batchServiceImpl struct {
+	userService   UserService
+	transactor    repository.Transactor
+	maxOperations int
+}
+
+// NewBatchService runs batches of at most maxOperations user operations through userService.
+func NewBatchService(userService UserService, transactor repository.Transactor, maxOperations int) BatchService {
+	if maxOperations <= 0 {
+		maxOperations = defaultMaxBatchOperations
+	}
+	return &batchServiceImpl{
+		userService:   userService,
+		transactor:    transactor,
+		maxOperations: maxOperations,
+	}
+}
+
+// ExecuteBatch runs the operations in order. Every operation gets its own
+// result, a failing operation does not stop the others unless the batch is
+// atomic: then all operations run in one transaction that is rolled back on the
+// first failure, and the other operations report errBatchRolledBack. A
+// transient transaction error is not a failure, the whole batch is retried.
+func (s batchServiceImpl) ExecuteBatch(ctx context.Context, req dto.BatchRequest) (dto.BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "BatchService.ExecuteBatch")
+	defer span.End()
+
+	if len(req.Operations) == 0 || len(req.Operations) > s.maxOperations {
+		return dto.BatchResult{}, errInvalidBatchSize(len(req.Operations), s.maxOperations)
+	}
+
+	if !req.Atomic {
+		results := make([]dto.BatchOperationResult, len(req.Operations))
+		for i, operation := range req.Operations {
+			results[i] = s.execute(ctx, operation)
+		}
+		return dto.BatchResult{Results: results}, nil
+	}
+
+	var results []dto.BatchOperationResult
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		// the transaction may be retried, start over with fresh results
+		results = make([]dto.BatchOperationResult, len(req.Operations))
+		for i, operation := range req.Operations {
+			results[i] = s.execute(ctx, operation)
+			if results[i].Err == nil {
+				continue
+			}
+			if repository.IsTransient(results[i].Err) {
+				// not a failure of the operation, the transaction is retried
+				return results[i].Err
+			}
+			for j := range results {
+				if j != i {
+					results[j] = dto.BatchOperationResult{ID: req.Operations[j].ID, Err: errBatchRolledBack}
+				}
+			}
+			return errBatchAborted
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchAborted) {
+		return dto.BatchResult{RolledBack: true, Results: results}, nil
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "batch transaction failed", "error", err)
+		return dto.BatchResult{}, apperror.Internal(err)
+	}
+	return dto.BatchResult{Results: results}, nil
+}
+
+func (s batchServiceImpl) execute(ctx context.Context, operation dto.BatchOperation) dto.BatchOperationResult {
+	result := dto.BatchOperationResult{ID: operation.ID}
+	switch operation.Method {
+	case dto.BatchMethodGet:
+		result.User, result.Err = s.get(ctx, operation)
+	case dto.BatchMethodCreate:
+		result.User, result.Err = s.create(ctx, operation)
+	case dto.BatchMethodUpdate:
+		result.User, result.Err = s.update(ctx, operation)
+	case dto.BatchMethodDelete:
+		result.Err = s.delete(ctx, operation)
+	default:
+		result.Err = validation.Struct(operation)
+		if result.Err == nil {
+			result.Err = errFieldRequired("method")
+		}
+	}
+	return result
+}
+
+func (s batchServiceImpl) get(ctx context.Context, operation dto.BatchOperation) (*dto.UserGetMeResponse, error) {
+	if operation.UserID == "" {
+		return nil, errFieldRequired("userId")
+	}
+	user, err := s.userService.GetUserByID(ctx, operation.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s batchServiceImpl) create(ctx context.Context, operation dto.BatchOperation) (*dto.UserGetMeResponse, error) {
+	if operation.User == nil {
+		return nil, errFieldRequired("user")
+	}
+	req := dto.UserRegisterRequest{
+		Name:     operation.User.Name,
+		Email:    operation.User.Email,
+		Password: operation.User.Password,
+		Locale:   operation.User.Locale,
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	req.Email = strings.ToLower(req.Email)
+
+	created, err := s.userService.RegisterUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// registered users start at version 1
+	return &dto.UserGetMeResponse{ID: created.ID, Name: created.Name, Email: created.Email, Locale: req.Locale, Version: 1}, nil
+}
+
+func (s batchServiceImpl) update(ctx context.Context, operation dto.BatchOperation) (*dto.UserGetMeResponse, error) {
+	if operation.UserID == "" {
+		return nil, errFieldRequired("userId")
+	}
+	if operation.User == nil {
+		return nil, errFieldRequired("user")
+	}
+	req := dto.UserUpdateRequest{
+		Name:    operation.User.Name,
+		Email:   operation.User.Email,
+		Locale:  operation.User.Locale,
+		IfMatch: operation.IfMatch,
+	}
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	req.Email = strings.ToLower(req.Email)
+
+	updated, err := s.userService.UpdateUser(ctx, operation.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UserGetMeResponse{ID: updated.ID, Name: updated.Name, Email: updated.Email, Locale: updated.Locale, Version: updated.Version}, nil
+}
+
+func (s batchServiceImpl) delete(ctx context.Context, operation dto.BatchOperation) error {
+	if operation.UserID == "" {
+		return errFieldRequired("userId")
+	}
+	return s.userService.DeleteUser(ctx, operation.UserID, dto.UserDeleteRequest{IfMatch: operation.IfMatch})
+}
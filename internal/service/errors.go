@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"strconv"
+)
+
+var (
+	// ErrPreconditionFailed is returned when the If-Match precondition does not match the current user version.
+	ErrPreconditionFailed = apperror.New(apperror.KindPreconditionFailed, apperror.CodePreconditionFailed, "user has been modified, precondition failed")
+	// ErrConcurrentModification is returned when the user was changed by another request during an update.
+	ErrConcurrentModification = apperror.Conflict(apperror.CodeConcurrentModification, "user was modified by another request, please retry")
+	// ErrInvalidCredentials is returned on login with an unknown email or a wrong password.
+	ErrInvalidCredentials = apperror.Unauthorized(apperror.CodeInvalidCredentials, "invalid email or password")
+
+	errInvalidCursor   = apperror.Validation(apperror.CodeInvalidCursor, "invalid pagination cursor")
+	errBatchRolledBack = apperror.New(apperror.KindFailedDependency, apperror.CodeBatchRolledBack, "the batch was rolled back because another operation failed")
+	// errBatchAborted aborts the transaction of an atomic batch, it never reaches clients.
+	errBatchAborted = errors.New("batch aborted")
+)
+
+const maxConnectionSize = 100
+
+func errInvalidConnectionSize(first int) error {
+	field := apperror.FieldError{Field: "first", Code: apperror.CodeFieldTooSmall, Message: "first must be at least 1", Params: map[string]string{"param": "1"}}
+	if first > maxConnectionSize {
+		limit := strconv.Itoa(maxConnectionSize)
+		field = apperror.FieldError{Field: "first", Code: apperror.CodeFieldTooLarge, Message: "first must be at most " + limit, Params: map[string]string{"param": limit}}
+	}
+	return apperror.Validation(apperror.CodeValidationFailed, "validation failed", field)
+}
+
+func errInvalidBatchSize(size int, max int) error {
+	field := apperror.FieldError{Field: "operations", Code: apperror.CodeFieldRequired, Message: "operations is required"}
+	if size > max {
+		limit := strconv.Itoa(max)
+		field = apperror.FieldError{Field: "operations", Code: apperror.CodeFieldTooLarge, Message: "operations must be at most " + limit, Params: map[string]string{"param": limit}}
+	}
+	return apperror.Validation(apperror.CodeValidationFailed, "validation failed", field)
+}
+
+func errFieldRequired(field string) error {
+	return apperror.Validation(apperror.CodeValidationFailed, "validation failed",
+		apperror.FieldError{Field: field, Code: apperror.CodeFieldRequired, Message: field + " is required"})
+}
+
+func errUserNotFound(id string) error {
+	return apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", id)).
+		WithParam("id", id)
+}
+
+func errWebhookNotFound(id string) error {
+	return apperror.NotFound(apperror.CodeWebhookNotFound, fmt.Sprintf("webhook with id %s not found", id)).
+		WithParam("id", id)
+}
+
+func errDeliveryNotFound(id string) error {
+	return apperror.NotFound(apperror.CodeDeliveryNotFound, fmt.Sprintf("webhook delivery with id %s not found", id)).
+		WithParam("id", id)
+}
+
+func errEmailAlreadyExists(email string) error {
+	return apperror.Conflict(apperror.CodeEmailAlreadyExists, fmt.Sprintf("email %s is already registered", email)).
+		WithParam("email", email)
+}
+
+func errInvalidPatch(err error, fields ...apperror.FieldError) error {
+	return apperror.Validation(apperror.CodeInvalidPatch, fmt.Sprintf("invalid patch: %v", err), fields...).
+		WithParam("reason", err.Error())
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, repository.ErrNotFound) || errors.Is(err, repository.ErrInvalidID)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"sync"
+	"time"
+)
+
+// EventPublisher receives the events the outbox relay publishes.
+type EventPublisher interface {
+	Publish(ctx context.Context, event entity.Event) error
+}
+
+// EventBus is the in-process sink of the outbox relay, it publishes every event
+// to each subscriber. An event is published again to every subscriber when one
+// of them fails, so subscribers must tolerate duplicates.
+type EventBus struct {
+	mu          sync.RWMutex
+	names       []string
+	subscribers []EventPublisher
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds subscriber under name, which is used in errors.
+func (b *EventBus) Subscribe(name string, subscriber EventPublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.names = append(b.names, name)
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+func (b *EventBus) Publish(ctx context.Context, event entity.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var errs []error
+	for i, subscriber := range b.subscribers {
+		if err := subscriber.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", b.names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newUserEvent returns the outbox event of a change to user. The sequence of a
+// deletion follows the last version of the user.
+func newUserEvent(eventType string, user entity.User) entity.OutboxEvent {
+	data := entity.UserSnapshot{ID: user.ID.Hex()}
+	sequence := user.Version
+	if eventType == entity.EventUserDeleted {
+		sequence++
+	} else {
+		data = entity.UserSnapshot{
+			ID:      user.ID.Hex(),
+			Name:    user.Name,
+			Email:   user.Email,
+			Locale:  user.Locale,
+			Version: user.Version,
+		}
+	}
+	now := time.Now().UTC()
+	return entity.OutboxEvent{
+		Event: entity.Event{
+			ID:         bson.NewObjectID().Hex(),
+			Type:       eventType,
+			OccurredAt: now,
+			Data:       data,
+		},
+		AggregateID:   user.ID.Hex(),
+		Sequence:      sequence,
+		CreatedAt:     now,
+		Status:        entity.OutboxStatusPending,
+		NextAttemptAt: now,
+	}
+}
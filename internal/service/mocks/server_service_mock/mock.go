@@ -5,7 +5,10 @@
 package mock_server_service
 
 import (
+	"context"
+
 	mock "github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
 )
 
 // NewServerService creates a new instance of ServerService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -35,46 +38,92 @@ func (_m *ServerService) EXPECT() *ServerService_Expecter {
 	return &ServerService_Expecter{mock: &_m.Mock}
 }
 
-// HealthCheck provides a mock function for the type ServerService
-func (_mock *ServerService) HealthCheck() string {
-	ret := _mock.Called()
+// Liveness provides a mock function for the type ServerService
+func (_mock *ServerService) Liveness(ctx context.Context) health.Report {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Liveness")
+	}
+
+	var r0 health.Report
+	if returnFunc, ok := ret.Get(0).(func(context.Context) health.Report); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(health.Report)
+	}
+	return r0
+}
+
+// ServerService_Liveness_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Liveness'
+type ServerService_Liveness_Call struct {
+	*mock.Call
+}
+
+// Liveness is a helper method to define mock.On call
+//   - ctx
+func (_e *ServerService_Expecter) Liveness(ctx interface{}) *ServerService_Liveness_Call {
+	return &ServerService_Liveness_Call{Call: _e.mock.On("Liveness", ctx)}
+}
+
+func (_c *ServerService_Liveness_Call) Run(run func(ctx context.Context)) *ServerService_Liveness_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *ServerService_Liveness_Call) Return(report health.Report) *ServerService_Liveness_Call {
+	_c.Call.Return(report)
+	return _c
+}
+
+func (_c *ServerService_Liveness_Call) RunAndReturn(run func(ctx context.Context) health.Report) *ServerService_Liveness_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Readiness provides a mock function for the type ServerService
+func (_mock *ServerService) Readiness(ctx context.Context) health.Report {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for HealthCheck")
+		panic("no return value specified for Readiness")
 	}
 
-	var r0 string
-	if returnFunc, ok := ret.Get(0).(func() string); ok {
-		r0 = returnFunc()
+	var r0 health.Report
+	if returnFunc, ok := ret.Get(0).(func(context.Context) health.Report); ok {
+		r0 = returnFunc(ctx)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(health.Report)
 	}
 	return r0
 }
 
-// ServerService_HealthCheck_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HealthCheck'
-type ServerService_HealthCheck_Call struct {
+// ServerService_Readiness_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Readiness'
+type ServerService_Readiness_Call struct {
 	*mock.Call
 }
 
-// HealthCheck is a helper method to define mock.On call
-func (_e *ServerService_Expecter) HealthCheck() *ServerService_HealthCheck_Call {
-	return &ServerService_HealthCheck_Call{Call: _e.mock.On("HealthCheck")}
+// Readiness is a helper method to define mock.On call
+//   - ctx
+func (_e *ServerService_Expecter) Readiness(ctx interface{}) *ServerService_Readiness_Call {
+	return &ServerService_Readiness_Call{Call: _e.mock.On("Readiness", ctx)}
 }
 
-func (_c *ServerService_HealthCheck_Call) Run(run func()) *ServerService_HealthCheck_Call {
+func (_c *ServerService_Readiness_Call) Run(run func(ctx context.Context)) *ServerService_Readiness_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *ServerService_HealthCheck_Call) Return(s string) *ServerService_HealthCheck_Call {
-	_c.Call.Return(s)
+func (_c *ServerService_Readiness_Call) Return(report health.Report) *ServerService_Readiness_Call {
+	_c.Call.Return(report)
 	return _c
 }
 
-func (_c *ServerService_HealthCheck_Call) RunAndReturn(run func() string) *ServerService_HealthCheck_Call {
+func (_c *ServerService_Readiness_Call) RunAndReturn(run func(ctx context.Context) health.Report) *ServerService_Readiness_Call {
 	_c.Call.Return(run)
 	return _c
 }
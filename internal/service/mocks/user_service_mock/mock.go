@@ -6,6 +6,7 @@ package mock_user_service
 
 import (
 	"context"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
@@ -38,17 +39,72 @@ func (_m *UserService) EXPECT() *UserService_Expecter {
 	return &UserService_Expecter{mock: &_m.Mock}
 }
 
+// CreateAdmin provides a mock function for the type UserService
+func (_mock *UserService) CreateAdmin(ctx context.Context, req dto.UserRegisterRequest) (dto.UserGetMeResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAdmin")
+	}
+
+	var r0 dto.UserGetMeResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.UserRegisterRequest) (dto.UserGetMeResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.UserRegisterRequest) dto.UserGetMeResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Get(0).(dto.UserGetMeResponse)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, dto.UserRegisterRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_CreateAdmin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAdmin'
+type UserService_CreateAdmin_Call struct {
+	*mock.Call
+}
+
+// CreateAdmin is a helper method to define mock.On call
+//   - ctx
+//   - req
+func (_e *UserService_Expecter) CreateAdmin(ctx interface{}, req interface{}) *UserService_CreateAdmin_Call {
+	return &UserService_CreateAdmin_Call{Call: _e.mock.On("CreateAdmin", ctx, req)}
+}
+
+func (_c *UserService_CreateAdmin_Call) Run(run func(ctx context.Context, req dto.UserRegisterRequest)) *UserService_CreateAdmin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.UserRegisterRequest))
+	})
+	return _c
+}
+
+func (_c *UserService_CreateAdmin_Call) Return(userGetMeResponse dto.UserGetMeResponse, err error) *UserService_CreateAdmin_Call {
+	_c.Call.Return(userGetMeResponse, err)
+	return _c
+}
+
+func (_c *UserService_CreateAdmin_Call) RunAndReturn(run func(ctx context.Context, req dto.UserRegisterRequest) (dto.UserGetMeResponse, error)) *UserService_CreateAdmin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteUser provides a mock function for the type UserService
-func (_mock *UserService) DeleteUser(ctx context.Context, id string) error {
-	ret := _mock.Called(ctx, id)
+func (_mock *UserService) DeleteUser(ctx context.Context, id string, req dto.UserDeleteRequest) error {
+	ret := _mock.Called(ctx, id, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUser")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, dto.UserDeleteRequest) error); ok {
+		r0 = returnFunc(ctx, id, req)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -63,13 +119,14 @@ type UserService_DeleteUser_Call struct {
 // DeleteUser is a helper method to define mock.On call
 //   - ctx
 //   - id
-func (_e *UserService_Expecter) DeleteUser(ctx interface{}, id interface{}) *UserService_DeleteUser_Call {
-	return &UserService_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id)}
+//   - req
+func (_e *UserService_Expecter) DeleteUser(ctx interface{}, id interface{}, req interface{}) *UserService_DeleteUser_Call {
+	return &UserService_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id, req)}
 }
 
-func (_c *UserService_DeleteUser_Call) Run(run func(ctx context.Context, id string)) *UserService_DeleteUser_Call {
+func (_c *UserService_DeleteUser_Call) Run(run func(ctx context.Context, id string, req dto.UserDeleteRequest)) *UserService_DeleteUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(dto.UserDeleteRequest))
 	})
 	return _c
 }
@@ -79,7 +136,53 @@ func (_c *UserService_DeleteUser_Call) Return(err error) *UserService_DeleteUser
 	return _c
 }
 
-func (_c *UserService_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id string) error) *UserService_DeleteUser_Call {
+func (_c *UserService_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id string, req dto.UserDeleteRequest) error) *UserService_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportUsers provides a mock function for the type UserService
+func (_mock *UserService) ExportUsers(ctx context.Context, fn func(dto.UserRecord) error) error {
+	ret := _mock.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportUsers")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, func(dto.UserRecord) error) error); ok {
+		r0 = returnFunc(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// UserService_ExportUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportUsers'
+type UserService_ExportUsers_Call struct {
+	*mock.Call
+}
+
+// ExportUsers is a helper method to define mock.On call
+//   - ctx
+//   - fn
+func (_e *UserService_Expecter) ExportUsers(ctx interface{}, fn interface{}) *UserService_ExportUsers_Call {
+	return &UserService_ExportUsers_Call{Call: _e.mock.On("ExportUsers", ctx, fn)}
+}
+
+func (_c *UserService_ExportUsers_Call) Run(run func(ctx context.Context, fn func(dto.UserRecord) error)) *UserService_ExportUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(dto.UserRecord) error))
+	})
+	return _c
+}
+
+func (_c *UserService_ExportUsers_Call) Return(err error) *UserService_ExportUsers_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *UserService_ExportUsers_Call) RunAndReturn(run func(ctx context.Context, fn func(dto.UserRecord) error) error) *UserService_ExportUsers_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -139,6 +242,61 @@ func (_c *UserService_GetUserByID_Call) RunAndReturn(run func(ctx context.Contex
 	return _c
 }
 
+// GetUserConnection provides a mock function for the type UserService
+func (_mock *UserService) GetUserConnection(ctx context.Context, req dto.UserConnectionRequest) (dto.UserConnectionResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserConnection")
+	}
+
+	var r0 dto.UserConnectionResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.UserConnectionRequest) (dto.UserConnectionResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.UserConnectionRequest) dto.UserConnectionResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Get(0).(dto.UserConnectionResponse)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, dto.UserConnectionRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_GetUserConnection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserConnection'
+type UserService_GetUserConnection_Call struct {
+	*mock.Call
+}
+
+// GetUserConnection is a helper method to define mock.On call
+//   - ctx
+//   - req
+func (_e *UserService_Expecter) GetUserConnection(ctx interface{}, req interface{}) *UserService_GetUserConnection_Call {
+	return &UserService_GetUserConnection_Call{Call: _e.mock.On("GetUserConnection", ctx, req)}
+}
+
+func (_c *UserService_GetUserConnection_Call) Run(run func(ctx context.Context, req dto.UserConnectionRequest)) *UserService_GetUserConnection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.UserConnectionRequest))
+	})
+	return _c
+}
+
+func (_c *UserService_GetUserConnection_Call) Return(userConnectionResponse dto.UserConnectionResponse, err error) *UserService_GetUserConnection_Call {
+	_c.Call.Return(userConnectionResponse, err)
+	return _c
+}
+
+func (_c *UserService_GetUserConnection_Call) RunAndReturn(run func(ctx context.Context, req dto.UserConnectionRequest) (dto.UserConnectionResponse, error)) *UserService_GetUserConnection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetUserList provides a mock function for the type UserService
 func (_mock *UserService) GetUserList(ctx context.Context, req dto.UserListGetRequest) (dto.UserListGetResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -194,6 +352,109 @@ func (_c *UserService_GetUserList_Call) RunAndReturn(run func(ctx context.Contex
 	return _c
 }
 
+// GetUsersByIDs provides a mock function for the type UserService
+func (_mock *UserService) GetUsersByIDs(ctx context.Context, ids []string) ([]dto.UserListGetResponseItem, error) {
+	ret := _mock.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
+	var r0 []dto.UserListGetResponseItem
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]dto.UserListGetResponseItem, error)); ok {
+		return returnFunc(ctx, ids)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []dto.UserListGetResponseItem); ok {
+		r0 = returnFunc(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.UserListGetResponseItem)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_GetUsersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsersByIDs'
+type UserService_GetUsersByIDs_Call struct {
+	*mock.Call
+}
+
+// GetUsersByIDs is a helper method to define mock.On call
+//   - ctx
+//   - ids
+func (_e *UserService_Expecter) GetUsersByIDs(ctx interface{}, ids interface{}) *UserService_GetUsersByIDs_Call {
+	return &UserService_GetUsersByIDs_Call{Call: _e.mock.On("GetUsersByIDs", ctx, ids)}
+}
+
+func (_c *UserService_GetUsersByIDs_Call) Run(run func(ctx context.Context, ids []string)) *UserService_GetUsersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *UserService_GetUsersByIDs_Call) Return(userListGetResponseItems []dto.UserListGetResponseItem, err error) *UserService_GetUsersByIDs_Call {
+	_c.Call.Return(userListGetResponseItems, err)
+	return _c
+}
+
+func (_c *UserService_GetUsersByIDs_Call) RunAndReturn(run func(ctx context.Context, ids []string) ([]dto.UserListGetResponseItem, error)) *UserService_GetUsersByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportUser provides a mock function for the type UserService
+func (_mock *UserService) ImportUser(ctx context.Context, record dto.UserRecord) error {
+	ret := _mock.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, dto.UserRecord) error); ok {
+		r0 = returnFunc(ctx, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// UserService_ImportUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportUser'
+type UserService_ImportUser_Call struct {
+	*mock.Call
+}
+
+// ImportUser is a helper method to define mock.On call
+//   - ctx
+//   - record
+func (_e *UserService_Expecter) ImportUser(ctx interface{}, record interface{}) *UserService_ImportUser_Call {
+	return &UserService_ImportUser_Call{Call: _e.mock.On("ImportUser", ctx, record)}
+}
+
+func (_c *UserService_ImportUser_Call) Run(run func(ctx context.Context, record dto.UserRecord)) *UserService_ImportUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.UserRecord))
+	})
+	return _c
+}
+
+func (_c *UserService_ImportUser_Call) Return(err error) *UserService_ImportUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *UserService_ImportUser_Call) RunAndReturn(run func(ctx context.Context, record dto.UserRecord) error) *UserService_ImportUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LoginUser provides a mock function for the type UserService
 func (_mock *UserService) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -249,6 +510,62 @@ func (_c *UserService_LoginUser_Call) RunAndReturn(run func(ctx context.Context,
 	return _c
 }
 
+// PatchUser provides a mock function for the type UserService
+func (_mock *UserService) PatchUser(ctx context.Context, id string, req dto.UserPatchRequest) (dto.UserUpdateResponse, error) {
+	ret := _mock.Called(ctx, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchUser")
+	}
+
+	var r0 dto.UserUpdateResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, dto.UserPatchRequest) (dto.UserUpdateResponse, error)); ok {
+		return returnFunc(ctx, id, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, dto.UserPatchRequest) dto.UserUpdateResponse); ok {
+		r0 = returnFunc(ctx, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.UserUpdateResponse)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, dto.UserPatchRequest) error); ok {
+		r1 = returnFunc(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_PatchUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchUser'
+type UserService_PatchUser_Call struct {
+	*mock.Call
+}
+
+// PatchUser is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - req
+func (_e *UserService_Expecter) PatchUser(ctx interface{}, id interface{}, req interface{}) *UserService_PatchUser_Call {
+	return &UserService_PatchUser_Call{Call: _e.mock.On("PatchUser", ctx, id, req)}
+}
+
+func (_c *UserService_PatchUser_Call) Run(run func(ctx context.Context, id string, req dto.UserPatchRequest)) *UserService_PatchUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(dto.UserPatchRequest))
+	})
+	return _c
+}
+
+func (_c *UserService_PatchUser_Call) Return(userUpdateResponse dto.UserUpdateResponse, err error) *UserService_PatchUser_Call {
+	_c.Call.Return(userUpdateResponse, err)
+	return _c
+}
+
+func (_c *UserService_PatchUser_Call) RunAndReturn(run func(ctx context.Context, id string, req dto.UserPatchRequest) (dto.UserUpdateResponse, error)) *UserService_PatchUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RegisterUser provides a mock function for the type UserService
 func (_mock *UserService) RegisterUser(ctx context.Context, req dto.UserRegisterRequest) (dto.UserRegisterResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -304,6 +621,209 @@ func (_c *UserService_RegisterUser_Call) RunAndReturn(run func(ctx context.Conte
 	return _c
 }
 
+// ReissueRegistration provides a mock function for the type UserService
+func (_mock *UserService) ReissueRegistration(ctx context.Context, id string) (dto.UserRegisterResponse, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReissueRegistration")
+	}
+
+	var r0 dto.UserRegisterResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (dto.UserRegisterResponse, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) dto.UserRegisterResponse); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(dto.UserRegisterResponse)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_ReissueRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReissueRegistration'
+type UserService_ReissueRegistration_Call struct {
+	*mock.Call
+}
+
+// ReissueRegistration is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *UserService_Expecter) ReissueRegistration(ctx interface{}, id interface{}) *UserService_ReissueRegistration_Call {
+	return &UserService_ReissueRegistration_Call{Call: _e.mock.On("ReissueRegistration", ctx, id)}
+}
+
+func (_c *UserService_ReissueRegistration_Call) Run(run func(ctx context.Context, id string)) *UserService_ReissueRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_ReissueRegistration_Call) Return(userRegisterResponse dto.UserRegisterResponse, err error) *UserService_ReissueRegistration_Call {
+	_c.Call.Return(userRegisterResponse, err)
+	return _c
+}
+
+func (_c *UserService_ReissueRegistration_Call) RunAndReturn(run func(ctx context.Context, id string) (dto.UserRegisterResponse, error)) *UserService_ReissueRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPassword provides a mock function for the type UserService
+func (_mock *UserService) ResetPassword(ctx context.Context, id string, password string) error {
+	ret := _mock.Called(ctx, id, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, id, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// UserService_ResetPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPassword'
+type UserService_ResetPassword_Call struct {
+	*mock.Call
+}
+
+// ResetPassword is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - password
+func (_e *UserService_Expecter) ResetPassword(ctx interface{}, id interface{}, password interface{}) *UserService_ResetPassword_Call {
+	return &UserService_ResetPassword_Call{Call: _e.mock.On("ResetPassword", ctx, id, password)}
+}
+
+func (_c *UserService_ResetPassword_Call) Run(run func(ctx context.Context, id string, password string)) *UserService_ResetPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_ResetPassword_Call) Return(err error) *UserService_ResetPassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *UserService_ResetPassword_Call) RunAndReturn(run func(ctx context.Context, id string, password string) error) *UserService_ResetPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSessions provides a mock function for the type UserService
+func (_mock *UserService) RevokeSessions(ctx context.Context, id string) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessions")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// UserService_RevokeSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSessions'
+type UserService_RevokeSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeSessions is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *UserService_Expecter) RevokeSessions(ctx interface{}, id interface{}) *UserService_RevokeSessions_Call {
+	return &UserService_RevokeSessions_Call{Call: _e.mock.On("RevokeSessions", ctx, id)}
+}
+
+func (_c *UserService_RevokeSessions_Call) Run(run func(ctx context.Context, id string)) *UserService_RevokeSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_RevokeSessions_Call) Return(err error) *UserService_RevokeSessions_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *UserService_RevokeSessions_Call) RunAndReturn(run func(ctx context.Context, id string) error) *UserService_RevokeSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SessionsRevokedAt provides a mock function for the type UserService
+func (_mock *UserService) SessionsRevokedAt(ctx context.Context, id string) (time.Time, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SessionsRevokedAt")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (time.Time, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) time.Time); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// UserService_SessionsRevokedAt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SessionsRevokedAt'
+type UserService_SessionsRevokedAt_Call struct {
+	*mock.Call
+}
+
+// SessionsRevokedAt is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *UserService_Expecter) SessionsRevokedAt(ctx interface{}, id interface{}) *UserService_SessionsRevokedAt_Call {
+	return &UserService_SessionsRevokedAt_Call{Call: _e.mock.On("SessionsRevokedAt", ctx, id)}
+}
+
+func (_c *UserService_SessionsRevokedAt_Call) Run(run func(ctx context.Context, id string)) *UserService_SessionsRevokedAt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserService_SessionsRevokedAt_Call) Return(time time.Time, err error) *UserService_SessionsRevokedAt_Call {
+	_c.Call.Return(time, err)
+	return _c
+}
+
+func (_c *UserService_SessionsRevokedAt_Call) RunAndReturn(run func(ctx context.Context, id string) (time.Time, error)) *UserService_SessionsRevokedAt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateUser provides a mock function for the type UserService
 func (_mock *UserService) UpdateUser(ctx context.Context, id string, req dto.UserUpdateRequest) (dto.UserUpdateResponse, error) {
 	ret := _mock.Called(ctx, id, req)
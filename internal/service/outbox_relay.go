@@ -0,0 +1,163 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"github.com/taninchot-work/backend-challenge/internal/core/webhook"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"os"
+	"slices"
+	"time"
+)
+
+const (
+	outboxRelayLease            = "outbox_relay"
+	defaultOutboxBatchSize      = 100
+	defaultOutboxLeaseTTL       = 30 * time.Second
+	defaultOutboxMaxAttempts    = 10
+	defaultOutboxInitialBackoff = time.Second
+	defaultOutboxMaxBackoff     = 5 * time.Minute
+)
+
+// OutboxRelay publishes the events of the outbox to a sink.
+type OutboxRelay interface {
+	// RelayPending publishes the pending events and returns how many were published.
+	// It does nothing while another replica holds the relay lease.
+	RelayPending(ctx context.Context) (int, error)
+}
+
+// OutboxOptions configures the outbox relay.
+type OutboxOptions struct {
+	// BatchSize is the most events published by one RelayPending call.
+	BatchSize int
+	// LeaseTTL is how long the relay keeps the lease without renewing it, another
+	// replica takes over after that.
+	LeaseTTL time.Duration
+	// MaxAttempts is the number of publish attempts before an event is dead.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type outboxRelayImpl struct {
+	outboxRepository repository.OutboxRepository
+	lockRepository   repository.LockRepository
+	sink             EventPublisher
+	owner            string
+	options          OutboxOptions
+}
+
+// NewOutboxRelay returns an OutboxRelay that publishes to sink. Only one replica
+// relays at a time, so the events of a user are published in order. An event
+// stays in the outbox until sink accepted it and is published again with a
+// growing backoff after a failure, sinks receive every event at least once.
+// After MaxAttempts failures the event is dead: it stays in the outbox without
+// being published and no longer holds back the later events of its user.
+func NewOutboxRelay(outboxRepository repository.OutboxRepository, lockRepository repository.LockRepository, sink EventPublisher, options OutboxOptions) OutboxRelay {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultOutboxBatchSize
+	}
+	if options.LeaseTTL <= 0 {
+		options.LeaseTTL = defaultOutboxLeaseTTL
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultOutboxMaxAttempts
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = defaultOutboxInitialBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultOutboxMaxBackoff
+	}
+	return &outboxRelayImpl{
+		outboxRepository: outboxRepository,
+		lockRepository:   lockRepository,
+		sink:             sink,
+		owner:            relayOwner(),
+		options:          options,
+	}
+}
+
+func (s outboxRelayImpl) RelayPending(ctx context.Context) (int, error) {
+	acquired, err := s.lockRepository.AcquireLease(ctx, outboxRelayLease, s.owner, s.options.LeaseTTL)
+	if err != nil || !acquired {
+		return 0, err
+	}
+
+	now := time.Now()
+	events, err := s.outboxRepository.GetPendingEvents(ctx, now, s.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var published []string
+	for _, aggregate := range groupByAggregate(events) {
+		for _, event := range aggregate {
+			// the later events of this user wait until this one is published
+			if event.NextAttemptAt.After(now) {
+				break
+			}
+			err := s.sink.Publish(ctx, event.Event)
+			if err == nil {
+				published = append(published, event.ID)
+				continue
+			}
+			attempts := event.Attempts + 1
+			if attempts >= s.options.MaxAttempts {
+				log.ErrorContext(ctx, "outbox event is dead", "event_id", event.ID, "type", event.Type, "attempts", attempts, "error", err)
+				if err := s.outboxRepository.MarkDead(ctx, event.ID, err.Error()); err != nil {
+					return 0, err
+				}
+				metrics.ObserveOutboxDeadEvent(event.Type)
+				continue
+			}
+			log.WarnContext(ctx, "failed to publish outbox event", "event_id", event.ID, "attempts", attempts, "error", err)
+			nextAttemptAt := now.Add(webhook.Backoff(attempts, s.options.InitialBackoff, s.options.MaxBackoff))
+			if err := s.outboxRepository.RecordFailure(ctx, event.ID, err.Error(), nextAttemptAt); err != nil {
+				return 0, err
+			}
+			break
+		}
+	}
+	if err := s.outboxRepository.DeleteEvents(ctx, published); err != nil {
+		return 0, err
+	}
+	return len(published), nil
+}
+
+// groupByAggregate splits events by user, in the order the users first appear,
+// and sorts the events of each user by sequence.
+func groupByAggregate(events []entity.OutboxEvent) [][]entity.OutboxEvent {
+	var groups [][]entity.OutboxEvent
+	index := map[string]int{}
+	for _, event := range events {
+		i, ok := index[event.AggregateID]
+		if !ok {
+			i = len(groups)
+			index[event.AggregateID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], event)
+	}
+	for _, group := range groups {
+		slices.SortStableFunc(group, func(a, b entity.OutboxEvent) int {
+			return cmp.Compare(a.Sequence, b.Sequence)
+		})
+	}
+	return groups
+}
+
+// relayOwner identifies this process as the holder of the relay lease.
+func relayOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
@@ -1,15 +1,31 @@
 package service
 
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+)
+
 type ServerService interface {
-	HealthCheck() string
+	Liveness(ctx context.Context) health.Report
+	Readiness(ctx context.Context) health.Report
+}
+
+type serverServiceImpl struct {
+	health *health.Registry
 }
 
-type serverServiceImpl struct{}
+// Liveness runs the checks that fail when the process must be restarted.
+func (s *serverServiceImpl) Liveness(ctx context.Context) health.Report {
+	return s.health.Run(ctx, health.Liveness)
+}
 
-func (s *serverServiceImpl) HealthCheck() string {
-	return "OK"
+// Readiness runs the checks that fail when the process should not get traffic.
+func (s *serverServiceImpl) Readiness(ctx context.Context) health.Report {
+	return s.health.Run(ctx, health.Readiness)
 }
 
-func NewServerService() ServerService {
-	return &serverServiceImpl{}
+func NewServerService(registry *health.Registry) ServerService {
+	return &serverServiceImpl{
+		health: registry,
+	}
 }
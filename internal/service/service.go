@@ -1,15 +1,79 @@
 package service
 
-import "github.com/taninchot-work/backend-challenge/internal/repository"
+import (
+	"context"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/eventstream"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"time"
+)
 
 type Service struct {
-	ServerService ServerService
-	UserService   UserService
+	ServerService  ServerService
+	Health         *health.Registry
+	UserService    UserService
+	BatchService   BatchService
+	WebhookService WebhookService
+	EventBroker    *eventstream.Broker
+	EventBus       *EventBus
+	OutboxRelay    OutboxRelay
 }
 
 func NewService(repository *repository.Repository) *Service {
+	cfg := config.GetConfig()
+	webhookService := NewWebhookService(repository.WebhookRepository, WebhookOptions{
+		Timeout:              time.Duration(cfg.Webhook.Timeout) * time.Millisecond,
+		MaxAttempts:          cfg.Webhook.MaxAttempts,
+		InitialBackoff:       time.Duration(cfg.Webhook.InitialBackoff) * time.Millisecond,
+		MaxBackoff:           time.Duration(cfg.Webhook.MaxBackoff) * time.Millisecond,
+		BatchSize:            cfg.Webhook.BatchSize,
+		AllowPrivateNetworks: cfg.Webhook.AllowPrivateNetworks,
+	})
+	healthRegistry := newHealthRegistry(cfg.Health)
+	eventBroker := eventstream.NewBroker(cfg.RestServer.Events.ReplaySize, cfg.RestServer.Events.SubscriberBuffer)
+	// the outbox relay is the only publisher of user events
+	eventBus := NewEventBus()
+	eventBus.Subscribe("webhooks", webhookService)
+	eventBus.Subscribe("event stream", eventBroker)
+	outboxRelay := NewOutboxRelay(repository.OutboxRepository, repository.LockRepository, eventBus, OutboxOptions{
+		BatchSize:      cfg.Outbox.BatchSize,
+		LeaseTTL:       time.Duration(cfg.Outbox.LeaseTTL) * time.Millisecond,
+		MaxAttempts:    cfg.Outbox.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.Outbox.InitialBackoff) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.Outbox.MaxBackoff) * time.Millisecond,
+	})
+	userService := NewUserService(repository.UserRepository, repository.OutboxRepository, repository.Transactor)
 	return &Service{
-		ServerService: NewServerService(),
-		UserService:   NewUserService(repository.UserRepository),
+		ServerService:  NewServerService(healthRegistry),
+		Health:         healthRegistry,
+		UserService:    userService,
+		BatchService:   NewBatchService(userService, repository.Transactor, cfg.RestServer.Batch.MaxOperations),
+		WebhookService: webhookService,
+		EventBroker:    eventBroker,
+		EventBus:       eventBus,
+		OutboxRelay:    outboxRelay,
 	}
 }
+
+// newHealthRegistry returns the registry with the dependency checks, the
+// background workers register their heartbeats when they start.
+func newHealthRegistry(cfg config.HealthConfig) *health.Registry {
+	registry := health.NewRegistry(time.Duration(cfg.Timeout)*time.Millisecond, time.Duration(cfg.CacheTTL)*time.Millisecond)
+	registry.Register(health.Readiness, "mongodb", health.CheckerFunc(db.Ping))
+	if db.GetSQL() != nil {
+		registry.Register(health.Readiness, config.GetConfig().UserStore.Backend, health.CheckerFunc(db.PingSQL))
+	}
+	registry.Register(health.Readiness, "config", health.CheckerFunc(func(context.Context) error {
+		return config.Validate(config.GetConfig())
+	}))
+	if cfg.MinFreeDisk > 0 {
+		path := cfg.DiskPath
+		if path == "" {
+			path = "."
+		}
+		registry.Register(health.Readiness, "disk", health.DiskSpace(path, uint64(cfg.MinFreeDisk)))
+	}
+	return registry
+}
@@ -1,67 +1,113 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/validation"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
 	"github.com/taninchot-work/backend-challenge/internal/repository"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"golang.org/x/crypto/bcrypt"
-	"log"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type UserService interface {
 	GetUserByID(ctx context.Context, id string) (dto.UserGetMeResponse, error)
 	GetUserList(ctx context.Context, req dto.UserListGetRequest) (dto.UserListGetResponse, error)
+	GetUserConnection(ctx context.Context, req dto.UserConnectionRequest) (dto.UserConnectionResponse, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]dto.UserListGetResponseItem, error)
 	RegisterUser(ctx context.Context, req dto.UserRegisterRequest) (dto.UserRegisterResponse, error)
+	// ReissueRegistration answers a retried registration of the user id with a
+	// fresh access token, the user is not registered again.
+	ReissueRegistration(ctx context.Context, id string) (dto.UserRegisterResponse, error)
 	LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error)
 	UpdateUser(ctx context.Context, id string, req dto.UserUpdateRequest) (dto.UserUpdateResponse, error)
-	DeleteUser(ctx context.Context, id string) error
+	PatchUser(ctx context.Context, id string, req dto.UserPatchRequest) (dto.UserUpdateResponse, error)
+	DeleteUser(ctx context.Context, id string, req dto.UserDeleteRequest) error
+	// CreateAdmin registers a user with the admin role.
+	CreateAdmin(ctx context.Context, req dto.UserRegisterRequest) (dto.UserGetMeResponse, error)
+	// ResetPassword replaces the password of the user and revokes the user's sessions.
+	ResetPassword(ctx context.Context, id string, password string) error
+	// RevokeSessions invalidates the access tokens issued to the user so far.
+	RevokeSessions(ctx context.Context, id string) error
+	// SessionsRevokedAt returns when the sessions of the user were last revoked,
+	// zero when they never were or the user does not exist.
+	SessionsRevokedAt(ctx context.Context, id string) (time.Time, error)
+	// ExportUsers calls fn with every user, ordered by id.
+	ExportUsers(ctx context.Context, fn func(dto.UserRecord) error) error
+	// ImportUser saves an exported user with its id and password hash.
+	ImportUser(ctx context.Context, record dto.UserRecord) error
 }
 
+// exportPageSize is the number of users ExportUsers reads at once.
+const exportPageSize = 500
+
 type userServiceImpl struct {
-	userRepository repository.UserRepository
+	userRepository   repository.UserRepository
+	outboxRepository repository.OutboxRepository
+	transactor       repository.Transactor
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
+// NewUserService returns a UserService that saves an event to the outbox in the
+// same transaction as every user it creates, changes or deletes.
+func NewUserService(userRepository repository.UserRepository, outboxRepository repository.OutboxRepository, transactor repository.Transactor) UserService {
 	return &userServiceImpl{
-		userRepository: userRepository,
+		userRepository:   userRepository,
+		outboxRepository: outboxRepository,
+		transactor:       transactor,
 	}
 }
 
 func (s userServiceImpl) GetUserByID(ctx context.Context, id string) (dto.UserGetMeResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
 	user, err := s.userRepository.GetUserById(ctx, id)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			log.Println("user not found with id:", id)
-			return dto.UserGetMeResponse{}, fmt.Errorf("user with id %s not found", id)
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user not found", "id", id)
+			return dto.UserGetMeResponse{}, errUserNotFound(id)
 		}
-		log.Println("user get by id failed:", err)
-		return dto.UserGetMeResponse{}, err
+		log.ErrorContext(ctx, "user get by id failed", "error", err)
+		return dto.UserGetMeResponse{}, apperror.Internal(err)
 	}
 	if user == (entity.User{}) {
-		log.Println("user not found")
-		return dto.UserGetMeResponse{}, fmt.Errorf("user with id %s not found", id)
+		log.InfoContext(ctx, "user not found", "id", id)
+		return dto.UserGetMeResponse{}, errUserNotFound(id)
 	}
 	return dto.UserGetMeResponse{
-		ID:    user.ID.Hex(),
-		Name:  user.Name,
-		Email: user.Email,
+		ID:      user.ID.Hex(),
+		Name:    user.Name,
+		Email:   user.Email,
+		Locale:  user.Locale,
+		Version: user.Version,
 	}, nil
 }
 
 func (s userServiceImpl) GetUserList(ctx context.Context, req dto.UserListGetRequest) (dto.UserListGetResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserList")
+	defer span.End()
+
 	offset := (req.Page - 1) * req.Limit
 
-	users, err := s.userRepository.GetUserList(ctx, offset, req.Limit)
+	users, err := s.userRepository.GetUserList(ctx, entity.UserFilter{}, offset, req.Limit)
 	if err != nil {
-		log.Println("user list get failed:", err)
-		return dto.UserListGetResponse{}, err
+		log.ErrorContext(ctx, "user list get failed", "error", err)
+		return dto.UserListGetResponse{}, apperror.Internal(err)
 	}
 
 	var userResponses []dto.UserListGetResponseItem
@@ -79,63 +125,238 @@ func (s userServiceImpl) GetUserList(ctx context.Context, req dto.UserListGetReq
 	}, nil
 }
 
+// GetUserConnection returns up to req.First users after the req.After cursor.
+// Cursors are opaque to clients and encode the offset of the edge.
+func (s userServiceImpl) GetUserConnection(ctx context.Context, req dto.UserConnectionRequest) (dto.UserConnectionResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserConnection")
+	defer span.End()
+
+	if req.First < 1 || req.First > maxConnectionSize {
+		return dto.UserConnectionResponse{}, errInvalidConnectionSize(req.First)
+	}
+	offset := 0
+	if req.After != "" {
+		after, err := decodeCursor(req.After)
+		if err != nil {
+			return dto.UserConnectionResponse{}, errInvalidCursor
+		}
+		offset = after + 1
+	}
+
+	filter := entity.UserFilter{Name: req.Filter.Name, Email: strings.ToLower(req.Filter.Email)}
+	// one extra user tells whether there is a next page
+	users, err := s.userRepository.GetUserList(ctx, filter, offset, req.First+1)
+	if err != nil {
+		log.ErrorContext(ctx, "user connection get failed", "error", err)
+		return dto.UserConnectionResponse{}, apperror.Internal(err)
+	}
+
+	response := dto.UserConnectionResponse{Edges: []dto.UserEdge{}}
+	if len(users) > req.First {
+		users = users[:req.First]
+		response.PageInfo.HasNextPage = true
+	}
+	for i, user := range users {
+		response.Edges = append(response.Edges, dto.UserEdge{
+			Cursor: encodeCursor(offset + i),
+			Node: dto.UserListGetResponseItem{
+				ID:    user.ID.Hex(),
+				Name:  user.Name,
+				Email: user.Email,
+			},
+		})
+	}
+	if len(response.Edges) > 0 {
+		response.PageInfo.EndCursor = response.Edges[len(response.Edges)-1].Cursor
+	}
+	return response, nil
+}
+
+// GetUsersByIDs loads several users in one repository call, unknown ids are left out.
+func (s userServiceImpl) GetUsersByIDs(ctx context.Context, ids []string) ([]dto.UserListGetResponseItem, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsersByIDs")
+	defer span.End()
+
+	users, err := s.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		log.ErrorContext(ctx, "users get by ids failed", "error", err)
+		return nil, apperror.Internal(err)
+	}
+	items := make([]dto.UserListGetResponseItem, 0, len(users))
+	for _, user := range users {
+		items = append(items, dto.UserListGetResponseItem{
+			ID:    user.ID.Hex(),
+			Name:  user.Name,
+			Email: user.Email,
+		})
+	}
+	return items, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := strings.CutPrefix(string(decoded), "offset:")
+	if !ok {
+		return 0, errors.New("malformed cursor")
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, errors.New("malformed cursor")
+	}
+	return offset, nil
+}
+
 func (s userServiceImpl) RegisterUser(ctx context.Context, req dto.UserRegisterRequest) (dto.UserRegisterResponse, error) {
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	ctx, span := tracing.Start(ctx, "UserService.RegisterUser")
+	defer span.End()
+
+	createdUser, err := s.createUser(ctx, req, "")
 	if err != nil {
 		return dto.UserRegisterResponse{}, err
 	}
+
+	accessToken, err := jwt.GenerateJwt(createdUser.ID.Hex(), createdUser.Locale, createdUser.Role)
+	if err != nil {
+		log.ErrorContext(ctx, "user register failed to generate access token", "error", err)
+		return dto.UserRegisterResponse{}, apperror.Internal(err)
+	}
+	return dto.UserRegisterResponse{
+		ID:          createdUser.ID.Hex(),
+		Name:        createdUser.Name,
+		Email:       createdUser.Email,
+		AccessToken: accessToken,
+	}, nil
+}
+
+func (s userServiceImpl) ReissueRegistration(ctx context.Context, id string) (dto.UserRegisterResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.ReissueRegistration")
+	defer span.End()
+
+	user, err := s.getUser(ctx, id, "registration reissue")
+	if err != nil {
+		return dto.UserRegisterResponse{}, err
+	}
+
+	accessToken, err := jwt.GenerateJwt(user.ID.Hex(), user.Locale, user.Role)
+	if err != nil {
+		log.ErrorContext(ctx, "user registration reissue failed to generate access token", "error", err)
+		return dto.UserRegisterResponse{}, apperror.Internal(err)
+	}
+	return dto.UserRegisterResponse{
+		ID:          user.ID.Hex(),
+		Name:        user.Name,
+		Email:       user.Email,
+		AccessToken: accessToken,
+	}, nil
+}
+
+func (s userServiceImpl) CreateAdmin(ctx context.Context, req dto.UserRegisterRequest) (dto.UserGetMeResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateAdmin")
+	defer span.End()
+
+	createdUser, err := s.createUser(ctx, req, entity.RoleAdmin)
+	if err != nil {
+		return dto.UserGetMeResponse{}, err
+	}
+	log.InfoContext(ctx, "admin created", "id", createdUser.ID.Hex())
+	return dto.UserGetMeResponse{
+		ID:      createdUser.ID.Hex(),
+		Name:    createdUser.Name,
+		Email:   createdUser.Email,
+		Locale:  createdUser.Locale,
+		Version: createdUser.Version,
+	}, nil
+}
+
+// createUser saves a new user with role, empty for a plain user, and the
+// created event in one transaction.
+func (s userServiceImpl) createUser(ctx context.Context, req dto.UserRegisterRequest, role string) (entity.User, error) {
+	hashPassword, err := hashPassword(ctx, req.Password)
+	if err != nil {
+		return entity.User{}, apperror.Internal(err)
+	}
 	user := entity.User{
 		ID:        bson.NewObjectID(),
 		Name:      req.Name,
 		Email:     req.Email,
-		Password:  string(hashPassword),
+		Password:  hashPassword,
+		Locale:    req.Locale,
+		Role:      role,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
+		Version:   1,
 	}
-	createdUser, err := s.userRepository.SaveUser(ctx, user)
+	var createdUser entity.User
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdUser, err = s.userRepository.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return s.raise(ctx, entity.EventUserCreated, createdUser)
+	})
 	if err != nil {
-		if mongo.IsDuplicateKeyError(err) {
-			log.Println("user register failed due to duplicate email:", err)
-			return dto.UserRegisterResponse{}, fmt.Errorf("email %s is already registered", req.Email)
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			log.InfoContext(ctx, "user register failed due to duplicate email")
+			return entity.User{}, errEmailAlreadyExists(req.Email)
 		}
-		return dto.UserRegisterResponse{}, err
+		log.ErrorContext(ctx, "user register failed", "error", err)
+		return entity.User{}, apperror.Internal(err)
 	}
-	accessToken, err := jwt.GenerateJwt(createdUser.ID.Hex())
-	return dto.UserRegisterResponse{
-		ID:          createdUser.ID.Hex(),
-		Name:        createdUser.Name,
-		Email:       createdUser.Email,
-		AccessToken: accessToken,
-	}, nil
+	return createdUser, nil
+}
+
+func hashPassword(ctx context.Context, password string) (string, error) {
+	_, hashSpan := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer hashSpan.End()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
 }
 
 func (s userServiceImpl) LoginUser(ctx context.Context, req dto.UserLoginRequest) (dto.UserLoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.LoginUser")
+	defer span.End()
+
 	// check if user exists
 	user, err := s.userRepository.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			log.Println("user login failed user not found with email:", req.Email)
-			return dto.UserLoginResponse{}, fmt.Errorf("invalid email or password")
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user login failed, no user with the email")
+			metrics.ObserveLogin(false)
+			return dto.UserLoginResponse{}, ErrInvalidCredentials
 		}
-		log.Println("user login failed to get user by email:", err)
-		return dto.UserLoginResponse{}, err
+		log.ErrorContext(ctx, "user login failed to get user by email", "error", err)
+		return dto.UserLoginResponse{}, apperror.Internal(err)
 	}
 
 	if user == (entity.User{}) {
-		log.Println("user login user not found")
+		log.InfoContext(ctx, "user login failed, no user with the email")
+		metrics.ObserveLogin(false)
 		return dto.UserLoginResponse{}, nil
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		log.Println("user login password mismatch:", err)
-		return dto.UserLoginResponse{}, fmt.Errorf("invalid email or password")
+	_, compareSpan := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	compareSpan.End()
+	if err != nil {
+		log.InfoContext(ctx, "user login failed, password mismatch", "id", user.ID.Hex())
+		metrics.ObserveLogin(false)
+		return dto.UserLoginResponse{}, ErrInvalidCredentials
 	}
-	accessToken, err := jwt.GenerateJwt(user.ID.Hex())
+	accessToken, err := jwt.GenerateJwt(user.ID.Hex(), user.Locale, user.Role)
 	if err != nil {
-		log.Println("user login failed to generate access token:", err)
-		return dto.UserLoginResponse{}, err
+		log.ErrorContext(ctx, "user login failed to generate access token", "error", err)
+		return dto.UserLoginResponse{}, apperror.Internal(err)
 	}
 
+	metrics.ObserveLogin(true)
 	return dto.UserLoginResponse{
 		ID:          user.ID.Hex(),
 		Name:        user.Name,
@@ -145,56 +366,408 @@ func (s userServiceImpl) LoginUser(ctx context.Context, req dto.UserLoginRequest
 }
 
 func (s userServiceImpl) UpdateUser(ctx context.Context, id string, req dto.UserUpdateRequest) (dto.UserUpdateResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
 	user, err := s.userRepository.GetUserById(ctx, id)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			log.Println("user update failed user not found with id:", id)
-			return dto.UserUpdateResponse{}, fmt.Errorf("user with id %s not found", id)
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user update failed, user not found", "id", id)
+			return dto.UserUpdateResponse{}, errUserNotFound(id)
 		}
-		log.Println("user update failed:", err)
-		return dto.UserUpdateResponse{}, err
+		log.ErrorContext(ctx, "user update failed", "error", err)
+		return dto.UserUpdateResponse{}, apperror.Internal(err)
 	}
 
 	if user == (entity.User{}) {
-		log.Println("user update user not found")
-		return dto.UserUpdateResponse{}, fmt.Errorf("user with id %s not found", id)
+		log.InfoContext(ctx, "user update failed, user not found", "id", id)
+		return dto.UserUpdateResponse{}, errUserNotFound(id)
 	}
 
-	user.Name = req.Name
-	user.Email = req.Email
+	return s.updateUserFields(ctx, user, req)
+}
 
-	updatedUser, err := s.userRepository.UpdateUser(ctx, user)
+func (s userServiceImpl) PatchUser(ctx context.Context, id string, req dto.UserPatchRequest) (dto.UserUpdateResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.PatchUser")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserById(ctx, id)
 	if err != nil {
-		log.Println("user update failed:", err)
-		if mongo.IsDuplicateKeyError(err) {
-			log.Println("user update failed due to duplicate email:", err)
-			return dto.UserUpdateResponse{}, fmt.Errorf("email %s is already exists", req.Email)
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user patch failed, user not found", "id", id)
+			return dto.UserUpdateResponse{}, errUserNotFound(id)
 		}
+		log.ErrorContext(ctx, "user patch failed", "error", err)
+		return dto.UserUpdateResponse{}, apperror.Internal(err)
+	}
+
+	if user == (entity.User{}) {
+		log.InfoContext(ctx, "user patch failed, user not found", "id", id)
+		return dto.UserUpdateResponse{}, errUserNotFound(id)
+	}
+
+	original, err := json.Marshal(dto.UserPatchDocument{
+		ID:        user.ID.Hex(),
+		Name:      user.Name,
+		Email:     user.Email,
+		Locale:    user.Locale,
+		CreatedAt: user.CreatedAt,
+	})
+	if err != nil {
+		return dto.UserUpdateResponse{}, apperror.Internal(err)
+	}
+
+	patched, err := patch.Apply(req.ContentType, original, req.Patch)
+	if err != nil {
+		log.InfoContext(ctx, "user patch failed to apply patch", "error", err)
+		return dto.UserUpdateResponse{}, errInvalidPatch(err)
+	}
+
+	if err := checkPatchedFields(original, patched); err != nil {
 		return dto.UserUpdateResponse{}, err
 	}
 
+	var updateReq dto.UserUpdateRequest
+	if err := json.Unmarshal(patched, &updateReq); err != nil {
+		return dto.UserUpdateResponse{}, errInvalidPatch(err)
+	}
+
+	// validate the patched user against the same rules as a full update
+	if err := validation.Struct(updateReq); err != nil {
+		return dto.UserUpdateResponse{}, err
+	}
+	updateReq.Email = strings.ToLower(updateReq.Email)
+	updateReq.IfMatch = req.IfMatch
+	// the original has the locale, a patch without it removed it, e.g. with {"locale":null}
+	updateReq.ClearLocale = updateReq.Locale == ""
+
+	return s.updateUserFields(ctx, user, updateReq)
+}
+
+// checkPatchedFields rejects patches that change immutable fields or introduce unknown ones.
+func checkPatchedFields(original []byte, patched []byte) error {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return errInvalidPatch(errors.New("patched document must be a JSON object"))
+	}
+
+	var fields []apperror.FieldError
+	for _, field := range []string{"id", "createdAt"} {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			fields = append(fields, immutableFieldError(field))
+		}
+	}
+	for field := range after {
+		switch field {
+		case "id", "createdAt", "name", "email", "locale":
+		case "password":
+			fields = append(fields, immutableFieldError(field))
+		default:
+			fields = append(fields, apperror.FieldError{
+				Field:   field,
+				Code:    apperror.CodeFieldUnknown,
+				Message: fmt.Sprintf("unknown field %s", field),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return errInvalidPatch(errors.New("patch changes fields that cannot be changed"), fields...)
+	}
+	return nil
+}
+
+func immutableFieldError(field string) apperror.FieldError {
+	return apperror.FieldError{
+		Field:   field,
+		Code:    apperror.CodeFieldImmutable,
+		Message: fmt.Sprintf("field %s is immutable", field),
+	}
+}
+
+// updateUserFields writes only the fields of req that differ from the stored user,
+// guarded by the version that was read so concurrent edits are not silently overwritten.
+func (s userServiceImpl) updateUserFields(ctx context.Context, user entity.User, req dto.UserUpdateRequest) (dto.UserUpdateResponse, error) {
+	if !etag.PreconditionMet(req.IfMatch, user.Version) {
+		log.InfoContext(ctx, "user update precondition failed", "id", user.ID.Hex())
+		return dto.UserUpdateResponse{}, ErrPreconditionFailed
+	}
+
+	var update entity.UserUpdate
+	if req.Name != user.Name {
+		update.Name = &req.Name
+	}
+	if req.Email != user.Email {
+		update.Email = &req.Email
+	}
+	if req.Locale != "" && req.Locale != user.Locale || req.ClearLocale && user.Locale != "" {
+		update.Locale = &req.Locale
+	}
+	if update.IsEmpty() {
+		return dto.UserUpdateResponse{
+			ID:      user.ID.Hex(),
+			Name:    user.Name,
+			Email:   user.Email,
+			Locale:  user.Locale,
+			Version: user.Version,
+		}, nil
+	}
+
+	var updatedUser entity.User
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updatedUser, err = s.userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, update)
+		if err != nil {
+			return err
+		}
+		return s.raise(ctx, entity.EventUserUpdated, updatedUser)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			log.InfoContext(ctx, "user update failed, version conflict", "id", user.ID.Hex())
+			if req.IfMatch != "" {
+				return dto.UserUpdateResponse{}, ErrPreconditionFailed
+			}
+			return dto.UserUpdateResponse{}, ErrConcurrentModification
+		}
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			log.InfoContext(ctx, "user update failed due to duplicate email")
+			return dto.UserUpdateResponse{}, errEmailAlreadyExists(req.Email)
+		}
+		if isNotFound(err) {
+			// deleted since it was read
+			log.InfoContext(ctx, "user update failed, user not found", "id", user.ID.Hex())
+			return dto.UserUpdateResponse{}, errUserNotFound(user.ID.Hex())
+		}
+		log.ErrorContext(ctx, "user update failed", "error", err)
+		return dto.UserUpdateResponse{}, apperror.Internal(err)
+	}
+
 	return dto.UserUpdateResponse{
-		ID:    updatedUser.ID.Hex(),
-		Name:  updatedUser.Name,
-		Email: updatedUser.Email,
+		ID:      updatedUser.ID.Hex(),
+		Name:    updatedUser.Name,
+		Email:   updatedUser.Email,
+		Locale:  updatedUser.Locale,
+		Version: updatedUser.Version,
 	}, nil
 }
 
-func (s userServiceImpl) DeleteUser(ctx context.Context, id string) error {
+func (s userServiceImpl) DeleteUser(ctx context.Context, id string, req dto.UserDeleteRequest) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
 	user, err := s.userRepository.GetUserById(ctx, id)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			log.Println("user delete failed user not found with id:", id)
-			return fmt.Errorf("user with id %s not found", id)
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user delete failed, user not found", "id", id)
+			return errUserNotFound(id)
 		}
-		log.Println("user delete failed:", err)
+		log.ErrorContext(ctx, "user delete failed", "error", err)
+		return apperror.Internal(err)
+	}
+
+	if !etag.PreconditionMet(req.IfMatch, user.Version) {
+		log.InfoContext(ctx, "user delete precondition failed", "id", id)
+		return ErrPreconditionFailed
+	}
+
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.DeleteUser(ctx, user.ID.Hex(), user.Version); err != nil {
+			return err
+		}
+		return s.raise(ctx, entity.EventUserDeleted, user)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			log.InfoContext(ctx, "user delete failed, version conflict", "id", id)
+			if req.IfMatch != "" {
+				return ErrPreconditionFailed
+			}
+			return ErrConcurrentModification
+		}
+		if isNotFound(err) {
+			// deleted since it was read
+			log.InfoContext(ctx, "user delete failed, user not found", "id", id)
+			return errUserNotFound(id)
+		}
+		log.ErrorContext(ctx, "user delete failed", "error", err)
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+func (s userServiceImpl) ResetPassword(ctx context.Context, id string, password string) error {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	user, err := s.getUser(ctx, id, "reset password")
+	if err != nil {
 		return err
 	}
+	hash, err := hashPassword(ctx, password)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	now := time.Now()
+	update := entity.UserUpdate{Password: &hash, SessionsRevokedAt: &now}
+	err = s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		updatedUser, err := s.userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, update)
+		if err != nil {
+			return err
+		}
+		return s.raise(ctx, entity.EventUserUpdated, updatedUser)
+	})
+	if err != nil {
+		return updateError(ctx, "reset password", id, err)
+	}
+	log.InfoContext(ctx, "password reset", "id", id)
+	return nil
+}
 
-	err = s.userRepository.DeleteUser(ctx, user.ID.Hex())
+func (s userServiceImpl) RevokeSessions(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeSessions")
+	defer span.End()
+
+	user, err := s.getUser(ctx, id, "revoke sessions")
 	if err != nil {
-		log.Println("user delete failed:", err)
 		return err
 	}
+	// the user's data does not change, there is no event
+	now := time.Now()
+	if _, err := s.userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, entity.UserUpdate{SessionsRevokedAt: &now}); err != nil {
+		return updateError(ctx, "revoke sessions", id, err)
+	}
+	log.InfoContext(ctx, "sessions revoked", "id", id)
+	return nil
+}
+
+func (s userServiceImpl) SessionsRevokedAt(ctx context.Context, id string) (time.Time, error) {
+	user, err := s.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return user.SessionsRevokedAt, nil
+}
+
+func (s userServiceImpl) ExportUsers(ctx context.Context, fn func(dto.UserRecord) error) error {
+	ctx, span := tracing.Start(ctx, "UserService.ExportUsers")
+	defer span.End()
+
+	for offset := 0; ; offset += exportPageSize {
+		users, err := s.userRepository.GetUserList(ctx, entity.UserFilter{}, offset, exportPageSize)
+		if err != nil {
+			log.ErrorContext(ctx, "user export failed", "error", err)
+			return apperror.Internal(err)
+		}
+		for _, user := range users {
+			if err := fn(dto.UserRecord{
+				ID:           user.ID.Hex(),
+				Name:         user.Name,
+				Email:        user.Email,
+				PasswordHash: user.Password,
+				Locale:       user.Locale,
+				Role:         user.Role,
+				CreatedAt:    user.CreatedAt,
+				UpdatedAt:    user.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(users) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+func (s userServiceImpl) ImportUser(ctx context.Context, record dto.UserRecord) error {
+	ctx, span := tracing.Start(ctx, "UserService.ImportUser")
+	defer span.End()
+
+	if err := validation.Struct(record); err != nil {
+		return err
+	}
+	if _, err := bcrypt.Cost([]byte(record.PasswordHash)); err != nil {
+		return apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+			Field:   "passwordHash",
+			Code:    apperror.CodeFieldInvalid,
+			Message: "passwordHash must be a bcrypt hash",
+		})
+	}
+	id := bson.NewObjectID()
+	if record.ID != "" {
+		var err error
+		if id, err = bson.ObjectIDFromHex(record.ID); err != nil {
+			return apperror.Validation(apperror.CodeValidationFailed, "validation failed", apperror.FieldError{
+				Field:   "id",
+				Code:    apperror.CodeFieldInvalid,
+				Message: "id must be an ObjectID",
+			})
+		}
+	}
+	now := time.Now()
+	user := entity.User{
+		ID:        id,
+		Name:      record.Name,
+		Email:     strings.ToLower(record.Email),
+		Password:  record.PasswordHash,
+		Locale:    record.Locale,
+		Role:      record.Role,
+		CreatedAt: cmp.Or(record.CreatedAt, now),
+		UpdatedAt: cmp.Or(record.UpdatedAt, now),
+		Version:   1,
+	}
+	err := s.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		createdUser, err := s.userRepository.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return s.raise(ctx, entity.EventUserCreated, createdUser)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			log.InfoContext(ctx, "user import failed due to duplicate email or id", "id", user.ID.Hex())
+			return errEmailAlreadyExists(user.Email)
+		}
+		log.ErrorContext(ctx, "user import failed", "error", err)
+		return apperror.Internal(err)
+	}
 	return nil
 }
+
+// getUser returns the user id for operation, or the not found error.
+func (s userServiceImpl) getUser(ctx context.Context, id string, operation string) (entity.User, error) {
+	user, err := s.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			log.InfoContext(ctx, "user "+operation+" failed, user not found", "id", id)
+			return entity.User{}, errUserNotFound(id)
+		}
+		log.ErrorContext(ctx, "user "+operation+" failed", "error", err)
+		return entity.User{}, apperror.Internal(err)
+	}
+	return user, nil
+}
+
+// updateError maps the error of an update of the user id for operation.
+func updateError(ctx context.Context, operation string, id string, err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		log.InfoContext(ctx, "user "+operation+" failed, version conflict", "id", id)
+		return ErrConcurrentModification
+	}
+	if isNotFound(err) {
+		return errUserNotFound(id)
+	}
+	log.ErrorContext(ctx, "user "+operation+" failed", "error", err)
+	return apperror.Internal(err)
+}
+
+// raise saves the event of a change to user to the outbox, call it in the
+// transaction of the change so the event is kept exactly when the change is.
+func (s userServiceImpl) raise(ctx context.Context, eventType string, user entity.User) error {
+	return s.outboxRepository.SaveEvent(ctx, newUserEvent(eventType, user))
+}
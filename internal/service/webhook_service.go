@@ -0,0 +1,395 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/core/webhook"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults used for the zero fields of WebhookOptions.
+const (
+	defaultWebhookTimeout        = 10 * time.Second
+	defaultWebhookMaxAttempts    = 8
+	defaultWebhookInitialBackoff = 10 * time.Second
+	defaultWebhookMaxBackoff     = time.Hour
+	defaultWebhookBatchSize      = 50
+
+	// maxDeliveryHistory bounds the attempts kept in the delivery log.
+	maxDeliveryHistory = 50
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req dto.WebhookCreateRequest) (dto.WebhookResponse, error)
+	GetWebhookList(ctx context.Context) (dto.WebhookListResponse, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	GetDeliveryList(ctx context.Context, req dto.WebhookDeliveryListRequest) (dto.WebhookDeliveryListResponse, error)
+	RedeliverDelivery(ctx context.Context, id string) (dto.WebhookDeliveryResponse, error)
+	// Publish queues a delivery of event for every webhook subscribed to its type.
+	Publish(ctx context.Context, event entity.Event) error
+	// DeliverDue sends the deliveries that are due and returns how many were attempted.
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// WebhookOptions configures how deliveries are sent and retried.
+type WebhookOptions struct {
+	// Timeout bounds one request to a webhook URL.
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery is dead.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BatchSize is the most deliveries sent by one DeliverDue call.
+	BatchSize int
+	// AllowPrivateNetworks lets deliveries reach loopback, private and
+	// link-local addresses, only set it for receivers on a trusted network.
+	AllowPrivateNetworks bool
+}
+
+type webhookServiceImpl struct {
+	webhookRepository repository.WebhookRepository
+	client            *http.Client
+	options           WebhookOptions
+}
+
+func NewWebhookService(webhookRepository repository.WebhookRepository, options WebhookOptions) WebhookService {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultWebhookTimeout
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = defaultWebhookInitialBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultWebhookMaxBackoff
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultWebhookBatchSize
+	}
+	return &webhookServiceImpl{
+		webhookRepository: webhookRepository,
+		client:            webhook.NewClient(options.Timeout, options.AllowPrivateNetworks),
+		options:           options,
+	}
+}
+
+func (s webhookServiceImpl) CreateWebhook(ctx context.Context, req dto.WebhookCreateRequest) (dto.WebhookResponse, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return dto.WebhookResponse{}, apperror.Internal(err)
+		}
+		secret = generated
+	}
+
+	now := time.Now()
+	created, err := s.webhookRepository.SaveWebhook(ctx, entity.Webhook{
+		ID:        bson.NewObjectID(),
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "webhook create failed", "error", err)
+		return dto.WebhookResponse{}, apperror.Internal(err)
+	}
+
+	response := toWebhookResponse(created)
+	response.Secret = created.Secret
+	return response, nil
+}
+
+func (s webhookServiceImpl) GetWebhookList(ctx context.Context) (dto.WebhookListResponse, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetWebhookList")
+	defer span.End()
+
+	webhooks, err := s.webhookRepository.GetWebhookList(ctx)
+	if err != nil {
+		log.ErrorContext(ctx, "webhook list get failed", "error", err)
+		return dto.WebhookListResponse{}, apperror.Internal(err)
+	}
+	response := dto.WebhookListResponse{Webhooks: make([]dto.WebhookResponse, 0, len(webhooks))}
+	for _, hook := range webhooks {
+		response.Webhooks = append(response.Webhooks, toWebhookResponse(hook))
+	}
+	return response, nil
+}
+
+func (s webhookServiceImpl) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	if err := s.webhookRepository.DeleteWebhook(ctx, id); err != nil {
+		if isNotFound(err) {
+			return errWebhookNotFound(id)
+		}
+		log.ErrorContext(ctx, "webhook delete failed", "error", err)
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+func (s webhookServiceImpl) GetDeliveryList(ctx context.Context, req dto.WebhookDeliveryListRequest) (dto.WebhookDeliveryListResponse, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetDeliveryList")
+	defer span.End()
+
+	filter := entity.WebhookDeliveryFilter{WebhookID: req.WebhookID, Status: req.Status}
+	deliveries, err := s.webhookRepository.GetDeliveryList(ctx, filter, (req.Page-1)*req.Limit, req.Limit)
+	if err != nil {
+		log.ErrorContext(ctx, "webhook delivery list get failed", "error", err)
+		return dto.WebhookDeliveryListResponse{}, apperror.Internal(err)
+	}
+	response := dto.WebhookDeliveryListResponse{
+		Deliveries: make([]dto.WebhookDeliveryResponse, 0, len(deliveries)),
+		Page:       req.Page,
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, toDeliveryResponse(delivery))
+	}
+	return response, nil
+}
+
+// RedeliverDelivery queues the delivery again with a fresh set of attempts,
+// whatever its status. Its history is kept.
+func (s webhookServiceImpl) RedeliverDelivery(ctx context.Context, id string) (dto.WebhookDeliveryResponse, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.RedeliverDelivery")
+	defer span.End()
+
+	delivery, err := s.webhookRepository.GetDeliveryById(ctx, id)
+	if err != nil {
+		if isNotFound(err) {
+			return dto.WebhookDeliveryResponse{}, errDeliveryNotFound(id)
+		}
+		log.ErrorContext(ctx, "webhook redelivery failed", "error", err)
+		return dto.WebhookDeliveryResponse{}, apperror.Internal(err)
+	}
+	if _, err := s.webhookRepository.GetWebhookById(ctx, delivery.WebhookID.Hex()); err != nil {
+		if isNotFound(err) {
+			return dto.WebhookDeliveryResponse{}, errWebhookNotFound(delivery.WebhookID.Hex())
+		}
+		log.ErrorContext(ctx, "webhook redelivery failed", "error", err)
+		return dto.WebhookDeliveryResponse{}, apperror.Internal(err)
+	}
+
+	now := time.Now()
+	delivery.Status = entity.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	if err := s.webhookRepository.UpdateDelivery(ctx, delivery); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return dto.WebhookDeliveryResponse{}, errDeliveryNotFound(id)
+		}
+		log.ErrorContext(ctx, "webhook redelivery failed", "error", err)
+		return dto.WebhookDeliveryResponse{}, apperror.Internal(err)
+	}
+	return toDeliveryResponse(delivery), nil
+}
+
+func (s webhookServiceImpl) Publish(ctx context.Context, event entity.Event) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.Publish")
+	defer span.End()
+
+	webhooks, err := s.webhookRepository.GetWebhooksByEvent(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhooks))
+	for _, hook := range webhooks {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			ID:            bson.NewObjectID(),
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        entity.DeliveryStatusPending,
+			NextAttemptAt: now,
+			History:       []entity.WebhookAttempt{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	return s.webhookRepository.SaveDeliveries(ctx, deliveries)
+}
+
+// DeliverDue claims and sends due deliveries one by one until none is due or
+// BatchSize were sent. A claimed delivery is hidden from other workers for
+// twice the timeout, so a worker that dies mid-send only delays it.
+func (s webhookServiceImpl) DeliverDue(ctx context.Context) (int, error) {
+	lease := 2 * s.options.Timeout
+
+	sent := 0
+	for sent < s.options.BatchSize {
+		delivery, err := s.webhookRepository.ClaimDueDelivery(ctx, time.Now(), lease)
+		if errors.Is(err, repository.ErrNotFound) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+		if err := s.deliver(ctx, delivery); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s webhookServiceImpl) deliver(ctx context.Context, delivery entity.WebhookDelivery) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.deliver")
+	defer span.End()
+
+	hook, err := s.webhookRepository.GetWebhookById(ctx, delivery.WebhookID.Hex())
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	// a delivery of a deleted webhook goes straight to the dead letters
+	deleted := err != nil
+	var attempt entity.WebhookAttempt
+	if deleted {
+		attempt = entity.WebhookAttempt{At: time.Now(), Error: "webhook was deleted"}
+	} else {
+		attempt = s.send(ctx, hook, delivery)
+	}
+
+	delivery.Attempts++
+	delivery.History = append(delivery.History, attempt)
+	if len(delivery.History) > maxDeliveryHistory {
+		delivery.History = delivery.History[len(delivery.History)-maxDeliveryHistory:]
+	}
+	delivery.UpdatedAt = time.Now()
+	switch {
+	case attempt.Error == "":
+		delivery.Status = entity.DeliveryStatusSucceeded
+	case deleted || delivery.Attempts >= s.options.MaxAttempts:
+		log.WarnContext(ctx, "webhook delivery is dead", "delivery_id", delivery.ID.Hex(), "attempts", delivery.Attempts, "error", attempt.Error)
+		delivery.Status = entity.DeliveryStatusDead
+	default:
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(webhook.Backoff(delivery.Attempts, s.options.InitialBackoff, s.options.MaxBackoff))
+	}
+	return s.webhookRepository.UpdateDelivery(ctx, delivery)
+}
+
+// send posts the payload of delivery to the webhook. Any 2xx response is a success.
+func (s webhookServiceImpl) send(ctx context.Context, hook entity.Webhook, delivery entity.WebhookDelivery) entity.WebhookAttempt {
+	start := time.Now()
+	attempt := entity.WebhookAttempt{At: start}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "backend-challenge-webhooks/1.0")
+	req.Header.Set(webhook.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook.HeaderEventID, delivery.EventID)
+	req.Header.Set(webhook.HeaderDelivery, delivery.ID.Hex())
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(hook.Secret, start, delivery.Payload))
+
+	ctx, span := tracing.Start(ctx, "POST", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.HTTPRequestMethodKey.String(http.MethodPost),
+		semconv.ServerAddress(req.URL.Hostname()),
+	))
+	defer span.End()
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	req = req.WithContext(ctx)
+
+	resp, err := s.client.Do(req)
+	attempt.Duration = time.Since(start)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	// drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func toWebhookResponse(hook entity.Webhook) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:        hook.ID.Hex(),
+		URL:       hook.URL,
+		Events:    hook.Events,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+func toDeliveryResponse(delivery entity.WebhookDelivery) dto.WebhookDeliveryResponse {
+	response := dto.WebhookDeliveryResponse{
+		ID:        delivery.ID.Hex(),
+		WebhookID: delivery.WebhookID.Hex(),
+		EventID:   delivery.EventID,
+		Event:     delivery.EventType,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+		History:   make([]dto.WebhookAttemptResponse, 0, len(delivery.History)),
+		CreatedAt: delivery.CreatedAt,
+		UpdatedAt: delivery.UpdatedAt,
+	}
+	if delivery.Status == entity.DeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	for _, attempt := range delivery.History {
+		response.History = append(response.History, dto.WebhookAttemptResponse{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return response
+}
@@ -0,0 +1,228 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_transactor "github.com/taninchot-work/backend-challenge/internal/repository/mocks/transactor_mock"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_batch_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/batch_service_mock"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errUserNotFound = apperror.NotFound(apperror.CodeUserNotFound, "user not found")
+
+func runInTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestExecuteBatchRunsEveryOperation(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserService := mock_user_service.NewUserService(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockUserService.On("GetUserByID", ctx, "683ecde861d005de5ec0907d").
+		Return(dto.UserGetMeResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com", Version: 2}, nil)
+	mockUserService.On("DeleteUser", ctx, "683ecde861d005de5ec0907e", dto.UserDeleteRequest{IfMatch: `"1"`}).Return(errUserNotFound)
+	batchService := service.NewBatchService(mockUserService, mockTransactor, 10)
+
+	// When
+	result, err := batchService.ExecuteBatch(ctx, dto.BatchRequest{Operations: []dto.BatchOperation{
+		{ID: "get", Method: dto.BatchMethodGet, UserID: "683ecde861d005de5ec0907d"},
+		{ID: "create", Method: dto.BatchMethodCreate, User: &dto.BatchUser{Name: "New User", Email: "invalid", Password: "password123"}},
+		{ID: "delete", Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907e", IfMatch: `"1"`},
+		{ID: "update", Method: dto.BatchMethodUpdate, User: &dto.BatchUser{Name: "New User", Email: "new@example.com"}},
+	}})
+
+	// Then
+	assert.NoError(t, err)
+	assert.False(t, result.RolledBack)
+	assert.Len(t, result.Results, 4)
+	assert.NoError(t, result.Results[0].Err)
+	assert.Equal(t, "Test User", result.Results[0].User.Name)
+	assert.Equal(t, apperror.CodeFieldInvalidEmail, apperror.From(result.Results[1].Err).Fields[0].Code)
+	assert.Equal(t, errUserNotFound, result.Results[2].Err)
+	assert.Equal(t, "userId", apperror.From(result.Results[3].Err).Fields[0].Field)
+	mockUserService.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything)
+	mockTransactor.AssertNotCalled(t, "WithTransaction", mock.Anything, mock.Anything)
+}
+
+func TestExecuteBatchTooLarge(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	batchService := service.NewBatchService(mockUserService, mock_transactor.NewTransactor(t), 2)
+	operation := dto.BatchOperation{Method: dto.BatchMethodGet, UserID: "683ecde861d005de5ec0907d"}
+
+	// When
+	_, err := batchService.ExecuteBatch(context.Background(), dto.BatchRequest{Operations: []dto.BatchOperation{operation, operation, operation}})
+
+	// Then
+	appErr := apperror.From(err)
+	assert.Equal(t, apperror.KindValidation, appErr.Kind)
+	assert.Equal(t, apperror.FieldError{Field: "operations", Code: apperror.CodeFieldTooLarge, Message: "operations must be at most 2", Params: map[string]string{"param": "2"}}, appErr.Fields[0])
+	mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestExecuteBatchAtomicRollsBack(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserService := mock_user_service.NewUserService(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(runInTransaction)
+	mockUserService.On("UpdateUser", ctx, "683ecde861d005de5ec0907d", dto.UserUpdateRequest{Name: "New Name", Email: "new@example.com"}).
+		Return(dto.UserUpdateResponse{ID: "683ecde861d005de5ec0907d", Name: "New Name", Email: "new@example.com", Version: 3}, nil)
+	mockUserService.On("DeleteUser", ctx, "683ecde861d005de5ec0907e", dto.UserDeleteRequest{}).Return(errUserNotFound)
+	batchService := service.NewBatchService(mockUserService, mockTransactor, 10)
+
+	// When
+	result, err := batchService.ExecuteBatch(ctx, dto.BatchRequest{Atomic: true, Operations: []dto.BatchOperation{
+		{ID: "update", Method: dto.BatchMethodUpdate, UserID: "683ecde861d005de5ec0907d", User: &dto.BatchUser{Name: "New Name", Email: "New@Example.com"}},
+		{ID: "delete", Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907e"},
+		{ID: "get", Method: dto.BatchMethodGet, UserID: "683ecde861d005de5ec0907d"},
+	}})
+
+	// Then
+	assert.NoError(t, err)
+	assert.True(t, result.RolledBack)
+	assert.Equal(t, "update", result.Results[0].ID)
+	assert.Nil(t, result.Results[0].User)
+	assert.Equal(t, apperror.CodeBatchRolledBack, apperror.From(result.Results[0].Err).Code)
+	assert.Equal(t, errUserNotFound, result.Results[1].Err)
+	assert.Equal(t, apperror.CodeBatchRolledBack, apperror.From(result.Results[2].Err).Code)
+	mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestExecuteBatchAtomicRetriesTransientTransactionError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserService := mock_user_service.NewUserService(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	attempts := 0
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		// like a MongoDB session, retry while fn fails with a transient error
+		for {
+			attempts++
+			if err := fn(ctx); !repository.IsTransient(err) {
+				return err
+			}
+		}
+	})
+	writeConflict := apperror.Internal(mongo.CommandError{Code: 112, Name: "WriteConflict", Labels: []string{"TransientTransactionError"}})
+	mockUserService.On("DeleteUser", ctx, "683ecde861d005de5ec0907e", dto.UserDeleteRequest{}).Return(writeConflict).Once()
+	mockUserService.On("DeleteUser", ctx, "683ecde861d005de5ec0907e", dto.UserDeleteRequest{}).Return(nil).Once()
+	batchService := service.NewBatchService(mockUserService, mockTransactor, 10)
+
+	// When
+	result, err := batchService.ExecuteBatch(ctx, dto.BatchRequest{Atomic: true, Operations: []dto.BatchOperation{
+		{ID: "delete", Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907e"},
+	}})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.False(t, result.RolledBack)
+	assert.Equal(t, []dto.BatchOperationResult{{ID: "delete"}}, result.Results)
+}
+
+func TestExecuteBatchAtomicTransactionError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserService := mock_user_service.NewUserService(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(errors.New("transactions are not supported"))
+	batchService := service.NewBatchService(mockUserService, mockTransactor, 10)
+
+	// When
+	_, err := batchService.ExecuteBatch(ctx, dto.BatchRequest{Atomic: true, Operations: []dto.BatchOperation{
+		{Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907e"},
+	}})
+
+	// Then
+	assert.Equal(t, apperror.KindInternal, apperror.From(err).Kind)
+}
+
+func TestBatchControllerResults(t *testing.T) {
+	// Given
+	mockBatchService := mock_batch_service.NewBatchService(t)
+	req := dto.BatchRequest{Atomic: true, Operations: []dto.BatchOperation{
+		{ID: "get", Method: dto.BatchMethodGet, UserID: "683ecde861d005de5ec0907d"},
+		{ID: "delete", Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907e"},
+		{ID: "create", Method: dto.BatchMethodCreate, User: &dto.BatchUser{Name: "New User", Email: "new@example.com", Password: "password123"}},
+		{ID: "deleted", Method: dto.BatchMethodDelete, UserID: "683ecde861d005de5ec0907f"},
+	}}
+	mockBatchService.On("ExecuteBatch", mock.Anything, req).Return(dto.BatchResult{
+		RolledBack: true,
+		Results: []dto.BatchOperationResult{
+			{ID: "get", User: &dto.UserGetMeResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com", Version: 2}},
+			{ID: "delete", Err: errUserNotFound},
+			{ID: "create", User: &dto.UserGetMeResponse{ID: "683ecde861d005de5ec09080", Name: "New User", Email: "new@example.com", Version: 1}},
+			{ID: "deleted"},
+		},
+	}, nil)
+	body, _ := encodingjson.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewBatchController(mockBatchService).Batch(w, r)
+
+	// Then
+	var response struct {
+		Data dto.BatchResponse `json:"data"`
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&response))
+	assert.True(t, response.Data.RolledBack)
+	assert.Equal(t, http.StatusOK, response.Data.Results[0].Status)
+	assert.Equal(t, `"2"`, response.Data.Results[0].ETag)
+	assert.Equal(t, "Test User", response.Data.Results[0].User.Name)
+	assert.Equal(t, http.StatusNotFound, response.Data.Results[1].Status)
+	assert.Equal(t, apperror.CodeUserNotFound, response.Data.Results[1].Error.Code)
+	assert.Equal(t, http.StatusCreated, response.Data.Results[2].Status)
+	assert.Equal(t, `"1"`, response.Data.Results[2].ETag)
+	assert.Equal(t, http.StatusNoContent, response.Data.Results[3].Status)
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{name: "user", role: "", status: http.StatusForbidden},
+		{name: "admin", role: entity.RoleAdmin, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			handler := middleware.JwtMiddleware(middleware.AdminMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", tt.role)
+			assert.NoError(t, err)
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/batch", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			// When
+			handler(w, r)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
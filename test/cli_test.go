@@ -0,0 +1,306 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/cli"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCLI returns a CLI on buffers whose commands use userRepository, the
+// configuration and the logger they set are restored after the test.
+func newCLI(t *testing.T, userRepository repository.UserRepository, stdin string) (*cli.CLI, *bytes.Buffer, *bytes.Buffer) {
+	t.Setenv("APP_REST_SERVER_JWT_SECRET", strongSecret)
+	t.Cleanup(func() {
+		config.SetConfig(cfg)
+		logger.Configure(logger.Options{}, os.Stderr)
+	})
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return &cli.CLI{
+		Stdin:  strings.NewReader(stdin),
+		Stdout: stdout,
+		Stderr: stderr,
+		Connect: func(*config.Config) (*repository.Repository, *service.Service, error) {
+			return &repository.Repository{UserRepository: userRepository}, &service.Service{UserService: newUserService(t, userRepository)}, nil
+		},
+	}, stdout, stderr
+}
+
+func TestCLICreateAdmin(t *testing.T) {
+	// Given
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockUserRepository.On("SaveUser", mock.Anything, mock.MatchedBy(func(user entity.User) bool {
+		return user.Role == entity.RoleAdmin && user.Email == "admin@example.com"
+	})).Return(func(_ context.Context, user entity.User) (entity.User, error) {
+		return user, nil
+	})
+	command, stdout, _ := newCLI(t, mockUserRepository, "password123\n")
+
+	// When
+	code := command.Run([]string{"user", "create-admin", "-name", "Admin", "-email", "Admin@Example.com",
+		"-password-stdin", "-output", "json", "-config", writeFile(t, "config.yaml", "")})
+
+	// Then
+	assert.Equal(t, cli.ExitOK, code)
+	var output map[string]string
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &output))
+	assert.Equal(t, "admin@example.com", output["email"])
+	assert.NotEmpty(t, output["id"])
+	assert.NotContains(t, output, "password", "a password from stdin is not printed")
+}
+
+func TestCLIRejectsShortPassword(t *testing.T) {
+	// Given
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	command, _, stderr := newCLI(t, mockUserRepository, "short\n")
+
+	// When
+	code := command.Run([]string{"user", "create-admin", "-name", "Admin", "-email", "admin@example.com", "-password-stdin"})
+
+	// Then
+	assert.Equal(t, cli.ExitInvalid, code)
+	assert.Contains(t, stderr.String(), "password must be at least 8 characters long")
+	mockUserRepository.AssertNotCalled(t, "SaveUser", mock.Anything, mock.Anything)
+}
+
+func TestCLIResetPasswordOfUnknownUser(t *testing.T) {
+	// Given
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockUserRepository.On("GetUserById", mock.Anything, "683ecde861d005de5ec0907d").Return(entity.User{}, repository.ErrNotFound)
+	command, stdout, stderr := newCLI(t, mockUserRepository, "")
+
+	// When
+	code := command.Run([]string{"user", "reset-password", "-id", "683ecde861d005de5ec0907d", "-output", "json",
+		"-config", writeFile(t, "config.yaml", "")})
+
+	// Then
+	assert.Equal(t, cli.ExitNotFound, code)
+	assert.Empty(t, stdout.String())
+	var output struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	// the logs come first on stderr
+	lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	assert.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &output))
+	assert.Equal(t, "USER_NOT_FOUND", output.Error.Code)
+}
+
+func TestCLIImportSkipsExistingUsers(t *testing.T) {
+	// Given
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	existingID := bson.NewObjectID()
+	mockUserRepository.On("SaveUser", mock.Anything, mock.MatchedBy(func(user entity.User) bool { return user.ID == existingID })).
+		Return(entity.User{}, repository.ErrDuplicateEmail)
+	mockUserRepository.On("SaveUser", mock.Anything, mock.MatchedBy(func(user entity.User) bool { return user.ID != existingID })).
+		Return(func(_ context.Context, user entity.User) (entity.User, error) {
+			return user, nil
+		})
+	hash := "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	stdin := `{"id":"` + existingID.Hex() + `","name":"Existing","email":"existing@example.com","passwordHash":"` + hash + `"}
+{"name":"New","email":"new@example.com","passwordHash":"` + hash + `","role":"admin"}
+`
+	command, stdout, _ := newCLI(t, mockUserRepository, stdin)
+
+	// When
+	code := command.Run([]string{"user", "import", "-skip-existing", "-output", "json", "-config", writeFile(t, "config.yaml", "")})
+
+	// Then
+	assert.Equal(t, cli.ExitOK, code)
+	assert.JSONEq(t, `{"imported":1,"skipped":1}`, stdout.String())
+}
+
+func TestCLIImportStopsAtExistingUser(t *testing.T) {
+	// Given
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockUserRepository.On("SaveUser", mock.Anything, mock.Anything).
+		Return(entity.User{}, repository.ErrDuplicateEmail)
+	stdin := `{"name":"Existing","email":"existing@example.com","passwordHash":"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"}`
+	command, _, stderr := newCLI(t, mockUserRepository, stdin)
+
+	// When
+	code := command.Run([]string{"user", "import", "-config", writeFile(t, "config.yaml", "")})
+
+	// Then
+	assert.Equal(t, cli.ExitConflict, code)
+	assert.Contains(t, stderr.String(), "line 1:")
+}
+
+func TestCLIConfigCheckListsProblems(t *testing.T) {
+	// Given
+	command, stdout, _ := newCLI(t, nil, "")
+	path := writeFile(t, "config.yaml", "restServer:\n  port: 70000\n")
+
+	// When
+	code := command.Run([]string{"config", "check", "-config", path, "-output", "json"})
+
+	// Then
+	assert.Equal(t, cli.ExitUsage, code)
+	var output struct {
+		Valid    bool     `json:"valid"`
+		Problems []string `json:"problems"`
+	}
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &output))
+	assert.False(t, output.Valid)
+	assert.Contains(t, strings.Join(output.Problems, "\n"), "restServer.port")
+}
+
+func TestCLIRejectsUnknownCommand(t *testing.T) {
+	// Given
+	command, _, stderr := newCLI(t, nil, "")
+
+	// When
+	code := command.Run([]string{"user", "promote"})
+
+	// Then
+	assert.Equal(t, cli.ExitUsage, code)
+	assert.Contains(t, stderr.String(), `unknown command "user promote"`)
+}
+
+func TestCLIMigrateRejectsUserStoreInMongoDB(t *testing.T) {
+	// Given
+	command, _, stderr := newCLI(t, nil, "")
+	path := writeFile(t, "config.yaml", "")
+
+	// When
+	unknown := command.Run([]string{"migrate", "up", "-store", "redis", "-config", path})
+	users := command.Run([]string{"migrate", "status", "-store", "users", "-config", path})
+
+	// Then
+	assert.Equal(t, cli.ExitUsage, unknown)
+	assert.Equal(t, cli.ExitUsage, users)
+	assert.Contains(t, stderr.String(), "-store users needs userStore.backend postgres or sqlite")
+}
+
+func TestCLIGeneratesUsableKeyPair(t *testing.T) {
+	// Given
+	command, _, _ := newCLI(t, nil, "")
+	out := filepath.Join(t.TempDir(), "jwt")
+
+	// When
+	code := command.Run([]string{"jwt", "keygen", "-algorithm", "ES256", "-out", out})
+
+	// Then
+	assert.Equal(t, cli.ExitOK, code)
+	info, err := os.Stat(out + ".key")
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	t.Setenv("APP_REST_SERVER_JWT_ALGORITHM", "ES256")
+	t.Setenv("APP_REST_SERVER_JWT_PRIVATE_KEY_FILE", out+".key")
+	t.Setenv("APP_REST_SERVER_JWT_PUBLIC_KEY_FILE", out+".pub")
+	loaded, err := config.Load([]string{"-config", writeFile(t, "config.yaml", "")})
+	assert.NoError(t, err)
+	config.SetConfig(loaded)
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	claim, err := jwt.ValidateJwt(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "683ecde861d005de5ec0907d", claim.UserId)
+
+	// the files are not replaced without -force
+	assert.Equal(t, cli.ExitUsage, command.Run([]string{"jwt", "keygen", "-out", out}))
+}
+
+// revocationCacheTTL sets restServer.jwt.revocationCacheTtl until the test ends.
+func revocationCacheTTL(t *testing.T, ttl time.Duration) {
+	cached := *cfg
+	cached.RestServer.Jwt.RevocationCacheTTL = int(ttl / time.Millisecond)
+	config.SetConfig(&cached)
+	t.Cleanup(func() { config.SetConfig(cfg) })
+}
+
+// revocationClaim returns the validated claim of a new token.
+func revocationClaim(t *testing.T) *jwt.JwtClaim {
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	claim, err := jwt.ValidateJwt(token)
+	assert.NoError(t, err)
+	return claim
+}
+
+func TestCheckRevokedRejectsTokensIssuedBeforeRevocation(t *testing.T) {
+	// Given
+	revocationCacheTTL(t, 0)
+	revokedAt := time.Now().Add(-time.Hour)
+	jwt.SetRevocationCheck(func(context.Context, string) (time.Time, error) { return revokedAt, nil })
+	t.Cleanup(func() { jwt.SetRevocationCheck(nil) })
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	claim, err := jwt.ValidateJwt(token)
+	assert.NoError(t, err)
+
+	// When
+	before := jwt.CheckRevoked(context.Background(), claim)
+	revokedAt = time.Now()
+	after := jwt.CheckRevoked(context.Background(), claim)
+
+	// Then
+	assert.NoError(t, before)
+	assert.ErrorIs(t, after, jwt.ErrSessionRevoked)
+}
+
+func TestCheckRevokedCachesRevocationTime(t *testing.T) {
+	// Given
+	revocationCacheTTL(t, 50*time.Millisecond)
+	revokedAt := time.Now().Add(-time.Hour)
+	reads := 0
+	jwt.SetRevocationCheck(func(context.Context, string) (time.Time, error) {
+		reads++
+		return revokedAt, nil
+	})
+	t.Cleanup(func() { jwt.SetRevocationCheck(nil) })
+	claim := revocationClaim(t)
+
+	// When
+	first := jwt.CheckRevoked(context.Background(), claim)
+	revokedAt = time.Now()
+	cached := jwt.CheckRevoked(context.Background(), claim)
+	time.Sleep(60 * time.Millisecond)
+	expired := jwt.CheckRevoked(context.Background(), claim)
+
+	// Then
+	assert.NoError(t, first)
+	assert.NoError(t, cached, "the revocation is seen once the cached time expires")
+	assert.ErrorIs(t, expired, jwt.ErrSessionRevoked)
+	assert.Equal(t, 2, reads)
+}
+
+func TestCheckRevokedFailsWhileStoreIsDown(t *testing.T) {
+	// Given
+	revocationCacheTTL(t, time.Minute)
+	storeErr := errors.New("store unreachable")
+	reads := 0
+	jwt.SetRevocationCheck(func(context.Context, string) (time.Time, error) {
+		reads++
+		return time.Time{}, storeErr
+	})
+	t.Cleanup(func() { jwt.SetRevocationCheck(nil) })
+	claim := revocationClaim(t)
+
+	// When
+	first := jwt.CheckRevoked(context.Background(), claim)
+	second := jwt.CheckRevoked(context.Background(), claim)
+
+	// Then
+	assert.ErrorIs(t, first, storeErr)
+	assert.ErrorIs(t, second, storeErr)
+	assert.Equal(t, 2, reads, "errors are not cached")
+}
@@ -0,0 +1,182 @@
+package test
+
+import (
+	"bytes"
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCodecNegotiate(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		ok          bool
+	}{
+		{accept: "", contentType: codec.ContentTypeJSON, ok: true},
+		{accept: "*/*", contentType: codec.ContentTypeJSON, ok: true},
+		{accept: "application/cbor", contentType: codec.ContentTypeCBOR, ok: true},
+		{accept: "application/json;q=0.5, application/msgpack", contentType: codec.ContentTypeMessagePack, ok: true},
+		{accept: "application/*;q=0.2, application/cbor;q=0.9", contentType: codec.ContentTypeCBOR, ok: true},
+		{accept: "text/html, */*;q=0.8", contentType: codec.ContentTypeJSON, ok: true},
+		{accept: "application/json;q=0, */*", contentType: codec.ContentTypeMessagePack, ok: true},
+		{accept: "text/csv", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			// When
+			c, ok := codec.Default.Negotiate(tt.accept)
+
+			// Then
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.contentType, c.ContentType())
+			}
+		})
+	}
+}
+
+func TestCodecRoundTripUsesJSONFieldNames(t *testing.T) {
+	for _, c := range []codec.Codec{codec.JSON, codec.MessagePack, codec.CBOR} {
+		t.Run(c.ContentType(), func(t *testing.T) {
+			// Given
+			in := dto.UserGetMeResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com", Version: 2}
+
+			// When
+			encoded, err := c.Marshal(json.Response{Data: in})
+			assert.NoError(t, err)
+			var generic map[string]interface{}
+			assert.NoError(t, c.Unmarshal(encoded, &generic))
+
+			// Then
+			data := generic["data"].(map[string]interface{})
+			assert.Equal(t, "test@example.com", data["email"])
+			assert.NotContains(t, data, "Version")
+			assert.NotContains(t, data, "locale")
+		})
+	}
+}
+
+func TestCodecRegisterWithMessagePackAndCBOR(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("RegisterUser", mock.Anything, dto.UserRegisterRequest{
+		Name:     "Test User",
+		Email:    "test@example.com",
+		Password: "password123",
+	}).Return(dto.UserRegisterResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com", AccessToken: "token"}, nil)
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true},
+		controller.NewUserController(mockUserService).UserRegister)
+	body, err := codec.MessagePack.Marshal(map[string]interface{}{"name": "Test User", "email": "test@example.com", "password": "password123"})
+	assert.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-msgpack")
+	r.Header.Set("Accept", codec.ContentTypeCBOR)
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, codec.ContentTypeCBOR, w.Header().Get("Content-Type"))
+	var response struct {
+		Data dto.UserRegisterResponse `json:"data"`
+	}
+	assert.NoError(t, codec.CBOR.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "683ecde861d005de5ec0907d", response.Data.ID)
+	assert.Equal(t, "token", response.Data.AccessToken)
+}
+
+func TestCodecValidatesMessagePackBody(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	body, err := codec.MessagePack.Marshal(map[string]interface{}{"email": "test@example.com", "password": 12345678})
+	assert.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
+	r.Header.Set("Content-Type", codec.ContentTypeMessagePack)
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "password", Code: apperror.CodeFieldInvalidType, Message: "password must be of type string"},
+	}, problem.Errors)
+}
+
+func TestCodecUnsupportedContentType(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(`<login/>`))
+	r.Header.Set("Content-Type", "application/xml")
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewUserController(mockUserService).UserLogin(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, json.ContentTypeProblem, w.Header().Get("Content-Type"))
+}
+
+func TestCodecNotAcceptable(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", strings.NewReader(`{"name":"Test User","email":"test@example.com","password":"password123"}`))
+	r.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewUserController(mockUserService).UserRegister(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, apperror.CodeNotAcceptable, problem.Code)
+	mockUserService.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything)
+}
+
+func TestCodecRejectsTooLargeBody(t *testing.T) {
+	// Given
+	limited := *cfg
+	limited.RestServer.MaxBodySize = 64
+	config.SetConfig(&limited)
+	t.Cleanup(func() { config.SetConfig(cfg) })
+	mockUserService := mock_user_service.NewUserService(t)
+	body, err := codec.MessagePack.Marshal(map[string]interface{}{"email": "test@example.com", "password": strings.Repeat("a", 64)})
+	assert.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
+	r.Header.Set("Content-Type", codec.ContentTypeMessagePack)
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewUserController(mockUserService).UserLogin(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, apperror.CodeRequestBodyTooLarge, problem.Code)
+	assert.Equal(t, "request body must not be larger than 64 bytes", problem.Detail)
+	mockUserService.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything)
+}
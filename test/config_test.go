@@ -0,0 +1,338 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const strongSecret = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+// writeFile writes content to name in a temporary directory and returns its path.
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	// Given
+	path := writeFile(t, "config.yaml", `
+restServer:
+  port: 4000
+  jwt:
+    secret: fromFile
+database:
+  host: file-host
+  databaseName: file-db
+`)
+	t.Setenv("APP_DATABASE_HOST", "env-host")
+	t.Setenv("APP_DATABASE_DATABASE_NAME", "env-db")
+
+	// When
+	loaded, err := config.Load([]string{"-config", path, "-set", "database.databaseName=flag-db"})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 4000, loaded.RestServer.Port)
+	assert.Equal(t, "env-host", loaded.Database.Host)
+	assert.Equal(t, "flag-db", loaded.Database.DatabaseName)
+	assert.Equal(t, 27017, loaded.Database.Port)
+	assert.Equal(t, config.ModeDevelopment, loaded.Mode)
+}
+
+func TestLoadConfigLimitsLoginAndRegisterByDefault(t *testing.T) {
+	// Given
+	t.Setenv("APP_REST_SERVER_JWT_SECRET", strongSecret)
+	configured := writeFile(t, "config.yaml", `
+restServer:
+  rateLimit:
+    policies:
+      - name: "users"
+        routes: ["/api/v1/users/"]
+        algorithm: "token_bucket"
+        limit: 60
+        window: 60000
+        keyBy: "ip"
+`)
+
+	// When
+	defaulted, errDefaulted := config.Load(nil)
+	overridden, errOverridden := config.Load([]string{"-config", configured})
+
+	// Then
+	if !assert.NoError(t, errDefaulted) || !assert.NoError(t, errOverridden) {
+		return
+	}
+	assert.True(t, defaulted.RestServer.RateLimit.Enabled)
+	names := []string{}
+	for _, policy := range defaulted.RestServer.RateLimit.Policies {
+		names = append(names, policy.Name)
+	}
+	assert.Equal(t, []string{"register", "login", "graphql"}, names)
+	assert.Equal(t, config.RateLimitPolicyConfig{
+		Name:      "login",
+		Routes:    []string{"POST /api/v1/users/login", "/user.v1.UserService/LoginUser"},
+		Algorithm: "sliding_window",
+		Limit:     10,
+		Window:    60000,
+		KeyBy:     "ip",
+	}, defaulted.RestServer.RateLimit.Policies[1])
+	if assert.Len(t, overridden.RestServer.RateLimit.Policies, 1, "configured policies replace the defaults") {
+		assert.Equal(t, "users", overridden.RestServer.RateLimit.Policies[0].Name)
+	}
+}
+
+func TestLoadConfigReadsSecretFile(t *testing.T) {
+	// Given
+	t.Setenv("APP_REST_SERVER_JWT_SECRET_FILE", writeFile(t, "jwt_secret", strongSecret+"\n"))
+
+	// When
+	loaded, err := config.Load([]string{"-config", writeFile(t, "config.yaml", "mode: production\n")})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, strongSecret, loaded.RestServer.Jwt.Secret)
+}
+
+func TestLoadConfigRejectsSecretAndSecretFile(t *testing.T) {
+	// Given
+	t.Setenv("APP_REST_SERVER_JWT_SECRET", strongSecret)
+	t.Setenv("APP_REST_SERVER_JWT_SECRET_FILE", writeFile(t, "jwt_secret", strongSecret))
+
+	// When
+	_, err := config.Load([]string{"-config", writeFile(t, "config.yaml", "")})
+
+	// Then
+	assert.ErrorContains(t, err, "APP_REST_SERVER_JWT_SECRET and APP_REST_SERVER_JWT_SECRET_FILE are both set")
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	// Given
+	path := writeFile(t, "config.yaml", "restServer:\n  prot: 3000\n  jwt:\n    secret: secret\n")
+
+	// When
+	_, err := config.Load([]string{"-config", path})
+
+	// Then
+	assert.ErrorContains(t, err, "prot")
+}
+
+func TestLoadConfigRejectsUnknownFlagSetting(t *testing.T) {
+	// When
+	_, err := config.Load([]string{"-config", writeFile(t, "config.yaml", ""), "-set", "restServer.prot=3000"})
+
+	// Then
+	assert.ErrorContains(t, err, `unknown setting "restServer.prot"`)
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	// Given
+	path := writeFile(t, "config.yaml", `
+mode: production
+restServer:
+  port: 70000
+  jwt:
+    secret: jwtSecret
+log:
+  level: loud
+tracing:
+  sampleRatio: 2
+`)
+
+	// When
+	_, err := config.Load([]string{"-config", path})
+
+	// Then
+	assert.Error(t, err)
+	lines := strings.Split(err.Error(), "\n")
+	assert.Equal(t, []string{
+		"invalid configuration:",
+		"  - restServer.port must be between 1 and 65535, got 70000",
+		"  - restServer.jwt.secret is too weak for production, use at least 32 random characters",
+		`  - log.level must be one of debug, info, warn, error, got "loud"`,
+		"  - tracing.sampleRatio must be between 0 and 1, got 2",
+	}, lines)
+}
+
+func TestLoadConfigValidatesUserStore(t *testing.T) {
+	// Given
+	path := writeFile(t, "config.yaml", `
+userStore:
+  backend: postgres
+  postgres:
+    url: mysql://localhost/users
+`)
+
+	// When
+	_, err := config.Load([]string{"-config", path})
+	_, errSQLite := config.Load([]string{"-config", path, "-set", "userStore.backend=sqlite"})
+	_, errUnknown := config.Load([]string{"-config", path, "-set", "userStore.backend=redis"})
+
+	// Then
+	assert.ErrorContains(t, err, "userStore.postgres.url must start with postgres:// or postgresql://")
+	assert.ErrorContains(t, errSQLite, "userStore.sqlite.path must be set for the sqlite backend")
+	assert.ErrorContains(t, errUnknown, `userStore.backend must be one of mongo, postgres, sqlite, got "redis"`)
+}
+
+func TestLoadConfigValidatesEventSinks(t *testing.T) {
+	// Given
+	path := writeFile(t, "config.yaml", `
+outbox:
+  nats:
+    enabled: true
+    url: http://localhost:4222
+  kafka:
+    enabled: true
+    brokers: ["localhost"]
+    topic: ""
+`)
+
+	// When
+	_, err := config.Load([]string{"-config", path})
+
+	// Then
+	assert.ErrorContains(t, err, "outbox.nats.url must start with nats:// or tls://")
+	assert.ErrorContains(t, err, `outbox.kafka.brokers[0] must be host:port, got "localhost"`)
+	assert.ErrorContains(t, err, "outbox.kafka.topic must be set")
+}
+
+func TestValidateRefusesPlaceholderSecretInProduction(t *testing.T) {
+	// Given
+	loaded, err := config.Load([]string{"-config", writeFile(t, "config.yaml", ""), "-set", "restServer.jwt.secret=" + strongSecret})
+	assert.NoError(t, err)
+	loaded.Mode = config.ModeProduction
+	loaded.RestServer.Jwt.Secret = "changeme-changeme-changeme-changeme"
+
+	// When
+	err = config.Validate(loaded)
+
+	// Then
+	assert.EqualError(t, err, "restServer.jwt.secret is too weak for production, it contains a placeholder")
+}
+
+// reloadableConfig writes a config file with port and level and loads it as the current configuration.
+func reloadableConfig(t *testing.T, port int, level string) (string, *config.Config) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, port, level)
+	loaded, err := config.Load([]string{"-config", path})
+	assert.NoError(t, err)
+	config.SetConfig(loaded)
+	t.Cleanup(func() {
+		config.SetConfig(cfg)
+	})
+	return path, loaded
+}
+
+func writeConfig(t *testing.T, path string, port int, level string) {
+	content := fmt.Sprintf("restServer:\n  port: %d\n  jwt:\n    secret: %s\nlog:\n  level: %s\n", port, strongSecret, level)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReloadConfigAppliesReloadableSettings(t *testing.T) {
+	// Given
+	path, previous := reloadableConfig(t, 3000, "info")
+	var notified *config.Config
+	unsubscribe := config.Subscribe("test", func(old *config.Config, current *config.Config) error {
+		assert.Same(t, previous, old)
+		notified = current
+		return nil
+	})
+	t.Cleanup(unsubscribe)
+	writeConfig(t, path, 4000, "debug")
+
+	// When
+	err := config.Reload([]string{"-config", path})
+
+	// Then
+	assert.NoError(t, err)
+	current := config.GetConfig()
+	assert.Same(t, current, notified)
+	assert.Equal(t, "debug", current.Log.Level)
+	assert.Equal(t, 3000, current.RestServer.Port, "the port needs a restart")
+}
+
+func TestReloadConfigKeepsCurrentWhenInvalid(t *testing.T) {
+	// Given
+	path, previous := reloadableConfig(t, 3000, "info")
+	unsubscribe := config.Subscribe("test", func(*config.Config, *config.Config) error {
+		t.Error("subscribers must not be notified of an invalid configuration")
+		return nil
+	})
+	t.Cleanup(unsubscribe)
+	writeConfig(t, path, 3000, "loud")
+
+	// When
+	err := config.Reload([]string{"-config", path})
+
+	// Then
+	assert.ErrorContains(t, err, "log.level must be one of")
+	assert.Same(t, previous, config.GetConfig())
+}
+
+func TestWatchConfigReloadsOnHangupSentBeforeItStarts(t *testing.T) {
+	// Given
+	path, _ := reloadableConfig(t, 3000, "info")
+	reloaded := make(chan *config.Config, 1)
+	unsubscribe := config.Subscribe("test", func(_ *config.Config, current *config.Config) error {
+		reloaded <- current
+		return nil
+	})
+	t.Cleanup(unsubscribe)
+	hangups, stopHangups := config.NotifyHangups()
+	defer stopHangups()
+	writeConfig(t, path, 3000, "debug")
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When
+	go config.Watch(ctx, []string{"-config", path}, hangups)
+
+	// Then
+	select {
+	case current := <-reloaded:
+		assert.Equal(t, "debug", current.Log.Level)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration was not reloaded")
+	}
+}
+
+func TestJwtSecretRotationKeepsIssuedTokensValid(t *testing.T) {
+	// Given
+	path, previous := reloadableConfig(t, 3000, "info")
+	unsubscribe := config.Subscribe("jwt", jwt.OnConfigReload)
+	t.Cleanup(unsubscribe)
+	issued, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	content := strings.Replace(mustReadFile(t, path), strongSecret, strings.Repeat("r", 64), 1)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	// When
+	err = config.Reload([]string{"-config", path})
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEqual(t, previous.RestServer.Jwt.Secret, config.GetConfig().RestServer.Jwt.Secret)
+	claim, err := jwt.ValidateJwt(issued)
+	assert.NoError(t, err)
+	assert.Equal(t, "683ecde861d005de5ec0907d", claim.UserId)
+	rotated, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	_, err = jwt.ValidateJwt(rotated)
+	assert.NoError(t, err)
+}
+
+func mustReadFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(content)
+}
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	encodingjson "encoding/json"
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/eventstream"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newUserEvent(eventType string, userID string) entity.Event {
+	return entity.Event{ID: userID + "-" + eventType, Type: eventType, Data: entity.UserSnapshot{ID: userID}}
+}
+
+// publishMessage publishes event and returns the message a subscriber receives for it.
+func publishMessage(t *testing.T, broker *eventstream.Broker, event entity.Event) eventstream.Message {
+	subscription := broker.Subscribe("", nil)
+	defer subscription.Close()
+	assert.NoError(t, broker.Publish(context.Background(), event))
+	return <-subscription.Messages()
+}
+
+func TestBrokerFiltersEventTypes(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	subscription := broker.Subscribe("", []string{entity.EventUserDeleted})
+
+	// When
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserCreated, "1")))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserDeleted, "1")))
+
+	// Then
+	message := <-subscription.Messages()
+	assert.Equal(t, entity.EventUserDeleted, message.Event.Type)
+	assert.Empty(t, subscription.Messages())
+}
+
+func TestBrokerReplaysAfterLastEventID(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	first := publishMessage(t, broker, newUserEvent(entity.EventUserCreated, "1"))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserUpdated, "1")))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserDeleted, "1")))
+
+	// When
+	subscription := broker.Subscribe(first.ID, nil)
+
+	// Then
+	assert.False(t, subscription.Reset)
+	assert.Len(t, subscription.Replay, 2)
+	assert.Equal(t, entity.EventUserUpdated, subscription.Replay[0].Event.Type)
+	assert.Equal(t, entity.EventUserDeleted, subscription.Replay[1].Event.Type)
+}
+
+func TestBrokerResetsWhenEventsAreNoLongerBuffered(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID func(first eventstream.Message) string
+	}{
+		{name: "evicted", lastEventID: func(first eventstream.Message) string { return first.ID }},
+		{name: "other broker", lastEventID: func(eventstream.Message) string { return "otherepoch-1" }},
+		{name: "malformed", lastEventID: func(eventstream.Message) string { return "not-an-id" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			broker := eventstream.NewBroker(1, 10)
+			first := publishMessage(t, broker, newUserEvent(entity.EventUserCreated, "1"))
+			assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserUpdated, "1")))
+			assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserDeleted, "1")))
+
+			// When
+			subscription := broker.Subscribe(tt.lastEventID(first), nil)
+
+			// Then
+			assert.True(t, subscription.Reset)
+			assert.Empty(t, subscription.Replay)
+		})
+	}
+}
+
+func TestBrokerDropsSlowConsumer(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 1)
+	slow := broker.Subscribe("", nil)
+	fast := broker.Subscribe("", nil)
+
+	// When
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserCreated, "1")))
+	<-fast.Messages()
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserUpdated, "1")))
+
+	// Then
+	<-slow.Done()
+	assert.ErrorIs(t, slow.Err(), eventstream.ErrSlowConsumer)
+	assert.Equal(t, entity.EventUserUpdated, (<-fast.Messages()).Event.Type)
+	assert.NoError(t, fast.Err())
+}
+
+func TestBrokerCloseEndsSubscriptions(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	subscription := broker.Subscribe("", nil)
+
+	// When
+	broker.Close()
+
+	// Then
+	<-subscription.Done()
+	assert.ErrorIs(t, subscription.Err(), eventstream.ErrClosed)
+	assert.ErrorIs(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserCreated, "1")), eventstream.ErrClosed)
+	<-broker.Subscribe("", nil).Done()
+}
+
+func TestEventStreamRejectsUnknownType(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/events?types=user.created,user.banned", nil)
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewEventController(broker, time.Second).Stream(w, r)
+
+	// Then
+	var problem struct {
+		Errors []apperror.FieldError `json:"errors"`
+	}
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Len(t, problem.Errors, 1)
+	assert.Equal(t, "types", problem.Errors[0].Field)
+	assert.Equal(t, apperror.CodeFieldNotAllowed, problem.Errors[0].Code)
+}
+
+func TestEventStreamServerSentEvents(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	first := publishMessage(t, broker, newUserEvent(entity.EventUserCreated, "1"))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserUpdated, "1")))
+	// the response validation must not buffer the stream
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateResponses: true},
+		controller.NewEventController(broker, time.Hour).Stream)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	r, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/events?types=user.updated,user.deleted", nil)
+	assert.NoError(t, err)
+	r.Header.Set("Last-Event-ID", first.ID)
+
+	// When
+	response, err := http.DefaultClient.Do(r)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			assert.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	// Then
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, "retry: 3000\n", readEvent())
+	assert.Contains(t, readEvent(), "event: user.updated\n")
+
+	// a live event is sent once the replay is done
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserCreated, "2")))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserDeleted, "2")))
+	event := readEvent()
+	assert.Contains(t, event, "event: user.deleted\n")
+	data, _ := strings.CutPrefix(event[strings.Index(event, "data: "):], "data: ")
+	var received entity.Event
+	assert.NoError(t, encodingjson.Unmarshal([]byte(data), &received))
+	assert.Equal(t, "2", received.Data.ID)
+}
+
+func TestEventStreamWebSocket(t *testing.T) {
+	// Given
+	broker := eventstream.NewBroker(10, 10)
+	server := httptest.NewServer(http.HandlerFunc(controller.NewEventController(broker, time.Hour).Stream))
+	defer server.Close()
+	first := publishMessage(t, broker, newUserEvent(entity.EventUserCreated, "1"))
+	assert.NoError(t, broker.Publish(context.Background(), newUserEvent(entity.EventUserUpdated, "1")))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// When
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/events?lastEventId="+first.ID, nil)
+	assert.NoError(t, err)
+	defer conn.CloseNow()
+	_, data, err := conn.Read(ctx)
+	assert.NoError(t, err)
+	broker.Close()
+
+	// Then
+	var message dto.StreamMessage
+	assert.NoError(t, encodingjson.Unmarshal(data, &message))
+	assert.Equal(t, entity.EventUserUpdated, message.Type)
+	assert.NotEmpty(t, message.ID)
+	assert.Equal(t, "1", message.Event.Data.ID)
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusGoingAway, websocket.CloseStatus(err))
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/eventsink"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"testing"
+	"time"
+)
+
+// startNATSServer runs an in-process NATS server with JetStream until the test ends.
+func startNATSServer(t *testing.T) *natsserver.Server {
+	server, err := natsserver.NewServer(&natsserver.Options{Host: "127.0.0.1", Port: -1, JetStream: true, StoreDir: t.TempDir()})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	go server.Start()
+	if !server.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server did not start")
+	}
+	t.Cleanup(server.Shutdown)
+	return server
+}
+
+// kafkaWriterFunc is a KafkaWriter that hands the messages to a function.
+type kafkaWriterFunc func(ctx context.Context, msgs ...kafka.Message) error
+
+func (f kafkaWriterFunc) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return f(ctx, msgs...)
+}
+
+func (f kafkaWriterFunc) Close() error {
+	return nil
+}
+
+func newSinkEvent() entity.Event {
+	return newOutboxEvent("683ecde861d005de5ec0907d", 1, entity.EventUserCreated).Event
+}
+
+func TestNATSPublisherPublishesEventPerType(t *testing.T) {
+	// Given
+	server := startNATSServer(t)
+	subscriber, err := nats.Connect(server.ClientURL())
+	assert.NoError(t, err)
+	defer subscriber.Close()
+	subscription, err := subscriber.SubscribeSync("users.>")
+	assert.NoError(t, err)
+	assert.NoError(t, subscriber.Flush())
+	publisher, err := eventsink.NewNATSPublisher(server.ClientURL(), eventsink.NATSOptions{SubjectPrefix: "users", Timeout: time.Second})
+	assert.NoError(t, err)
+	defer publisher.Close()
+	event := newSinkEvent()
+
+	// When
+	err = publisher.Publish(context.Background(), event)
+
+	// Then
+	assert.NoError(t, err)
+	msg, err := subscription.NextMsg(time.Second)
+	if assert.NoError(t, err) {
+		var received entity.Event
+		assert.NoError(t, encodingjson.Unmarshal(msg.Data, &received))
+		assert.Equal(t, "users.user.created", msg.Subject)
+		assert.Equal(t, event.ID, msg.Header.Get(eventsink.HeaderEventID))
+		assert.Equal(t, entity.EventUserCreated, msg.Header.Get(eventsink.HeaderEventType))
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, event.Data, received.Data)
+	}
+}
+
+func TestNATSPublisherJetStreamDropsDuplicates(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	server := startNATSServer(t)
+	conn, err := nats.Connect(server.ClientURL())
+	assert.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	assert.NoError(t, err)
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "USERS", Subjects: []string{"users.>"}})
+	assert.NoError(t, err)
+	publisher, err := eventsink.NewNATSPublisher(server.ClientURL(), eventsink.NATSOptions{SubjectPrefix: "users", JetStream: true, Timeout: time.Second})
+	assert.NoError(t, err)
+	defer publisher.Close()
+	event := newSinkEvent()
+
+	// When
+	first := publisher.Publish(ctx, event)
+	republished := publisher.Publish(ctx, event)
+
+	// Then
+	assert.NoError(t, first)
+	assert.NoError(t, republished)
+	info, err := stream.Info(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), info.State.Msgs)
+}
+
+func TestNATSPublisherJetStreamFailsWithoutStream(t *testing.T) {
+	// Given
+	server := startNATSServer(t)
+	publisher, err := eventsink.NewNATSPublisher(server.ClientURL(), eventsink.NATSOptions{SubjectPrefix: "users", JetStream: true, Timeout: 200 * time.Millisecond})
+	assert.NoError(t, err)
+	defer publisher.Close()
+
+	// When
+	err = publisher.Publish(context.Background(), newSinkEvent())
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestNATSPublisherFailsWithoutServer(t *testing.T) {
+	// When
+	_, err := eventsink.NewNATSPublisher("nats://127.0.0.1:1", eventsink.NATSOptions{SubjectPrefix: "users", Timeout: 200 * time.Millisecond})
+
+	// Then
+	assert.ErrorContains(t, err, "connect to nats")
+}
+
+func TestKafkaPublisherKeysEventByUser(t *testing.T) {
+	// Given
+	var written []kafka.Message
+	publisher := eventsink.NewKafkaPublisher(kafkaWriterFunc(func(ctx context.Context, msgs ...kafka.Message) error {
+		written = append(written, msgs...)
+		return nil
+	}))
+	event := newSinkEvent()
+
+	// When
+	err := publisher.Publish(context.Background(), event)
+
+	// Then
+	assert.NoError(t, err)
+	if assert.Len(t, written, 1) {
+		var received entity.Event
+		assert.NoError(t, encodingjson.Unmarshal(written[0].Value, &received))
+		assert.Equal(t, []byte(event.Data.ID), written[0].Key)
+		assert.Equal(t, event.ID, received.ID)
+		assert.Equal(t, []kafka.Header{
+			{Key: eventsink.HeaderEventID, Value: []byte(event.ID)},
+			{Key: eventsink.HeaderEventType, Value: []byte(entity.EventUserCreated)},
+		}, written[0].Headers)
+	}
+}
+
+func TestKafkaPublisherReturnsWriteError(t *testing.T) {
+	// Given
+	publisher := eventsink.NewKafkaPublisher(kafkaWriterFunc(func(ctx context.Context, msgs ...kafka.Message) error {
+		return kafka.LeaderNotAvailable
+	}))
+
+	// When
+	err := publisher.Publish(context.Background(), newSinkEvent())
+
+	// Then
+	assert.ErrorIs(t, err, kafka.LeaderNotAvailable)
+}
+
+func TestKafkaWriterWaitsForEveryReplica(t *testing.T) {
+	// When
+	writer := eventsink.NewKafkaWriter([]string{"localhost:9092", "localhost:9093"}, "user-events", time.Second)
+
+	// Then
+	assert.Equal(t, "user-events", writer.Topic)
+	assert.Equal(t, kafka.RequireAll, writer.RequiredAcks)
+	assert.Equal(t, 1, writer.BatchSize)
+	assert.IsType(t, &kafka.Hash{}, writer.Balancer)
+}
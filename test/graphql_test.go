@@ -0,0 +1,304 @@
+package test
+
+import (
+	"bytes"
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/graphqlserver"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type graphQLTestResponse struct {
+	Data   map[string]interface{}       `json:"data"`
+	Errors []graphqlserver.GraphQLError `json:"errors"`
+}
+
+func doGraphQL(t *testing.T, userService service.UserService, limits graphqlserver.Limits, userID string, req graphqlserver.GraphQLRequest) (int, graphQLTestResponse) {
+	server, err := graphqlserver.NewServer(userService, limits)
+	assert.NoError(t, err)
+	handler := middleware.OptionalJwtMiddleware(controller.NewGraphQLController(server).Query)
+
+	body, _ := encodingjson.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		token, err := jwt.GenerateJwt(userID, "", "")
+		assert.NoError(t, err)
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	var response graphQLTestResponse
+	if w.Code == http.StatusOK {
+		assert.NoError(t, encodingjson.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestGraphQLMe(t *testing.T) {
+	// Given
+	userID := "683ecde861d005de5ec0907d"
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("GetUserByID", mock.Anything, userID).Return(dto.UserGetMeResponse{
+		ID:      userID,
+		Name:    "Test User",
+		Email:   "test@example.com",
+		Version: 2,
+	}, nil)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, userID, graphqlserver.GraphQLRequest{
+		Query: "{ me { id name email locale version } }",
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"id":      userID,
+		"name":    "Test User",
+		"email":   "test@example.com",
+		"locale":  nil,
+		"version": float64(2),
+	}, resp.Data["me"])
+}
+
+func TestGraphQLMeUnauthenticated(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: "{ me { id } }",
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, apperror.CodeUnauthorized, resp.Errors[0].Extensions["code"])
+	mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestGraphQLInvalidTokenRejected(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	server, err := graphqlserver.NewServer(mockUserService, graphqlserver.Limits{})
+	assert.NoError(t, err)
+	handler := middleware.OptionalJwtMiddleware(controller.NewGraphQLController(server).Query)
+	r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(`{"query":"{ me { id } }"}`))
+	r.Header.Set("Authorization", "Bearer invalid")
+	w := httptest.NewRecorder()
+
+	// When
+	handler(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGraphQLUsersConnection(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("GetUserConnection", mock.Anything, dto.UserConnectionRequest{
+		First:  1,
+		After:  "b2Zmc2V0OjA",
+		Filter: dto.UserListFilter{Name: "test", Email: "test@example.com"},
+	}).Return(dto.UserConnectionResponse{
+		Edges: []dto.UserEdge{
+			{Cursor: "b2Zmc2V0OjE", Node: dto.UserListGetResponseItem{ID: "683ecde861d005de5ec0907e", Name: "Test User", Email: "test@example.com"}},
+		},
+		PageInfo: dto.PageInfo{EndCursor: "b2Zmc2V0OjE", HasNextPage: true},
+	}, nil)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: `query Users($first: Int, $after: String) {
+			users(first: $first, after: $after, filter: {name: "test", email: "Test@Example.com"}) {
+				edges { cursor node { id name } }
+				pageInfo { endCursor hasNextPage }
+			}
+		}`,
+		Variables: map[string]interface{}{"first": 1, "after": "b2Zmc2V0OjA"},
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{
+		"edges": []interface{}{
+			map[string]interface{}{
+				"cursor": "b2Zmc2V0OjE",
+				"node":   map[string]interface{}{"id": "683ecde861d005de5ec0907e", "name": "Test User"},
+			},
+		},
+		"pageInfo": map[string]interface{}{"endCursor": "b2Zmc2V0OjE", "hasNextPage": true},
+	}, resp.Data["users"])
+}
+
+func TestGraphQLUserLookupsAreBatched(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("GetUsersByIDs", mock.Anything, []string{"683ecde861d005de5ec0907d", "683ecde861d005de5ec0907e"}).
+		Return([]dto.UserListGetResponseItem{
+			{ID: "683ecde861d005de5ec0907d", Name: "Test User 1", Email: "test1@example.com"},
+		}, nil).Once()
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: `{
+			a: user(id: "683ecde861d005de5ec0907d") { name }
+			b: user(id: "683ecde861d005de5ec0907e") { name }
+			c: user(id: "683ecde861d005de5ec0907d") { email }
+		}`,
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"name": "Test User 1"}, resp.Data["a"])
+	assert.Nil(t, resp.Data["b"])
+	assert.Equal(t, map[string]interface{}{"email": "test1@example.com"}, resp.Data["c"])
+	mockUserService.AssertNumberOfCalls(t, "GetUsersByIDs", 1)
+}
+
+func TestGraphQLQueryTooDeep(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{MaxDepth: 3}, "", graphqlserver.GraphQLRequest{
+		Query: "{ users { edges { node { id } } } }",
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, resp.Data)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, apperror.CodeQueryTooDeep, resp.Errors[0].Extensions["code"])
+	assert.Equal(t, "query depth 4 exceeds the limit of 3", resp.Errors[0].Message)
+}
+
+func TestGraphQLQueryTooComplex(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	query := `query Users($first: Int) { users(first: $first) { ...edges } }
+		fragment edges on UserConnection { edges { node { id name email } } }`
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{MaxComplexity: 100}, "", graphqlserver.GraphQLRequest{
+		Query:     query,
+		Variables: map[string]interface{}{"first": 50},
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, apperror.CodeQueryTooComplex, resp.Errors[0].Extensions["code"])
+	assert.Equal(t, "query complexity 251 exceeds the limit of 100", resp.Errors[0].Message)
+	mockUserService.AssertNotCalled(t, "GetUserConnection", mock.Anything, mock.Anything)
+}
+
+func TestGraphQLRejectsAliasedAuthMutations(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	query := `mutation Guess { ...guesses }
+		fragment guesses on Mutation {
+			a: login(input: {email: "test@example.com", password: "password1"}) { accessToken }
+			b: login(input: {email: "test@example.com", password: "password2"}) { accessToken }
+			c: register(input: {name: "Test User", email: "new@example.com", password: "password123"}) { accessToken }
+		}`
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{MaxComplexity: 1000}, "", graphqlserver.GraphQLRequest{Query: query})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, resp.Data)
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, apperror.CodeTooManyAuthMutations, resp.Errors[0].Extensions["code"])
+		assert.Equal(t, "an operation may run at most 1 login or register, got 3", resp.Errors[0].Message)
+	}
+	mockUserService.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything)
+	mockUserService.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything)
+}
+
+func TestGraphQLInvalidQuery(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: "{ me { password } }",
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, apperror.CodeInvalidQuery, resp.Errors[0].Extensions["code"])
+}
+
+func TestGraphQLUpdateMe(t *testing.T) {
+	// Given
+	userID := "683ecde861d005de5ec0907d"
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("UpdateUser", mock.Anything, userID, dto.UserUpdateRequest{
+		Name:    "New Name",
+		Email:   "new@example.com",
+		IfMatch: etag.FromVersion(2),
+	}).Return(dto.UserUpdateResponse{ID: userID, Name: "New Name", Email: "new@example.com", Version: 3}, nil)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, userID, graphqlserver.GraphQLRequest{
+		Query: `mutation { updateMe(input: {name: "New Name", email: "New@Example.com"}, expectedVersion: 2) { id version } }`,
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]interface{}{"id": userID, "version": float64(3)}, resp.Data["updateMe"])
+}
+
+func TestGraphQLRegisterValidationFailed(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: `mutation { register(input: {name: "Test User", email: "invalid", password: "password123"}) { accessToken } }`,
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, resp.Errors, 1)
+	assert.Equal(t, apperror.CodeValidationFailed, resp.Errors[0].Extensions["code"])
+	fields := resp.Errors[0].Extensions["fields"].([]interface{})
+	assert.Equal(t, "email", fields[0].(map[string]interface{})["field"])
+	mockUserService.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything)
+}
+
+func TestGraphQLDeleteMeRequiresUser(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+
+	// When
+	status, resp := doGraphQL(t, mockUserService, graphqlserver.Limits{}, "", graphqlserver.GraphQLRequest{
+		Query: "mutation { deleteMe }",
+	})
+
+	// Then
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, resp.Data)
+	assert.Equal(t, apperror.CodeUnauthorized, resp.Errors[0].Extensions["code"])
+}
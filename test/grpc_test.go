@@ -0,0 +1,194 @@
+package test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/etag"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/grpcserver"
+	"github.com/taninchot-work/backend-challenge/internal/grpcserver/userpb"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func newGrpcClient(t *testing.T, userService service.UserService) *grpc.ClientConn {
+	return newRateLimitedGrpcClient(t, userService, nil)
+}
+
+// newRateLimitedGrpcClient is newGrpcClient with the calls limited by limiter.
+func newRateLimitedGrpcClient(t *testing.T, userService service.UserService, limiter *ratelimit.Limiter) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpcserver.NewServer(&service.Service{UserService: userService}, limiter)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+	return conn
+}
+
+func withAccessToken(t *testing.T, ctx context.Context, userID string) context.Context {
+	token, err := jwt.GenerateJwt(userID, "", "")
+	assert.NoError(t, err)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func TestGrpcGetMeSuccess(t *testing.T) {
+	// Given
+	userID := "683ecde861d005de5ec0907d"
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("GetUserByID", mock.Anything, userID).Return(dto.UserGetMeResponse{
+		ID:      userID,
+		Name:    "Test User",
+		Email:   "test@example.com",
+		Version: 3,
+	}, nil)
+	client := userpb.NewUserServiceClient(newGrpcClient(t, mockUserService))
+
+	// When
+	resp, err := client.GetMe(withAccessToken(t, context.Background(), userID), &userpb.GetMeRequest{})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, userID, resp.GetUser().GetId())
+	assert.Equal(t, "Test User", resp.GetUser().GetName())
+	assert.Equal(t, int64(3), resp.GetUser().GetVersion())
+}
+
+func TestGrpcGetMeUnauthenticated(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	client := userpb.NewUserServiceClient(newGrpcClient(t, mockUserService))
+
+	// When
+	_, err := client.GetMe(context.Background(), &userpb.GetMeRequest{})
+
+	// Then
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	mockUserService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+}
+
+func TestGrpcRegisterUserInvalidArgument(t *testing.T) {
+	// Given
+	mockUserService := mock_user_service.NewUserService(t)
+	client := userpb.NewUserServiceClient(newGrpcClient(t, mockUserService))
+
+	// When
+	_, err := client.RegisterUser(context.Background(), &userpb.RegisterUserRequest{Name: "Test User", Password: "password123"})
+
+	// Then
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.GetFieldViolations()
+		}
+	}
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "email", violations[0].GetField())
+		assert.Equal(t, apperror.CodeFieldRequired, violations[0].GetReason())
+	}
+}
+
+func TestGrpcLoginUserIsRateLimited(t *testing.T) {
+	// Given
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{{
+		Name:      "login",
+		Routes:    []string{"POST /api/v1/users/login", userpb.UserService_LoginUser_FullMethodName},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     2,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByIP,
+	}})
+	assert.NoError(t, err)
+	mockUserService := mock_user_service.NewUserService(t)
+	mockUserService.On("LoginUser", mock.Anything, mock.Anything).Return(dto.UserLoginResponse{}, service.ErrInvalidCredentials).Times(2)
+	client := userpb.NewUserServiceClient(newRateLimitedGrpcClient(t, mockUserService, limiter))
+	req := &userpb.LoginUserRequest{Email: "test@example.com", Password: "guess"}
+
+	// When
+	var codesSeen []codes.Code
+	var header metadata.MD
+	for range 3 {
+		_, err := client.LoginUser(context.Background(), req, grpc.Header(&header))
+		codesSeen = append(codesSeen, status.Code(err))
+	}
+
+	// Then
+	assert.Equal(t, []codes.Code{codes.Unauthenticated, codes.Unauthenticated, codes.ResourceExhausted}, codesSeen)
+	assert.NotEmpty(t, header.Get("retry-after"))
+}
+
+func TestGrpcUpdateUserStatusCodes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{name: "precondition failed", serviceError: service.ErrPreconditionFailed, expectedCode: codes.FailedPrecondition},
+		{name: "concurrent modification", serviceError: service.ErrConcurrentModification, expectedCode: codes.Aborted},
+		{name: "email already exists", serviceError: apperror.Conflict(apperror.CodeEmailAlreadyExists, "email is already registered"), expectedCode: codes.AlreadyExists},
+		{name: "not found", serviceError: apperror.NotFound(apperror.CodeUserNotFound, "user not found"), expectedCode: codes.NotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			userID := "683ecde861d005de5ec0907d"
+			expectedVersion := int64(2)
+			mockUserService := mock_user_service.NewUserService(t)
+			mockUserService.On("UpdateUser", mock.Anything, userID, dto.UserUpdateRequest{
+				Name:    "Updated Name",
+				Email:   "updated@example.com",
+				IfMatch: etag.FromVersion(expectedVersion),
+			}).Return(dto.UserUpdateResponse{}, tc.serviceError)
+			client := userpb.NewUserServiceClient(newGrpcClient(t, mockUserService))
+
+			// When
+			_, err := client.UpdateUser(withAccessToken(t, context.Background(), userID), &userpb.UpdateUserRequest{
+				Name:            "Updated Name",
+				Email:           "Updated@Example.com",
+				ExpectedVersion: &expectedVersion,
+			})
+
+			// Then
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestGrpcHealthCheck(t *testing.T) {
+	// Given
+	client := healthpb.NewHealthClient(newGrpcClient(t, mock_user_service.NewUserService(t)))
+
+	// When
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: userpb.UserService_ServiceDesc.ServiceName})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
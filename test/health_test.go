@@ -0,0 +1,137 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/health"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthReportFailsWhenACheckFails(t *testing.T) {
+	// Given
+	registry := health.NewRegistry(time.Second, time.Millisecond)
+	registry.Register(health.Readiness, "config", health.CheckerFunc(func(context.Context) error { return nil }))
+	registry.Register(health.Readiness, "mongodb", health.CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+
+	// When
+	report := registry.Run(context.Background(), health.Readiness)
+
+	// Then
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusPass, report.Checks["config"].Status)
+	assert.Equal(t, health.StatusFail, report.Checks["mongodb"].Status)
+	assert.Equal(t, "connection refused", report.Checks["mongodb"].Error)
+}
+
+func TestHealthReportIsCached(t *testing.T) {
+	// Given
+	var calls atomic.Int32
+	registry := health.NewRegistry(time.Second, time.Minute)
+	registry.Register(health.Liveness, "counter", health.CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	// When
+	first := registry.Run(context.Background(), health.Liveness)
+	second := registry.Run(context.Background(), health.Liveness)
+
+	// Then
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, first.CheckedAt, second.CheckedAt)
+}
+
+func TestHealthCheckTimesOut(t *testing.T) {
+	// Given
+	registry := health.NewRegistry(20*time.Millisecond, time.Millisecond)
+	registry.Register(health.Readiness, "mongodb", health.CheckerFunc(func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	// When
+	start := time.Now()
+	report := registry.Run(context.Background(), health.Readiness)
+
+	// Then
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["mongodb"].Error)
+}
+
+func TestHeartbeatFailsWhenStale(t *testing.T) {
+	// Given
+	heartbeat := health.NewHeartbeat(10 * time.Millisecond)
+
+	// When
+	fresh := heartbeat.Check(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	stale := heartbeat.Check(context.Background())
+	heartbeat.Beat()
+	beaten := heartbeat.Check(context.Background())
+
+	// Then
+	assert.NoError(t, fresh)
+	assert.Error(t, stale)
+	assert.NoError(t, beaten)
+}
+
+func TestReadinessFailsAfterShutdown(t *testing.T) {
+	// Given
+	registry := health.NewRegistry(time.Second, time.Minute)
+	registry.Register(health.Readiness, "config", health.CheckerFunc(func(context.Context) error { return nil }))
+	assert.Equal(t, health.StatusPass, registry.Run(context.Background(), health.Readiness).Status)
+
+	// When
+	registry.Shutdown()
+
+	// Then
+	readiness := registry.Run(context.Background(), health.Readiness)
+	assert.Equal(t, health.StatusFail, readiness.Status)
+	assert.Equal(t, health.StatusFail, readiness.Checks["shutdown"].Status)
+	assert.Equal(t, health.StatusPass, registry.Run(context.Background(), health.Liveness).Status)
+}
+
+func TestReadyzReturnsReport(t *testing.T) {
+	// Given
+	registry := health.NewRegistry(time.Second, time.Millisecond)
+	registry.Register(health.Readiness, "mongodb", health.CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+	serverController := controller.NewServerController(service.NewServerService(registry))
+	w := httptest.NewRecorder()
+
+	// When
+	serverController.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// Then
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var report health.Report
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["mongodb"].Error)
+}
+
+func TestLivezPassesWithoutChecks(t *testing.T) {
+	// Given
+	serverController := controller.NewServerController(service.NewServerService(health.NewRegistry(0, 0)))
+	w := httptest.NewRecorder()
+
+	// When
+	serverController.Livez(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report health.Report
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, health.StatusPass, report.Status)
+	assert.Empty(t, report.Checks)
+}
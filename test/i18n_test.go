@@ -0,0 +1,48 @@
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/i18n"
+	"testing"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	testCases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: "en"},
+		{acceptLanguage: "th", expected: "th"},
+		{acceptLanguage: "th-TH,th;q=0.9,en;q=0.8", expected: "th"},
+		{acceptLanguage: "en-US,th;q=0.5", expected: "en"},
+		{acceptLanguage: "fr-FR,th;q=0.3", expected: "th"},
+		{acceptLanguage: "de, fr;q=0.9", expected: "en"},
+		{acceptLanguage: "en;q=0.2, th;q=0.8", expected: "th"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.acceptLanguage, func(t *testing.T) {
+			assert.Equal(t, tc.expected, i18n.Negotiate(tc.acceptLanguage))
+		})
+	}
+}
+
+func TestTranslateWithParams(t *testing.T) {
+	// When
+	message, ok := i18n.Translate("th", "USER_NOT_FOUND", map[string]string{"id": "42"})
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "ไม่พบผู้ใช้รหัส 42", message)
+}
+
+func TestTranslateFallsBackToDefaultLocale(t *testing.T) {
+	// When
+	message, ok := i18n.Translate("xx", "INVALID_CREDENTIALS", nil)
+	_, unknown := i18n.Translate("th", "NOT_A_CODE", nil)
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "invalid email or password", message)
+	assert.False(t, unknown)
+}
@@ -0,0 +1,323 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/idempotency"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var idempotencyOptions = middleware.IdempotencyOptions{TTL: time.Hour, LockTTL: time.Minute}
+
+func newIdempotentRequest(method string, key string, body string) *http.Request {
+	r := httptest.NewRequest(method, "/api/v1/users/register", strings.NewReader(body))
+	r.Header.Set(middleware.HeaderIdempotencyKey, key)
+	return r
+}
+
+func TestIdempotencyMiddlewareReplaysResponse(t *testing.T) {
+	// Given
+	calls := 0
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, newIdempotentRequest(http.MethodPost, "key-1", `{"name":"Test User"}`))
+
+	// When
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, newIdempotentRequest(http.MethodPost, "key-1", `{"name":"Test User"}`))
+
+	// Then
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(middleware.HeaderIdempotencyReplayed))
+	assert.Empty(t, first.Header().Get(middleware.HeaderIdempotencyReplayed))
+}
+
+func TestIdempotencyMiddlewareRejectsDifferentPayload(t *testing.T) {
+	// Given
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		codec.ResponseWithSuccess(w, r, "ok")
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(http.MethodPost, "key-1", `{"name":"Test User"}`))
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, "key-1", `{"name":"Other User"}`))
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, apperror.CodeIdempotencyKeyReused, problem.Code)
+}
+
+func TestIdempotencyMiddlewareRejectsInFlightDuplicate(t *testing.T) {
+	// Given
+	store := idempotency.NewMemoryStore()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := middleware.IdempotencyMiddleware(store, idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		codec.ResponseWithSuccess(w, r, "ok")
+	})
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+		close(done)
+	}()
+	<-started
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+	close(release)
+	<-done
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, apperror.CodeIdempotencyInProgress, problem.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestIdempotencyMiddlewareDoesNotStoreServerError(t *testing.T) {
+	// Given
+	calls := 0
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		codec.ResponseWithSuccess(w, r, "ok")
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// Then
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.HeaderIdempotencyReplayed))
+}
+
+func TestIdempotencyMiddlewareScopesKeyToPrincipal(t *testing.T) {
+	// Given
+	calls := 0
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		codec.ResponseWithSuccess(w, r, "ok")
+	})
+	userA := newIdempotentRequest(http.MethodPost, "key-1", `{}`)
+	userA = userA.WithContext(context.WithValue(userA.Context(), constant.CONTEXT_KEY_USER_ID, "user-a"))
+	userB := newIdempotentRequest(http.MethodPost, "key-1", `{}`)
+	userB = userB.WithContext(context.WithValue(userB.Context(), constant.CONTEXT_KEY_USER_ID, "user-b"))
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), userA)
+	handler.ServeHTTP(httptest.NewRecorder(), userB)
+
+	// Then
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddlewareInvalidKey(t *testing.T) {
+	// Given
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, strings.Repeat("k", 256), `{}`))
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apperror.CodeInvalidIdempotencyKey, problem.Code)
+}
+
+func TestMemoryStoreExpiresRecords(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore()
+	_, err := store.Begin(ctx, "key-1", "fingerprint", time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Complete(ctx, "key-1", http.StatusOK, http.Header{}, []byte("ok"), "", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	// When
+	record, err := store.Begin(ctx, "key-1", "fingerprint", time.Hour)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestMemoryStoreExpiresUnfinishedLock(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore()
+	_, err := store.Begin(ctx, "key-1", "fingerprint", time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// When
+	record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestMemoryStoreKeepsCompletedRecordPastLock(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore()
+	_, err := store.Begin(ctx, "key-1", "fingerprint", time.Millisecond)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Complete(ctx, "key-1", http.StatusOK, http.Header{}, []byte("ok"), "", time.Hour))
+	time.Sleep(5 * time.Millisecond)
+
+	// When
+	record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+
+	// Then
+	assert.NoError(t, err)
+	if assert.NotNil(t, record) {
+		assert.Equal(t, []byte("ok"), record.Body)
+	}
+}
+
+func TestIdempotencyMiddlewareDoesNotStoreNoStoreResponse(t *testing.T) {
+	// Given
+	calls := 0
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "no-store")
+		codec.ResponseWithSuccess(w, r, map[string]string{"accessToken": "secret"})
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// Then
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, w.Header().Get(middleware.HeaderIdempotencyReplayed))
+}
+
+func TestIdempotencyMiddlewareReissuesNoStoreResponseWithSubject(t *testing.T) {
+	// Given
+	replays := []idempotency.Reissue{}
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		reissue, ok := idempotency.ReissueFromContext(r.Context())
+		assert.True(t, ok)
+		replays = append(replays, *reissue)
+		reissue.Subject = "user-1"
+		w.Header().Set("Cache-Control", "no-store")
+		codec.ResponseWithSuccess(w, r, map[string]string{"accessToken": "token-" + strconv.Itoa(len(replays))})
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newIdempotentRequest(http.MethodPost, "key-1", `{}`))
+
+	// Then
+	assert.Equal(t, []idempotency.Reissue{{}, {Subject: "user-1", Replay: true}}, replays)
+	assert.Equal(t, "true", w.Header().Get(middleware.HeaderIdempotencyReplayed))
+	assert.Contains(t, w.Body.String(), "token-2", "the replay carries a fresh token")
+}
+
+func TestRetriedRegisterReturnsUserWithFreshToken(t *testing.T) {
+	// Given
+	database := openSQLiteStore(t)
+	userRepository := repository.NewSQLiteUserRepository(database)
+	userService := service.NewUserService(userRepository, repository.NewSQLOutboxRepository(database, config.UserStoreSQLite), repository.NewSQLTransactor(database))
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(controller.NewUserController(userService).UserRegister)
+	body := `{"name":"Test User","email":"test@example.com","password":"password123"}`
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, newIdempotentRequest(http.MethodPost, "key-1", body))
+
+	// When
+	retried := httptest.NewRecorder()
+	handler.ServeHTTP(retried, newIdempotentRequest(http.MethodPost, "key-1", body))
+
+	// Then
+	var registered, replayed struct {
+		Data dto.UserRegisterResponse `json:"data"`
+	}
+	assert.NoError(t, encodingjson.NewDecoder(first.Body).Decode(&registered))
+	assert.NoError(t, encodingjson.NewDecoder(retried.Body).Decode(&replayed))
+	assert.Equal(t, http.StatusOK, retried.Code)
+	assert.Equal(t, "true", retried.Header().Get(middleware.HeaderIdempotencyReplayed))
+	assert.Equal(t, "no-store", retried.Header().Get("Cache-Control"))
+	assert.Equal(t, registered.Data.ID, replayed.Data.ID)
+	assert.Equal(t, "test@example.com", replayed.Data.Email)
+	claim, err := jwt.ValidateJwt(replayed.Data.AccessToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, registered.Data.ID, claim.Subject)
+	}
+	count, err := userRepository.CountUsers(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestIdempotencyMiddlewareScopesAnonymousKeyToClientIP(t *testing.T) {
+	// Given
+	calls := 0
+	handler := middleware.IdempotencyMiddleware(idempotency.NewMemoryStore(), idempotencyOptions)(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		codec.ResponseWithSuccess(w, r, "ok")
+	})
+	clientA := newIdempotentRequest(http.MethodPost, "key-1", `{}`)
+	clientA.RemoteAddr = "192.0.2.1:1234"
+	clientB := newIdempotentRequest(http.MethodPost, "key-1", `{}`)
+	clientB.RemoteAddr = "192.0.2.2:1234"
+	retryA := newIdempotentRequest(http.MethodPost, "key-1", `{}`)
+	retryA.RemoteAddr = "192.0.2.1:5678"
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), clientA)
+	handler.ServeHTTP(httptest.NewRecorder(), clientB)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, retryA)
+
+	// Then
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "true", w.Header().Get(middleware.HeaderIdempotencyReplayed))
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJwtMiddlewareRequiresBearerScheme(t *testing.T) {
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{name: "bearer token", authorization: "Bearer " + token, status: http.StatusOK},
+		{name: "token without scheme", authorization: token, status: http.StatusUnauthorized},
+		{name: "other scheme", authorization: "Basic " + token, status: http.StatusUnauthorized},
+		{name: "empty token", authorization: "Bearer ", status: http.StatusUnauthorized},
+		{name: "missing", authorization: "", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			handler := middleware.JwtMiddleware(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil)
+			r.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+
+			// When
+			handler(w, r)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureLogs sends the logs to a buffer until the test ends.
+func captureLogs(t *testing.T, options logger.Options) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, logger.Configure(options, buffer))
+	t.Cleanup(func() {
+		logger.Configure(logger.Options{}, os.Stderr)
+	})
+	return buffer
+}
+
+func decodeLogs(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		assert.NoError(t, encodingjson.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerRedactsPersonalData(t *testing.T) {
+	// Given
+	buffer := captureLogs(t, logger.Options{})
+	log := logger.New("test")
+
+	// When
+	log.Info("user registered", "email", "test@example.com", "Password", "secret",
+		"error", errors.New(`duplicate key { email: "test@example.com" }`))
+
+	// Then
+	records := decodeLogs(t, buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, logger.Redacted, records[0]["email"])
+	assert.Equal(t, logger.Redacted, records[0]["Password"])
+	assert.Equal(t, `duplicate key { email: "[REDACTED]" }`, records[0]["error"])
+	assert.NotContains(t, buffer.String(), "test@example.com")
+}
+
+func TestLoggerPackageLevel(t *testing.T) {
+	// Given
+	buffer := captureLogs(t, logger.Options{Level: "warn", Packages: map[string]string{"repository": "debug"}})
+
+	// When
+	logger.New("repository").Debug("query")
+	logger.New("service").Info("hidden")
+	logger.New("service").Warn("shown")
+
+	// Then
+	records := decodeLogs(t, buffer)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "query", records[0]["msg"])
+	assert.Equal(t, "repository", records[0]["package"])
+	assert.Equal(t, "shown", records[1]["msg"])
+}
+
+func TestLoggerConfigureRejectsUnknownLevel(t *testing.T) {
+	// When
+	err := logger.Configure(logger.Options{Packages: map[string]string{"service": "verbose"}}, &bytes.Buffer{})
+
+	// Then
+	assert.EqualError(t, err, `package service: unknown log level "verbose"`)
+}
+
+func TestLoggerAddsRequestFields(t *testing.T) {
+	// Given
+	buffer := captureLogs(t, logger.Options{})
+	ctx := logger.NewContext(context.Background(), "request-1", http.MethodGet)
+	logger.SetRoute(ctx, "GET /api/v1/users/get/me")
+	logger.SetUserID(ctx, "user-1")
+
+	// When
+	logger.New("test").InfoContext(ctx, "handled")
+
+	// Then
+	records := decodeLogs(t, buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "request-1", records[0]["request_id"])
+	assert.Equal(t, http.MethodGet, records[0]["method"])
+	assert.Equal(t, "GET /api/v1/users/get/me", records[0]["route"])
+	assert.Equal(t, "user-1", records[0]["user_id"])
+}
+
+func TestLoggingMiddlewareWritesAccessLog(t *testing.T) {
+	// Given
+	buffer := captureLogs(t, logger.Options{})
+	handler := middleware.RequestIDMiddleware(middleware.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.SetRoute(r.Context(), "POST /api/v1/users/register")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	})))
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", nil)
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	records := decodeLogs(t, buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "request completed", records[0]["msg"])
+	assert.Equal(t, float64(http.StatusCreated), records[0]["status"])
+	assert.Equal(t, float64(len("created")), records[0]["bytes"])
+	assert.Equal(t, "POST /api/v1/users/register", records[0]["route"])
+	assert.NotEmpty(t, records[0]["request_id"])
+	assert.Contains(t, records[0], "duration_ms")
+}
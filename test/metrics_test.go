@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/metrics"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// scrapeMetric returns the value of series from the metrics endpoint, 0 when it is not exposed yet.
+func scrapeMetric(t *testing.T, series string) float64 {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, series+" "); ok {
+			number, err := strconv.ParseFloat(value, 64)
+			assert.NoError(t, err)
+			return number
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddlewareCountsRequestsByRoute(t *testing.T) {
+	// Given
+	handler := middleware.LoggingMiddleware(middleware.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.SetRoute(r.Context(), "GET /api/v1/users/{id}")
+		w.WriteHeader(http.StatusNotModified)
+	})))
+	series := `http_requests_total{route="GET /api/v1/users/{id}",status="304"}`
+	before := scrapeMetric(t, series)
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/2", nil))
+
+	// Then
+	assert.Equal(t, before+2, scrapeMetric(t, series))
+	assert.Equal(t, before+2, scrapeMetric(t, `http_request_duration_seconds_count{route="GET /api/v1/users/{id}",status="304"}`))
+}
+
+func TestMetricsMiddlewareGroupsUnmatchedRoutes(t *testing.T) {
+	// Given
+	handler := middleware.LoggingMiddleware(middleware.MetricsMiddleware(http.NotFoundHandler()))
+	series := `http_requests_total{route="unmatched",status="404"}`
+	before := scrapeMetric(t, series)
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/random/path", nil))
+
+	// Then
+	assert.Equal(t, before+1, scrapeMetric(t, series))
+}
+
+func TestLoginUserCountsFailure(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userService := newUserService(t, mockUserRepository)
+	mockUserRepository.On("GetUserByEmail", ctx, "test@example.com").Return(entity.User{}, repository.ErrNotFound)
+	before := scrapeMetric(t, `user_logins_total{result="failure"}`)
+
+	// When
+	_, err := userService.LoginUser(ctx, dto.UserLoginRequest{Email: "test@example.com", Password: "password123"})
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, before+1, scrapeMetric(t, `user_logins_total{result="failure"}`))
+}
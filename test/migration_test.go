@@ -0,0 +1,287 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/migration"
+	mock_lock_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/lock_repository_mock"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// memoryMigrationStore keeps the migration records of a test.
+type memoryMigrationStore struct {
+	records []migration.Record
+}
+
+func (s *memoryMigrationStore) Applied(context.Context) ([]migration.Record, error) {
+	return slices.Clone(s.records), nil
+}
+
+func (s *memoryMigrationStore) Add(_ context.Context, record migration.Record) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryMigrationStore) Remove(_ context.Context, version int) error {
+	s.records = slices.DeleteFunc(s.records, func(record migration.Record) bool { return record.Version == version })
+	return nil
+}
+
+func (s *memoryMigrationStore) versions() []int {
+	var versions []int
+	for _, record := range s.records {
+		versions = append(versions, record.Version)
+	}
+	return versions
+}
+
+// recordedMigration appends "up <version>" or "down <version>" to steps.
+func recordedMigration(version int, name string, steps *[]string) migration.Migration {
+	return migration.Migration{
+		Version: version,
+		Name:    name,
+		Up: func(context.Context, migration.Schema) error {
+			*steps = append(*steps, "up "+name)
+			return nil
+		},
+		Down: func(context.Context, migration.Schema) error {
+			*steps = append(*steps, "down "+name)
+			return nil
+		},
+	}
+}
+
+func TestMigratorAppliesPendingMigrationsInOrder(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var steps []string
+	store := &memoryMigrationStore{records: []migration.Record{{Version: 1, Name: "first"}}}
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, time.Minute).Return(true, nil)
+	mockLockRepository.On("ReleaseLease", mock.Anything, "migrations", mock.Anything).Return(nil).Once()
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(3, "third", &steps),
+		recordedMigration(1, "first", &steps),
+		recordedMigration(2, "second", &steps),
+	}, nil, store, mockLockRepository, migration.Options{})
+	assert.NoError(t, err)
+
+	// When
+	applied, err := migrator.Up(ctx, 0)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []string{"up second", "up third"}, steps)
+	assert.Equal(t, []int{1, 2, 3}, store.versions())
+}
+
+func TestMigratorAppliesUpToTarget(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var steps []string
+	store := &memoryMigrationStore{}
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, mock.Anything).Return(true, nil)
+	mockLockRepository.On("ReleaseLease", mock.Anything, "migrations", mock.Anything).Return(nil)
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(1, "first", &steps),
+		recordedMigration(2, "second", &steps),
+		recordedMigration(3, "third", &steps),
+	}, nil, store, mockLockRepository, migration.Options{})
+	assert.NoError(t, err)
+
+	// When
+	_, err = migrator.Up(ctx, 2)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"up first", "up second"}, steps)
+	_, err = migrator.Up(ctx, 4)
+	assert.EqualError(t, err, "there is no migration 4")
+}
+
+func TestMigratorWaitsForLock(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var steps []string
+	store := &memoryMigrationStore{}
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	// another replica migrates while this one waits
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		store.records = []migration.Record{{Version: 1, Name: "first"}}
+	}).Return(false, nil).Once()
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, mock.Anything).Return(true, nil)
+	mockLockRepository.On("ReleaseLease", mock.Anything, "migrations", mock.Anything).Return(nil)
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(1, "first", &steps),
+	}, nil, store, mockLockRepository, migration.Options{LockTTL: 30 * time.Millisecond})
+	assert.NoError(t, err)
+
+	// When
+	applied, err := migrator.Up(ctx, 0)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Empty(t, steps)
+}
+
+func TestMigratorRevertsNewestFirst(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var steps []string
+	store := &memoryMigrationStore{records: []migration.Record{{Version: 1}, {Version: 2}, {Version: 3}}}
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, mock.Anything).Return(true, nil)
+	mockLockRepository.On("ReleaseLease", mock.Anything, "migrations", mock.Anything).Return(nil)
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(1, "first", &steps),
+		recordedMigration(2, "second", &steps),
+		recordedMigration(3, "third", &steps),
+	}, nil, store, mockLockRepository, migration.Options{})
+	assert.NoError(t, err)
+
+	// When
+	reverted, err := migrator.Down(ctx, 2)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, reverted, 2)
+	assert.Equal(t, []string{"down third", "down second"}, steps)
+	assert.Equal(t, []int{1}, store.versions())
+}
+
+func TestMigratorRefusesIrreversibleDown(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var steps []string
+	irreversible := recordedMigration(1, "first", &steps)
+	irreversible.Down = nil
+	store := &memoryMigrationStore{records: []migration.Record{{Version: 1, Name: "first"}, {Version: 2, Name: "second"}}}
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockLockRepository.On("AcquireLease", mock.Anything, "migrations", mock.Anything, mock.Anything).Return(true, nil)
+	mockLockRepository.On("ReleaseLease", mock.Anything, "migrations", mock.Anything).Return(nil)
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		irreversible,
+		recordedMigration(2, "second", &steps),
+	}, nil, store, mockLockRepository, migration.Options{})
+	assert.NoError(t, err)
+
+	// When
+	_, err = migrator.Down(ctx, 2)
+
+	// Then
+	assert.EqualError(t, err, "migration 1 first cannot be reverted")
+	assert.Empty(t, steps, "nothing is reverted")
+	assert.Equal(t, []int{1, 2}, store.versions())
+}
+
+func TestNewMigratorRejectsDuplicateVersions(t *testing.T) {
+	// Given
+	var steps []string
+
+	// When
+	_, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(1, "first", &steps),
+		recordedMigration(1, "other", &steps),
+	}, nil, &memoryMigrationStore{}, nil, migration.Options{})
+
+	// Then
+	assert.EqualError(t, err, `migrations "first" and "other" have the same version 1`)
+}
+
+func TestMigrationStatusListsUnknownVersions(t *testing.T) {
+	// Given
+	var steps []string
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memoryMigrationStore{records: []migration.Record{{Version: 1, Name: "first", AppliedAt: appliedAt}, {Version: 9, Name: "newer", AppliedAt: appliedAt}}}
+	migrator, err := migration.NewMigrator([]migration.Migration{
+		recordedMigration(1, "first", &steps),
+		recordedMigration(2, "second", &steps),
+	}, nil, store, nil, migration.Options{})
+	assert.NoError(t, err)
+
+	// When
+	statuses, err := migrator.Status(context.Background())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []migration.Status{
+		{Version: 1, Name: "first", AppliedAt: appliedAt},
+		{Version: 2, Name: "second"},
+		{Version: 9, Name: "newer", AppliedAt: appliedAt, Unknown: true},
+	}, statuses)
+}
+
+func TestMigrationDryRunPrintsInitialSchema(t *testing.T) {
+	// Given
+	store := &memoryMigrationStore{}
+	var out bytes.Buffer
+	// the lock is not taken and nothing is recorded
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	migrator, err := migration.NewMigrator(migration.Migrations(), nil, store, mockLockRepository, migration.Options{DryRun: &out})
+	assert.NoError(t, err)
+
+	// When
+	pending, err := migrator.Up(context.Background(), 0)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Empty(t, store.records)
+	assert.Equal(t, `apply 1 initial_schema
+  create collection users
+  create index unique_email_index {email: 1} unique on users
+  create index events_index {events: 1} on webhooks
+  create index due_deliveries_index {status: 1, next_attempt_at: 1} on webhook_deliveries
+  create index unique_webhook_event_index {webhook_id: 1, event_id: 1} unique on webhook_deliveries
+  create index pending_events_index {created_at: 1, _id: 1} on outbox
+`, out.String())
+}
+
+func TestSQLMigratorAppliesAndRevertsUserStoreSchema(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	database, err := db.OpenSQL(ctx, config.UserStoreConfig{
+		Backend: config.UserStoreSQLite,
+		SQLite:  config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "users.db")},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { database.Close() })
+	var out bytes.Buffer
+	dryRun, err := migration.NewSQLMigrator(database, config.UserStoreSQLite, &out)
+	assert.NoError(t, err)
+	migrator, err := migration.NewSQLMigrator(database, config.UserStoreSQLite, nil)
+	assert.NoError(t, err)
+
+	// When
+	planned, errDryRun := dryRun.Up(ctx, 0)
+	pendingAfterDryRun, errPending := migrator.Pending(ctx)
+	applied, errUp := migrator.Up(ctx, 0)
+	statuses, errStatus := migrator.Status(ctx)
+	reverted, errDown := migrator.Down(ctx, 2)
+	_, errQuery := database.Exec("SELECT 1 FROM users")
+	_, errOutboxQuery := database.Exec("SELECT 1 FROM outbox")
+
+	// Then
+	assert.NoError(t, errors.Join(errDryRun, errPending, errUp, errStatus, errDown))
+	assert.Len(t, planned, 2)
+	assert.Contains(t, out.String(), "apply 1 create_users\n  CREATE TABLE users (")
+	assert.Contains(t, out.String(), "apply 2 create_outbox\n  CREATE TABLE outbox (")
+	assert.Len(t, pendingAfterDryRun, 2, "a dry run applies nothing")
+	assert.Len(t, applied, 2)
+	assert.Len(t, statuses, 2)
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+	assert.Len(t, reverted, 2)
+	assert.ErrorContains(t, errQuery, "no such table: users")
+	assert.ErrorContains(t, errOutboxQuery, "no such table: outbox")
+}
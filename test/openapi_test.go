@@ -0,0 +1,127 @@
+package test
+
+import (
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/openapi"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_server_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/server_service_mock"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingRouter keeps the registered handlers by pattern.
+type recordingRouter struct {
+	handlers map[string]func(http.ResponseWriter, *http.Request)
+}
+
+func (r *recordingRouter) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.handlers[pattern] = handler
+}
+
+func registerRecordedRoutes(t *testing.T) *recordingRouter {
+	router := &recordingRouter{handlers: map[string]func(http.ResponseWriter, *http.Request){}}
+	svc := &service.Service{
+		ServerService: mock_server_service.NewServerService(t),
+		UserService:   mock_user_service.NewUserService(t),
+	}
+	identity := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	assert.NoError(t, controller.RegisterRoutes(router, svc, controller.RouteOptions{Idempotent: identity}))
+	return router
+}
+
+func TestOpenAPIDocumentsEveryRegisteredRoute(t *testing.T) {
+	// Given
+	router := registerRecordedRoutes(t)
+
+	// When
+	document, err := controller.OpenAPIDocument()
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, router.handlers)
+	for pattern := range router.handlers {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !assert.True(t, ok, "route %q has no method", pattern) {
+			continue
+		}
+		operation := document.Paths[path][strings.ToLower(method)]
+		assert.NotNil(t, operation, "route %q is missing from the OpenAPI document", pattern)
+	}
+
+	documented := 0
+	for _, item := range document.Paths {
+		documented += len(item)
+	}
+	assert.Equal(t, len(router.handlers), documented, "the OpenAPI document has routes that are not registered")
+}
+
+func TestOpenAPIDocumentSchemas(t *testing.T) {
+	// When
+	document, err := controller.OpenAPIDocument()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, openapi.Version, document.OpenAPI)
+
+	register := document.Components.Schemas["UserRegisterRequest"]
+	if assert.NotNil(t, register) {
+		assert.Equal(t, []string{"email", "name", "password"}, register.Required)
+		assert.Equal(t, "email", register.Properties["email"].Format)
+		assert.Equal(t, []interface{}{"en", "th"}, register.Properties["locale"].Enum)
+		assert.Equal(t, 8, *register.Properties["password"].MinLength)
+	}
+
+	getMe := document.Paths["/api/v1/users/get/me"]["get"]
+	if assert.NotNil(t, getMe) {
+		assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, getMe.Security)
+		assert.Equal(t, "#/components/schemas/Problem", getMe.Responses["401"].Content["application/problem+json"].Schema.Ref)
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	// Given
+	router := registerRecordedRoutes(t)
+	w := httptest.NewRecorder()
+
+	// When
+	router.handlers["GET /openapi.json"](w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	// Then
+	var document openapi.Document
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&document))
+	assert.Contains(t, document.Paths, "/api/v1/users/register")
+}
+
+func TestDocsServesEmbeddedSwaggerUI(t *testing.T) {
+	// Given
+	mux := http.NewServeMux()
+	svc := &service.Service{
+		ServerService: mock_server_service.NewServerService(t),
+		UserService:   mock_user_service.NewUserService(t),
+	}
+	identity := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	assert.NoError(t, controller.RegisterRoutes(mux, svc, controller.RouteOptions{Idempotent: identity}))
+
+	// When
+	page := httptest.NewRecorder()
+	mux.ServeHTTP(page, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	bundle := httptest.NewRecorder()
+	mux.ServeHTTP(bundle, httptest.NewRequest(http.MethodGet, "/docs/swagger-ui-bundle.js", nil))
+	missing := httptest.NewRecorder()
+	mux.ServeHTTP(missing, httptest.NewRequest(http.MethodGet, "/docs/missing.js", nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, page.Code)
+	assert.NotContains(t, page.Body.String(), "https://")
+	assert.Contains(t, page.Body.String(), `src="/docs/swagger-ui-bundle.js"`)
+	assert.Equal(t, http.StatusOK, bundle.Code)
+	assert.Contains(t, bundle.Header().Get("Content-Type"), "javascript")
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
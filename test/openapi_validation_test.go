@@ -0,0 +1,260 @@
+package test
+
+import (
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/openapi"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newOpenAPIValidationHandler(t *testing.T, options middleware.OpenAPIValidationOptions, handler http.HandlerFunc) http.Handler {
+	document, err := controller.OpenAPIDocument()
+	assert.NoError(t, err)
+	return middleware.OpenAPIValidationMiddleware(openapi.NewValidator(document), options)(handler)
+}
+
+func authorize(t *testing.T, r *http.Request, role string) {
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", role)
+	assert.NoError(t, err)
+	r.Header.Set("Authorization", "Bearer "+token)
+}
+
+func TestOpenAPIValidationRejectsInvalidBody(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", strings.NewReader(`{"email":"not-an-email","password":"short","locale":"fr"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apperror.CodeValidationFailed, problem.Code)
+	assert.ElementsMatch(t, []apperror.FieldError{
+		{Field: "name", Code: apperror.CodeFieldRequired, Message: "name is required"},
+		{Field: "email", Code: apperror.CodeFieldInvalidEmail, Message: "email must be a valid email address"},
+		{Field: "password", Code: apperror.CodeFieldTooShort, Message: "password must be at least 8 characters"},
+		{Field: "locale", Code: apperror.CodeFieldNotAllowed, Message: "locale must be one of: en, th"},
+	}, problem.Errors)
+}
+
+func TestOpenAPIValidationAuthenticatesBeforeValidating(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authorization string
+	}{
+		{name: "missing token"},
+		{name: "invalid token", authorization: "Bearer invalid"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("handler should not be called")
+			})
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/users/update", strings.NewReader(`{"email":"not-an-email"}`))
+			r.Header.Set("Content-Type", "application/json")
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			// When
+			handler.ServeHTTP(w, r)
+
+			// Then
+			var problem json.Problem
+			assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, apperror.CodeUnauthorized, problem.Code)
+		})
+	}
+}
+
+func TestOpenAPIValidationRejectsTooLargeBody(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	body := `{"email":"test@example.com","password":"` + strings.Repeat("a", cfg.RestServer.MaxBodySize) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, apperror.CodeRequestBodyTooLarge, problem.Code)
+}
+
+func TestOpenAPIValidationRejectsWrongType(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/list", strings.NewReader(`{"page":"1","limit":1.5}`))
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.ElementsMatch(t, []apperror.FieldError{
+		{Field: "limit", Code: apperror.CodeFieldInvalidType, Message: "limit must be of type integer"},
+		{Field: "page", Code: apperror.CodeFieldInvalidType, Message: "page must be of type integer"},
+	}, problem.Errors)
+}
+
+func TestOpenAPIValidationRejectsUnsupportedContentType(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/login", strings.NewReader(`email=test@example.com`))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestOpenAPIValidationPassesValidRequest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		auth        bool
+	}{
+		{name: "json body", method: http.MethodPost, path: "/api/v1/users/login", contentType: "application/json; charset=utf-8", body: `{"email":"test@example.com","password":"password123"}`},
+		{name: "missing content type", method: http.MethodPost, path: "/api/v1/users/login", body: `{"email":"test@example.com","password":"password123"}`},
+		{name: "merge patch removing a member", method: http.MethodPatch, path: "/api/v1/users/me", contentType: patch.ContentTypeMergePatch, body: `{"name":"Patched Name","locale":null}`, auth: true},
+		{name: "json patch", method: http.MethodPatch, path: "/api/v1/users/me", contentType: patch.ContentTypeJSONPatch, body: `[{"op":"replace","path":"/name","value":"Patched Name"}]`, auth: true},
+		{name: "undocumented route", method: http.MethodPost, path: "/api/v1/unknown", contentType: "text/plain", body: `anything`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			called := false
+			handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			})
+			r := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.auth {
+				authorize(t, r, "")
+			}
+
+			// When
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			// Then
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestOpenAPIValidationReportsInvalidResponse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+		valid   bool
+	}{
+		{
+			name: "documented payload",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				codec.ResponseWithSuccess(w, r, dto.UserGetMeResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com"})
+			},
+			valid: true,
+		},
+		{
+			name: "undocumented payload",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				codec.ResponseWithSuccess(w, r, map[string]interface{}{"id": 1})
+			},
+		},
+		{
+			name: "undocumented status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			var invalid error
+			handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{
+				ValidateResponses: true,
+				OnInvalidResponse: func(r *http.Request, err error) { invalid = err },
+			}, tc.handler)
+
+			// When
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil))
+
+			// Then
+			if tc.valid {
+				assert.NoError(t, invalid)
+			} else {
+				assert.Error(t, invalid)
+			}
+		})
+	}
+}
+
+func TestOpenAPIValidationChecksArrayItems(t *testing.T) {
+	// Given
+	handler := newOpenAPIValidationHandler(t, middleware.OpenAPIValidationOptions{ValidateRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"https://example.com/hooks","events":["user.created","user.banned"]}`))
+	r.Header.Set("Content-Type", "application/json")
+	authorize(t, r, entity.RoleAdmin)
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "events[1]", Code: apperror.CodeFieldNotAllowed, Message: "events[1] must be one of: user.created, user.updated, user.deleted"},
+	}, problem.Errors)
+}
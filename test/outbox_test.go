@@ -0,0 +1,402 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_lock_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/lock_repository_mock"
+	mock_outbox_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/outbox_repository_mock"
+	mock_transactor "github.com/taninchot-work/backend-challenge/internal/repository/mocks/transactor_mock"
+	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_event_publisher "github.com/taninchot-work/backend-challenge/internal/service/mocks/event_publisher_mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"testing"
+	"time"
+)
+
+// newUserService returns a user service whose transactions and outbox writes succeed.
+func newUserService(t *testing.T, userRepository repository.UserRepository) service.UserService {
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockOutboxRepository.On("SaveEvent", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockTransactor.On("WithTransaction", mock.Anything, mock.Anything).Return(runInTransaction).Maybe()
+	return service.NewUserService(userRepository, mockOutboxRepository, mockTransactor)
+}
+
+func newOutboxEvent(aggregateID string, sequence int64, eventType string) entity.OutboxEvent {
+	return entity.OutboxEvent{
+		Event:       entity.Event{ID: bson.NewObjectID().Hex(), Type: eventType, Data: entity.UserSnapshot{ID: aggregateID}},
+		AggregateID: aggregateID,
+		Sequence:    sequence,
+	}
+}
+
+// transactionKey marks the context of a transaction started by inTransaction.
+type transactionKey struct{}
+
+// inTransaction runs fn like a transactor, with a context the repository mocks
+// can tell apart from the one outside the transaction.
+func inTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(context.WithValue(ctx, transactionKey{}, true))
+}
+
+var transactionContext = mock.MatchedBy(func(ctx context.Context) bool {
+	inTransaction, _ := ctx.Value(transactionKey{}).(bool)
+	return inTransaction
+})
+
+func TestUserServiceSavesEveryChangeWithItsEventInOneTransaction(t *testing.T) {
+	user := entity.User{ID: bson.NewObjectID(), Name: "Test User", Email: "test@example.com", Version: 1}
+	updated := user
+	updated.Name = "New Name"
+	updated.Version = 2
+
+	testCases := []struct {
+		name      string
+		eventType string
+		setup     func(mockUserRepository *mock_user_repository.UserRepository)
+		change    func(userService service.UserService) error
+	}{
+		{
+			name:      "register",
+			eventType: entity.EventUserCreated,
+			setup: func(mockUserRepository *mock_user_repository.UserRepository) {
+				mockUserRepository.On("SaveUser", transactionContext, mock.Anything).Return(func(ctx context.Context, user entity.User) (entity.User, error) {
+					return user, nil
+				}).Once()
+			},
+			change: func(userService service.UserService) error {
+				_, err := userService.RegisterUser(context.Background(), dto.UserRegisterRequest{Name: "Test User", Email: "test@example.com", Password: "password123"})
+				return err
+			},
+		},
+		{
+			name:      "update",
+			eventType: entity.EventUserUpdated,
+			setup: func(mockUserRepository *mock_user_repository.UserRepository) {
+				mockUserRepository.On("GetUserById", mock.Anything, user.ID.Hex()).Return(user, nil)
+				mockUserRepository.On("UpdateUser", transactionContext, user.ID.Hex(), int64(1), mock.Anything).Return(updated, nil).Once()
+			},
+			change: func(userService service.UserService) error {
+				_, err := userService.UpdateUser(context.Background(), user.ID.Hex(), dto.UserUpdateRequest{Name: "New Name", Email: user.Email})
+				return err
+			},
+		},
+		{
+			name:      "patch",
+			eventType: entity.EventUserUpdated,
+			setup: func(mockUserRepository *mock_user_repository.UserRepository) {
+				mockUserRepository.On("GetUserById", mock.Anything, user.ID.Hex()).Return(user, nil)
+				mockUserRepository.On("UpdateUser", transactionContext, user.ID.Hex(), int64(1), mock.Anything).Return(updated, nil).Once()
+			},
+			change: func(userService service.UserService) error {
+				_, err := userService.PatchUser(context.Background(), user.ID.Hex(), dto.UserPatchRequest{
+					ContentType: patch.ContentTypeMergePatch,
+					Patch:       []byte(`{"name":"New Name"}`),
+				})
+				return err
+			},
+		},
+		{
+			name:      "delete",
+			eventType: entity.EventUserDeleted,
+			setup: func(mockUserRepository *mock_user_repository.UserRepository) {
+				mockUserRepository.On("GetUserById", mock.Anything, user.ID.Hex()).Return(user, nil)
+				mockUserRepository.On("DeleteUser", transactionContext, user.ID.Hex(), int64(1)).Return(nil).Once()
+			},
+			change: func(userService service.UserService) error {
+				return userService.DeleteUser(context.Background(), user.ID.Hex(), dto.UserDeleteRequest{})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			mockUserRepository := mock_user_repository.NewUserRepository(t)
+			mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+			mockTransactor := mock_transactor.NewTransactor(t)
+			mockTransactor.On("WithTransaction", mock.Anything, mock.Anything).Return(inTransaction).Once()
+			tc.setup(mockUserRepository)
+			mockOutboxRepository.On("SaveEvent", transactionContext, mock.MatchedBy(func(event entity.OutboxEvent) bool {
+				return event.Type == tc.eventType && event.Status == entity.OutboxStatusPending
+			})).Return(nil).Once()
+			userService := service.NewUserService(mockUserRepository, mockOutboxRepository, mockTransactor)
+
+			// When
+			err := tc.change(userService)
+
+			// Then
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUserServiceIgnoresPublishErrors(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	user := entity.User{ID: bson.NewObjectID(), Name: "Test User", Email: "test@example.com", Version: 1}
+	updated := user
+	updated.Name = "New Name"
+	updated.Version = 2
+	var outbox []entity.OutboxEvent
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(runInTransaction)
+	mockUserRepository.On("GetUserById", ctx, user.ID.Hex()).Return(user, nil)
+	mockUserRepository.On("UpdateUser", ctx, user.ID.Hex(), int64(1), mock.Anything).Return(updated, nil)
+	mockOutboxRepository.On("SaveEvent", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		outbox = append(outbox, args.Get(1).(entity.OutboxEvent))
+	}).Return(nil)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(true, nil)
+	mockOutboxRepository.On("GetPendingEvents", ctx, mock.Anything, 10).Return(func(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error) {
+		return outbox, nil
+	})
+	mockSink.On("Publish", ctx, mock.MatchedBy(func(event entity.Event) bool {
+		return event.Type == entity.EventUserUpdated && event.Data.Name == "New Name" && event.Data.Version == 2
+	})).Return(mongo.ErrClientDisconnected).Once()
+	mockOutboxRepository.On("RecordFailure", ctx, mock.Anything, mongo.ErrClientDisconnected.Error(), mock.Anything).Return(nil).Once()
+	mockOutboxRepository.On("DeleteEvents", ctx, []string(nil)).Return(nil)
+	userService := service.NewUserService(mockUserRepository, mockOutboxRepository, mockTransactor)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{BatchSize: 10})
+
+	// When
+	resp, err := userService.UpdateUser(ctx, user.ID.Hex(), dto.UserUpdateRequest{Name: "New Name", Email: user.Email})
+	published, relayErr := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "New Name", resp.Name)
+	assert.NoError(t, relayErr)
+	assert.Zero(t, published)
+	assert.Len(t, outbox, 1)
+}
+
+func TestRegisterUserSavesEventInTransaction(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(runInTransaction).Once()
+	mockUserRepository.On("SaveUser", ctx, mock.Anything).Return(func(ctx context.Context, user entity.User) (entity.User, error) {
+		return user, nil
+	})
+	mockOutboxRepository.On("SaveEvent", ctx, mock.MatchedBy(func(event entity.OutboxEvent) bool {
+		return event.Type == entity.EventUserCreated && event.ID != "" && event.Sequence == 1 &&
+			event.AggregateID == event.Data.ID && event.Data.Email == "test@example.com"
+	})).Return(nil).Once()
+	userService := service.NewUserService(mockUserRepository, mockOutboxRepository, mockTransactor)
+
+	// When
+	resp, err := userService.RegisterUser(ctx, dto.UserRegisterRequest{Name: "Test User", Email: "test@example.com", Password: "password123"})
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.ID)
+}
+
+func TestDeleteUserEventFollowsLastVersion(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	user := entity.User{ID: bson.NewObjectID(), Name: "Test User", Email: "test@example.com", Version: 3}
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(runInTransaction).Once()
+	mockUserRepository.On("GetUserById", ctx, user.ID.Hex()).Return(user, nil)
+	mockUserRepository.On("DeleteUser", ctx, user.ID.Hex(), int64(3)).Return(nil)
+	mockOutboxRepository.On("SaveEvent", ctx, mock.MatchedBy(func(event entity.OutboxEvent) bool {
+		return event.Type == entity.EventUserDeleted && event.Sequence == 4 && event.Data == entity.UserSnapshot{ID: user.ID.Hex()}
+	})).Return(nil).Once()
+	userService := service.NewUserService(mockUserRepository, mockOutboxRepository, mockTransactor)
+
+	// When
+	err := userService.DeleteUser(ctx, user.ID.Hex(), dto.UserDeleteRequest{})
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestUpdateUserFailsWhenEventIsNotSaved(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockTransactor := mock_transactor.NewTransactor(t)
+	user := entity.User{ID: bson.NewObjectID(), Name: "Test User", Email: "test@example.com", Version: 1}
+	updated := user
+	updated.Name = "New Name"
+	updated.Version = 2
+	mockTransactor.On("WithTransaction", ctx, mock.Anything).Return(runInTransaction).Once()
+	mockUserRepository.On("GetUserById", ctx, user.ID.Hex()).Return(user, nil)
+	mockUserRepository.On("UpdateUser", ctx, user.ID.Hex(), int64(1), mock.Anything).Return(updated, nil)
+	mockOutboxRepository.On("SaveEvent", ctx, mock.MatchedBy(func(event entity.OutboxEvent) bool {
+		return event.Type == entity.EventUserUpdated && event.Sequence == 2 && event.Data.Name == "New Name"
+	})).Return(mongo.ErrClientDisconnected)
+	userService := service.NewUserService(mockUserRepository, mockOutboxRepository, mockTransactor)
+
+	// When
+	_, err := userService.UpdateUser(ctx, user.ID.Hex(), dto.UserUpdateRequest{Name: "New Name", Email: user.Email})
+
+	// Then
+	assert.Equal(t, apperror.CodeInternalError, apperror.From(err).Code)
+	assert.ErrorIs(t, err, mongo.ErrClientDisconnected)
+}
+
+func TestRelayPendingPublishesEventsOfAUserInOrder(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	updated := newOutboxEvent("user-a", 2, entity.EventUserUpdated)
+	other := newOutboxEvent("user-b", 1, entity.EventUserCreated)
+	created := newOutboxEvent("user-a", 1, entity.EventUserCreated)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(true, nil)
+	mockOutboxRepository.On("GetPendingEvents", ctx, mock.Anything, 10).Return([]entity.OutboxEvent{updated, other, created}, nil)
+	var published []string
+	mockSink.On("Publish", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(entity.Event).ID)
+	}).Return(nil)
+	mockOutboxRepository.On("DeleteEvents", ctx, []string{created.ID, updated.ID, other.ID}).Return(nil)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{BatchSize: 10})
+
+	// When
+	count, err := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []string{created.ID, updated.ID, other.ID}, published)
+}
+
+func TestRelayPendingHoldsLaterEventsOfAFailedUser(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	created := newOutboxEvent("user-a", 1, entity.EventUserCreated)
+	updated := newOutboxEvent("user-a", 2, entity.EventUserUpdated)
+	other := newOutboxEvent("user-b", 1, entity.EventUserCreated)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(true, nil)
+	mockOutboxRepository.On("GetPendingEvents", ctx, mock.Anything, 10).Return([]entity.OutboxEvent{created, updated, other}, nil)
+	mockSink.On("Publish", ctx, created.Event).Return(errors.New("sink unavailable"))
+	mockSink.On("Publish", ctx, other.Event).Return(nil)
+	mockOutboxRepository.On("RecordFailure", ctx, created.ID, "sink unavailable", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+		return nextAttemptAt.After(time.Now().Add(50 * time.Second))
+	})).Return(nil)
+	mockOutboxRepository.On("DeleteEvents", ctx, []string{other.ID}).Return(nil)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{BatchSize: 10, InitialBackoff: time.Minute})
+
+	// When
+	count, err := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	mockSink.AssertNotCalled(t, "Publish", ctx, updated.Event)
+}
+
+func TestRelayPendingWaitsForTheBackoff(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	created := newOutboxEvent("user-a", 1, entity.EventUserCreated)
+	created.Attempts = 1
+	created.NextAttemptAt = time.Now().Add(time.Minute)
+	updated := newOutboxEvent("user-a", 2, entity.EventUserUpdated)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(true, nil)
+	mockOutboxRepository.On("GetPendingEvents", ctx, mock.Anything, 10).Return([]entity.OutboxEvent{created, updated}, nil)
+	mockOutboxRepository.On("DeleteEvents", ctx, []string(nil)).Return(nil)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{BatchSize: 10})
+
+	// When
+	count, err := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	mockSink.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestRelayPendingMarksExhaustedEventDead(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	created := newOutboxEvent("user-a", 1, entity.EventUserCreated)
+	created.Attempts = 2
+	updated := newOutboxEvent("user-a", 2, entity.EventUserUpdated)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(true, nil)
+	mockOutboxRepository.On("GetPendingEvents", ctx, mock.Anything, 10).Return([]entity.OutboxEvent{created, updated}, nil)
+	mockSink.On("Publish", ctx, created.Event).Return(errors.New("poison event"))
+	mockSink.On("Publish", ctx, updated.Event).Return(nil)
+	mockOutboxRepository.On("MarkDead", ctx, created.ID, "poison event").Return(nil).Once()
+	mockOutboxRepository.On("DeleteEvents", ctx, []string{updated.ID}).Return(nil)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{BatchSize: 10, MaxAttempts: 3})
+	series := `outbox_dead_events_total{type="user.created"}`
+	deadBefore := scrapeMetric(t, series)
+
+	// When
+	count, err := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, deadBefore+1, scrapeMetric(t, series))
+	mockOutboxRepository.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRelayPendingWithoutLease(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockOutboxRepository := mock_outbox_repository.NewOutboxRepository(t)
+	mockLockRepository := mock_lock_repository.NewLockRepository(t)
+	mockSink := mock_event_publisher.NewEventPublisher(t)
+	mockLockRepository.On("AcquireLease", ctx, "outbox_relay", mock.Anything, mock.Anything).Return(false, nil)
+	relay := service.NewOutboxRelay(mockOutboxRepository, mockLockRepository, mockSink, service.OutboxOptions{})
+
+	// When
+	count, err := relay.RelayPending(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	mockOutboxRepository.AssertNotCalled(t, "GetPendingEvents", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventBusPublishesToEverySubscriber(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	event := newOutboxEvent("user-a", 1, entity.EventUserCreated).Event
+	failing := mock_event_publisher.NewEventPublisher(t)
+	failing.On("Publish", ctx, event).Return(errors.New("unavailable"))
+	working := mock_event_publisher.NewEventPublisher(t)
+	working.On("Publish", ctx, event).Return(nil)
+	bus := service.NewEventBus()
+	bus.Subscribe("audit", failing)
+	bus.Subscribe("search", working)
+
+	// When
+	err := bus.Publish(ctx, event)
+
+	// Then
+	assert.EqualError(t, err, "audit: unavailable")
+	working.AssertNumberOfCalls(t, "Publish", 1)
+}
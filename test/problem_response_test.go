@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWithProblemNotFound(t *testing.T) {
+	// Given
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil)
+	err := apperror.NotFound(apperror.CodeUserNotFound, "user with id 1 not found").WithParam("id", "1")
+
+	// When
+	json.ResponseWithProblem(w, r, err)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, json.ContentTypeProblem, w.Header().Get("Content-Type"))
+	assert.Equal(t, json.Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "user with id 1 not found",
+		Instance: "/api/v1/users/get/me",
+		Code:     apperror.CodeUserNotFound,
+	}, problem)
+}
+
+func TestResponseWithProblemValidationFields(t *testing.T) {
+	// Given
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", nil)
+	fieldError := apperror.FieldError{Field: "email", Code: apperror.CodeFieldInvalidEmail, Message: "email must be a valid email address"}
+	err := apperror.Validation(apperror.CodeValidationFailed, "validation failed", fieldError)
+
+	// When
+	json.ResponseWithProblem(w, r, err)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, apperror.CodeValidationFailed, problem.Code)
+	assert.Equal(t, []apperror.FieldError{fieldError}, problem.Errors)
+}
+
+func TestResponseWithProblemHidesInternalError(t *testing.T) {
+	// Given
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/list", nil)
+	r = r.WithContext(context.WithValue(r.Context(), constant.CONTEXT_KEY_REQUEST_ID, "request-1"))
+	err := errors.New("connection refused: mongo:27017")
+
+	// When
+	json.ResponseWithProblem(w, r, err)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, apperror.CodeInternalError, problem.Code)
+	assert.Equal(t, "request-1", problem.CorrelationID)
+	assert.NotContains(t, problem.Detail, "mongo")
+}
+
+func TestResponseWithProblemLocalized(t *testing.T) {
+	// Given
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/users/register", nil)
+	r = r.WithContext(context.WithValue(r.Context(), constant.CONTEXT_KEY_LOCALE, "th"))
+	err := apperror.Validation(apperror.CodeValidationFailed, "validation failed",
+		apperror.FieldError{Field: "email", Code: apperror.CodeFieldRequired, Message: "email is required"},
+		apperror.FieldError{Field: "name", Code: apperror.CodeFieldTooShort, Message: "name must be at least 3 characters", Params: map[string]string{"param": "3"}},
+	)
+
+	// When
+	json.ResponseWithProblem(w, r, err)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "th", w.Header().Get("Content-Language"))
+	assert.Equal(t, "ข้อมูลไม่ผ่านการตรวจสอบ", problem.Detail)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "email", Code: apperror.CodeFieldRequired, Message: "กรุณาระบุอีเมล"},
+		{Field: "name", Code: apperror.CodeFieldTooShort, Message: "ชื่อต้องมีความยาวอย่างน้อย 3 ตัวอักษร"},
+	}, problem.Errors)
+}
@@ -0,0 +1,318 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/ratelimit"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newRateLimitHandler(t *testing.T, policies ...ratelimit.Policy) http.Handler {
+	return newRateLimitHandlerWithOptions(t, middleware.RateLimitOptions{}, policies...)
+}
+
+func newRateLimitHandlerWithOptions(t *testing.T, options middleware.RateLimitOptions, policies ...ratelimit.Policy) http.Handler {
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), policies)
+	assert.NoError(t, err)
+	return middleware.RateLimitMiddleware(limiter, options)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codec.ResponseWithSuccess(w, r, "ok")
+	}))
+}
+
+func newRateLimitRequest(method string, path string, remoteAddr string) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	r.RemoteAddr = remoteAddr
+	return r
+}
+
+func TestRateLimitSlidingWindowRejectsOverLimit(t *testing.T) {
+	// Given
+	handler := newRateLimitHandler(t, ratelimit.Policy{
+		Name:      "register",
+		Routes:    []string{"POST /api/v1/users/register"},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     2,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByIP,
+	})
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRateLimitRequest(http.MethodPost, "/api/v1/users/register", "10.0.0.1:1234"))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRateLimitRequest(http.MethodPost, "/api/v1/users/register", "10.0.0.1:1234"))
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, apperror.CodeRateLimitExceeded, problem.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.NotEqual(t, "0", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitKeysByClientIP(t *testing.T) {
+	// Given
+	handler := newRateLimitHandler(t, ratelimit.Policy{
+		Name:      "login",
+		Routes:    []string{"POST /api/v1/users/login"},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     1,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByIP,
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newRateLimitRequest(http.MethodPost, "/api/v1/users/login", "10.0.0.1:1234"))
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRateLimitRequest(http.MethodPost, "/api/v1/users/login", "10.0.0.2:1234"))
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+}
+
+// apiKeyDigest returns the SHA-256 hex digest of apiKey, as configured in restServer.rateLimit.apiKeys.
+func apiKeyDigest(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRateLimitKeysByAPIKey(t *testing.T) {
+	// Given
+	handler := newRateLimitHandlerWithOptions(t, middleware.RateLimitOptions{APIKeys: []string{apiKeyDigest("key-a"), apiKeyDigest("key-b")}}, ratelimit.Policy{
+		Name:      "users",
+		Routes:    []string{"/api/v1/users/"},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     1,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByAPIKey,
+	})
+	first := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234")
+	first.Header.Set(middleware.HeaderAPIKey, "key-a")
+	handler.ServeHTTP(httptest.NewRecorder(), first)
+
+	// When
+	sameKey := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.2:1234")
+	sameKey.Header.Set(middleware.HeaderAPIKey, "key-a")
+	sameKeyResponse := httptest.NewRecorder()
+	handler.ServeHTTP(sameKeyResponse, sameKey)
+	otherKey := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234")
+	otherKey.Header.Set(middleware.HeaderAPIKey, "key-b")
+	otherKeyResponse := httptest.NewRecorder()
+	handler.ServeHTTP(otherKeyResponse, otherKey)
+
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, sameKeyResponse.Code)
+	assert.Equal(t, http.StatusOK, otherKeyResponse.Code)
+}
+
+func TestRateLimitKeysUnknownAPIKeysByClientIP(t *testing.T) {
+	// Given
+	handler := newRateLimitHandlerWithOptions(t, middleware.RateLimitOptions{APIKeys: []string{apiKeyDigest("key-a")}}, ratelimit.Policy{
+		Name:      "users",
+		Routes:    []string{"/api/v1/users/"},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     1,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByAPIKey,
+	})
+	first := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234")
+	first.Header.Set(middleware.HeaderAPIKey, "made-up-1")
+	handler.ServeHTTP(httptest.NewRecorder(), first)
+
+	// When
+	madeUp := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234")
+	madeUp.Header.Set(middleware.HeaderAPIKey, "made-up-2")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, madeUp)
+
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimitKeysRevokedTokensByClientIP(t *testing.T) {
+	// Given
+	revocationCacheTTL(t, 0)
+	jwt.SetRevocationCheck(func(context.Context, string) (time.Time, error) { return time.Now().Add(time.Minute), nil })
+	t.Cleanup(func() { jwt.SetRevocationCheck(nil) })
+	handler := newRateLimitHandler(t, ratelimit.Policy{
+		Name:      "users",
+		Routes:    []string{"/api/v1/users/"},
+		Algorithm: ratelimit.AlgorithmSlidingWindow,
+		Limit:     1,
+		Window:    time.Minute,
+		KeyBy:     ratelimit.KeyByUser,
+	})
+	handler.ServeHTTP(httptest.NewRecorder(), newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234"))
+	token, err := jwt.GenerateJwt("683ecde861d005de5ec0907d", "", "")
+	assert.NoError(t, err)
+
+	// When
+	revoked := newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234")
+	revoked.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, revoked)
+
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "a revoked token shares the budget of its IP")
+}
+
+// failingRateLimitStore is a ratelimit.Store whose Take fails with err.
+type failingRateLimitStore struct {
+	err error
+}
+
+func (s failingRateLimitStore) Take(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, s.err
+}
+
+func TestRateLimitStoreErrors(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "a contended counter rejects the request", err: ratelimit.ErrContended, expectedStatus: http.StatusTooManyRequests},
+		{name: "a store outage lets the request through", err: errors.New("store unreachable"), expectedStatus: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			limiter, err := ratelimit.NewLimiter(failingRateLimitStore{err: tc.err}, []ratelimit.Policy{{
+				Name:      "login",
+				Routes:    []string{"POST /api/v1/users/login"},
+				Algorithm: ratelimit.AlgorithmSlidingWindow,
+				Limit:     10,
+				Window:    time.Minute,
+				KeyBy:     ratelimit.KeyByIP,
+			}})
+			assert.NoError(t, err)
+			handler := middleware.RateLimitMiddleware(limiter, middleware.RateLimitOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				codec.ResponseWithSuccess(w, r, "ok")
+			}))
+			w := httptest.NewRecorder()
+
+			// When
+			handler.ServeHTTP(w, newRateLimitRequest(http.MethodPost, "/api/v1/users/login", "10.0.0.1:1234"))
+
+			// Then
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRateLimitTokenBucketAllowsBurst(t *testing.T) {
+	// Given
+	handler := newRateLimitHandler(t, ratelimit.Policy{
+		Name:      "users",
+		Routes:    []string{"/api/v1/users/"},
+		Algorithm: ratelimit.AlgorithmTokenBucket,
+		Limit:     1,
+		Burst:     3,
+		Window:    time.Hour,
+		KeyBy:     ratelimit.KeyByIP,
+	})
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234"))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// When
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRateLimitRequest(http.MethodGet, "/api/v1/users/get/list", "10.0.0.1:1234"))
+
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitMostSpecificRouteWins(t *testing.T) {
+	// Given
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{
+		{Name: "users", Routes: []string{"/api/v1/users/"}, Algorithm: ratelimit.AlgorithmTokenBucket, Limit: 60, Window: time.Minute, KeyBy: ratelimit.KeyByUser},
+		{Name: "login", Routes: []string{"POST /api/v1/users/login"}, Algorithm: ratelimit.AlgorithmSlidingWindow, Limit: 10, Window: time.Minute, KeyBy: ratelimit.KeyByIP},
+	})
+	assert.NoError(t, err)
+
+	// When
+	login, loginOk := limiter.Match(httptest.NewRequest(http.MethodPost, "/api/v1/users/login", nil))
+	list, listOk := limiter.Match(httptest.NewRequest(http.MethodGet, "/api/v1/users/get/list", nil))
+	_, healthOk := limiter.Match(httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	// Then
+	assert.True(t, loginOk)
+	assert.Equal(t, "login", login.Name)
+	assert.True(t, listOk)
+	assert.Equal(t, "users", list.Name)
+	assert.False(t, healthOk)
+}
+
+func TestRateLimitInvalidPolicy(t *testing.T) {
+	// When
+	_, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{
+		{Name: "broken", Routes: []string{"/"}, Algorithm: "leaky_bucket", Limit: 1, Window: time.Minute, KeyBy: ratelimit.KeyByIP},
+	})
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestRateLimitSetPoliciesReplacesPolicies(t *testing.T) {
+	// Given
+	policy := ratelimit.Policy{Name: "login", Routes: []string{"POST /api/v1/users/login"}, Algorithm: ratelimit.AlgorithmSlidingWindow, Limit: 1, Window: time.Minute, KeyBy: ratelimit.KeyByIP}
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{policy})
+	assert.NoError(t, err)
+	r := newRateLimitRequest(http.MethodPost, "/api/v1/users/login", "10.0.0.1:1234")
+	policy.Limit = 10
+
+	// When
+	invalid := limiter.SetPolicies([]ratelimit.Policy{{Name: "broken", Routes: []string{"/"}, Algorithm: "leaky_bucket", Limit: 1, Window: time.Minute, KeyBy: ratelimit.KeyByIP}})
+	kept, _ := limiter.Match(r)
+	valid := limiter.SetPolicies([]ratelimit.Policy{policy})
+	replaced, _ := limiter.Match(r)
+
+	// Then
+	assert.Error(t, invalid)
+	assert.Equal(t, 1, kept.Limit)
+	assert.NoError(t, valid)
+	assert.Equal(t, 10, replaced.Limit)
+}
+
+func TestRateLimitMemoryStoreSlidingWindowRecovers(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Name: "short", Algorithm: ratelimit.AlgorithmSlidingWindow, Limit: 1, Window: 20 * time.Millisecond}
+	first, err := store.Take(ctx, "key", policy)
+	assert.NoError(t, err)
+	assert.True(t, first.Allowed)
+
+	// When
+	time.Sleep(50 * time.Millisecond)
+	second, err := store.Take(ctx, "key", policy)
+
+	// Then
+	assert.NoError(t, err)
+	assert.True(t, second.Allowed)
+}
@@ -19,7 +19,8 @@ func TestMain(m *testing.M) {
 func setup() {
 	cfg = &config.Config{
 		RestServer: config.RestServer{
-			Port: 8080,
+			Port:        8080,
+			MaxBodySize: 1 << 20,
 			Jwt: config.JwtConfig{
 				Secret:   "SECRET_KEY",
 				ExpireIn: 100000,
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openSQLiteStore returns a migrated SQLite user store of a test.
+func openSQLiteStore(t *testing.T) *sql.DB {
+	return openSQLUserStore(t, config.UserStoreConfig{
+		Backend: config.UserStoreSQLite,
+		SQLite:  config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "users.db")},
+	})
+}
+
+func TestSQLOutboxRepositoryKeepsEventsUntilDeleted(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	outboxRepository := repository.NewSQLOutboxRepository(openSQLiteStore(t), config.UserStoreSQLite)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first := newOutboxEvent("683ecde861d005de5ec0907d", 1, entity.EventUserCreated)
+	first.OccurredAt, first.CreatedAt, first.Status = now, now, entity.OutboxStatusPending
+	first.Data = entity.UserSnapshot{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@example.com", Version: 1}
+	second := newOutboxEvent("683ecde861d005de5ec0907d", 2, entity.EventUserUpdated)
+	second.OccurredAt, second.CreatedAt, second.Status = now, now.Add(time.Second), entity.OutboxStatusPending
+	other := newOutboxEvent("683ecde861d005de5ec0907f", 1, entity.EventUserCreated)
+	other.OccurredAt, other.CreatedAt, other.Status = now, now.Add(2*time.Second), entity.OutboxStatusPending
+	dead := newOutboxEvent("683ecde861d005de5ec0907e", 1, entity.EventUserCreated)
+	dead.OccurredAt, dead.CreatedAt, dead.Status = now, now, entity.OutboxStatusPending
+	for _, event := range []entity.OutboxEvent{second, other, first, dead} {
+		assert.NoError(t, outboxRepository.SaveEvent(ctx, event))
+	}
+	nextAttemptAt := now.Add(time.Minute)
+
+	// When
+	errFailure := outboxRepository.RecordFailure(ctx, first.ID, "broker down", nextAttemptAt)
+	errDead := outboxRepository.MarkDead(ctx, dead.ID, "broker down")
+	waiting, errWaiting := outboxRepository.GetPendingEvents(ctx, now, 10)
+	due, errDue := outboxRepository.GetPendingEvents(ctx, nextAttemptAt, 10)
+	errDelete := outboxRepository.DeleteEvents(ctx, []string{first.ID})
+	remaining, errRemaining := outboxRepository.GetPendingEvents(ctx, now, 10)
+
+	// Then
+	assert.NoError(t, errors.Join(errFailure, errDead, errWaiting, errDue, errDelete, errRemaining))
+	first.Attempts, first.LastError, first.NextAttemptAt = 1, "broker down", nextAttemptAt
+	assert.Equal(t, []entity.OutboxEvent{other}, waiting, "the events of a user wait behind its event in backoff")
+	assert.Equal(t, []entity.OutboxEvent{first, second, other}, due, "oldest first, without the dead event")
+	assert.Equal(t, []entity.OutboxEvent{second, other}, remaining)
+}
+
+func TestSQLUserStoreSavesUserAndEventInOneTransaction(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	database := openSQLiteStore(t)
+	userRepository := repository.NewSQLiteUserRepository(database)
+	outboxRepository := repository.NewSQLOutboxRepository(database, config.UserStoreSQLite)
+	transactor := repository.NewSQLTransactor(database)
+	userService := service.NewUserService(userRepository, outboxRepository, transactor)
+	failed := errors.New("event not saved")
+
+	// When
+	registered, errRegister := userService.RegisterUser(ctx, dto.UserRegisterRequest{Name: "Test User", Email: "test@example.com", Password: "password123"})
+	errRolledBack := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		user := entity.User{ID: bson.NewObjectID(), Name: "Other", Email: "other@example.com", Password: "hash", Version: 1}
+		if _, err := userRepository.SaveUser(ctx, user); err != nil {
+			return err
+		}
+		return failed
+	})
+	events, errEvents := outboxRepository.GetPendingEvents(ctx, time.Now(), 10)
+	count, errCount := userRepository.CountUsers(ctx)
+
+	// Then
+	assert.NoError(t, errors.Join(errRegister, errEvents, errCount))
+	assert.ErrorIs(t, errRolledBack, failed)
+	assert.Equal(t, int64(1), count, "the rolled back user is not saved")
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, entity.EventUserCreated, events[0].Type)
+		assert.Equal(t, registered.ID, events[0].AggregateID)
+		assert.Equal(t, "test@example.com", events[0].Data.Email)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/logger"
+	"github.com/taninchot-work/backend-challenge/internal/core/middleware"
+	"github.com/taninchot-work/backend-challenge/internal/core/tracing"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/json"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	parentTraceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID    = "00f067aa0ba902b7"
+	testTraceparent = "00-" + parentTraceID + "-" + parentSpanID + "-01"
+)
+
+// recordSpans records the spans started until the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	_, err := tracing.Setup(context.Background(), tracing.Options{})
+	assert.NoError(t, err)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+	return recorder
+}
+
+func TestTracingMiddlewareContinuesTraceparent(t *testing.T) {
+	// Given
+	spans := recordSpans(t)
+	handler := middleware.TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "UserService.GetUserByID")
+		span.End()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil)
+	r.Header.Set("traceparent", testTraceparent)
+
+	// When
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Then
+	ended := spans.Ended()
+	assert.Len(t, ended, 2)
+	child, server := ended[0], ended[1]
+	assert.Equal(t, parentTraceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, parentSpanID, server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, "UserService.GetUserByID", child.Name())
+}
+
+func TestProblemResponseCarriesTraceID(t *testing.T) {
+	// Given
+	recordSpans(t)
+	handler := middleware.TracingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.ResponseWithProblem(w, r, apperror.NotFound(apperror.CodeUserNotFound, "user not found"))
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil)
+	r.Header.Set("traceparent", testTraceparent)
+	w := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(w, r)
+
+	// Then
+	var problem json.Problem
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, parentTraceID, problem.TraceID)
+}
+
+func TestLoggerAddsTraceID(t *testing.T) {
+	// Given
+	recordSpans(t)
+	buffer := captureLogs(t, logger.Options{})
+	ctx, span := tracing.Start(context.Background(), "test")
+	defer span.End()
+
+	// When
+	logger.New("test").InfoContext(ctx, "traced")
+
+	// Then
+	records := decodeLogs(t, buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, span.SpanContext().TraceID().String(), records[0]["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), records[0]["span_id"])
+}
+
+func TestTracingEndRedactsEmails(t *testing.T) {
+	// Given
+	spans := recordSpans(t)
+	_, span := tracing.Start(context.Background(), "UserRepository.SaveUser")
+
+	// When
+	tracing.End(span, errors.New(`duplicate key { email: "test@example.com" }`))
+
+	// Then
+	ended := spans.Ended()
+	assert.Len(t, ended, 1)
+	assert.Equal(t, `duplicate key { email: "[REDACTED]" }`, ended[0].Status().Description)
+}
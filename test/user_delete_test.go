@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
 	"github.com/taninchot-work/backend-challenge/internal/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"testing"
 )
 
@@ -26,11 +28,11 @@ func TestDeleteUserSuccess(t *testing.T) {
 	}
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
-	mockUserRepository.On("DeleteUser", ctx, userID).Return(nil)
-	userService := service.NewUserService(mockUserRepository)
+	mockUserRepository.On("DeleteUser", ctx, userID, int64(0)).Return(nil)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
-	err := userService.DeleteUser(ctx, userID)
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{})
 
 	// Then
 	assert.NoError(t, err)
@@ -45,14 +47,14 @@ func TestDeleteUserFailUserNotFound(t *testing.T) {
 	expectedError := errors.New("user not found")
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, expectedError)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
-	err := userService.DeleteUser(ctx, userID)
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{})
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	mockUserRepository.AssertExpectations(t)
 }
 
@@ -61,14 +63,14 @@ func TestDeleteUserFailGetUserByIdError(t *testing.T) {
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
 	userID := "683ecde861d005de5ec0907d"
-	expectedError := fmt.Errorf("user with id %s not found", userID)
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", userID)).WithParam("id", userID)
 
-	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, mongo.ErrNoDocuments)
+	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, repository.ErrNotFound)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
-	err := userService.DeleteUser(ctx, userID)
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{})
 
 	// Then
 	assert.Error(t, err)
@@ -90,15 +92,91 @@ func TestDeleteUserFailRepositoryDeleteError(t *testing.T) {
 	expectedError := errors.New("repository delete error")
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
-	mockUserRepository.On("DeleteUser", ctx, userID).Return(expectedError)
+	mockUserRepository.On("DeleteUser", ctx, userID, int64(0)).Return(expectedError)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
-	err := userService.DeleteUser(ctx, userID)
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{})
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestDeleteUserFailPreconditionFailed(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Test User",
+		Email:   "test@example.com",
+		Version: 5,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{IfMatch: `"4"`})
+
+	// Then
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestDeleteUserFailVersionConflict(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Test User",
+		Email:   "test@example.com",
+		Version: 5,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("DeleteUser", ctx, userID, int64(5)).Return(repository.ErrVersionConflict)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{IfMatch: `"5"`})
+
+	// Then
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestDeleteUserFailUserDeletedBeforeDelete(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Test User",
+		Email:   "test@example.com",
+		Version: 5,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("DeleteUser", ctx, userID, int64(5)).Return(repository.ErrNotFound)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	err := userService.DeleteUser(ctx, userID, dto.UserDeleteRequest{})
+
+	// Then
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeUserNotFound, appErr.Code)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -4,12 +4,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/codec"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
-	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -34,7 +41,7 @@ func TestGetUserByIdSuccess(t *testing.T) {
 
 	mockUserRepository.On("GetUserById", ctx, "683ecde861d005de5ec0907d").Return(userEntity, nil)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserByID(ctx, "683ecde861d005de5ec0907d")
@@ -51,11 +58,11 @@ func TestGetUserByIdFailIdNotFound(t *testing.T) {
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
 	userID := "nonexistentuserid"
-	expectedError := fmt.Errorf("user with id %s not found", userID)
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", userID)).WithParam("id", userID)
 
-	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, mongo.ErrNoDocuments)
+	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, repository.ErrNotFound)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserByID(ctx, userID)
@@ -75,7 +82,7 @@ func TestGetUserByIdFailOnRepository(t *testing.T) {
 
 	mockUserRepository.On("GetUserById", ctx, "683ecde861d005de5ec0907d").Return(entity.User{}, expectedError)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserByID(ctx, "683ecde861d005de5ec0907d")
@@ -84,7 +91,7 @@ func TestGetUserByIdFailOnRepository(t *testing.T) {
 	assert.Error(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, dto.UserGetMeResponse{}, resp)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	mockUserRepository.AssertExpectations(t)
 }
 
@@ -92,11 +99,11 @@ func TestGetUserByIdFailUserNotFound(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	expectedError := fmt.Errorf("user with id %s not found", "683ecde861d005de5ec0907d")
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", "683ecde861d005de5ec0907d")).WithParam("id", "683ecde861d005de5ec0907d")
 
 	mockUserRepository.On("GetUserById", ctx, "683ecde861d005de5ec0907d").Return(entity.User{}, nil)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserByID(ctx, "683ecde861d005de5ec0907d")
@@ -108,3 +115,39 @@ func TestGetUserByIdFailUserNotFound(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 	mockUserRepository.AssertExpectations(t)
 }
+
+func TestGetMeETagPerRepresentation(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		ifNoneMatch string
+		status      int
+		etag        string
+	}{
+		{name: "json", accept: codec.ContentTypeJSON, status: http.StatusOK, etag: `"2"`},
+		{name: "msgpack", accept: codec.ContentTypeMessagePack, status: http.StatusOK, etag: `"2-msgpack"`},
+		{name: "cached json", accept: codec.ContentTypeJSON, ifNoneMatch: `"2"`, status: http.StatusNotModified, etag: `"2"`},
+		{name: "json cached as cbor", accept: codec.ContentTypeCBOR, ifNoneMatch: `"2"`, status: http.StatusOK, etag: `"2-cbor"`},
+		{name: "weakly cached cbor", accept: codec.ContentTypeCBOR, ifNoneMatch: `W/"2-cbor"`, status: http.StatusNotModified, etag: `"2-cbor"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			mockUserService := mock_user_service.NewUserService(t)
+			mockUserService.On("GetUserByID", mock.Anything, "683ecde861d005de5ec0907d").
+				Return(dto.UserGetMeResponse{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@gmail.com", Version: 2}, nil)
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users/get/me", nil)
+			r.Header.Set("Accept", tt.accept)
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			r = r.WithContext(context.WithValue(r.Context(), constant.CONTEXT_KEY_USER_ID, "683ecde861d005de5ec0907d"))
+			w := httptest.NewRecorder()
+
+			// When
+			controller.NewUserController(mockUserService).GetMe(w, r)
+
+			// Then
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.etag, w.Header().Get("ETag"))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"testing"
+)
+
+func TestGetUserConnectionPages(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	objectID1, _ := bson.ObjectIDFromHex("683ecde861d005de5ec0907d")
+	objectID2, _ := bson.ObjectIDFromHex("683ecde861d005de5ec0907e")
+	objectID3, _ := bson.ObjectIDFromHex("683ecde861d005de5ec0907f")
+	filter := entity.UserFilter{Name: "test", Email: "test@gmail.com"}
+	mockUserRepository.On("GetUserList", ctx, filter, 0, 3).Return([]entity.User{
+		{ID: objectID1, Name: "Test User 1", Email: "test@gmail.com"},
+		{ID: objectID2, Name: "Test User 2", Email: "test@gmail.com"},
+		{ID: objectID3, Name: "Test User 3", Email: "test@gmail.com"},
+	}, nil).Once()
+	mockUserRepository.On("GetUserList", ctx, filter, 2, 3).Return([]entity.User{
+		{ID: objectID3, Name: "Test User 3", Email: "test@gmail.com"},
+	}, nil).Once()
+	userService := newUserService(t, mockUserRepository)
+	req := dto.UserConnectionRequest{First: 2, Filter: dto.UserListFilter{Name: "test", Email: "Test@Gmail.com"}}
+
+	// When
+	first, err := userService.GetUserConnection(ctx, req)
+	assert.NoError(t, err)
+	req.After = first.PageInfo.EndCursor
+	second, err := userService.GetUserConnection(ctx, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, first.Edges, 2)
+	assert.Equal(t, "683ecde861d005de5ec0907d", first.Edges[0].Node.ID)
+	assert.True(t, first.PageInfo.HasNextPage)
+	assert.Len(t, second.Edges, 1)
+	assert.Equal(t, "683ecde861d005de5ec0907f", second.Edges[0].Node.ID)
+	assert.False(t, second.PageInfo.HasNextPage)
+	assert.Equal(t, second.Edges[0].Cursor, second.PageInfo.EndCursor)
+}
+
+func TestGetUserConnectionInvalidCursor(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	_, err := userService.GetUserConnection(ctx, dto.UserConnectionRequest{First: 10, After: "not-a-cursor"})
+
+	// Then
+	assert.Equal(t, apperror.CodeInvalidCursor, apperror.From(err).Code)
+	mockUserRepository.AssertNotCalled(t, "GetUserList")
+}
+
+func TestGetUserConnectionInvalidSize(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	_, err := userService.GetUserConnection(ctx, dto.UserConnectionRequest{First: 101})
+
+	// Then
+	appErr := apperror.From(err)
+	assert.Equal(t, apperror.KindValidation, appErr.Kind)
+	assert.Equal(t, apperror.CodeFieldTooLarge, appErr.Fields[0].Code)
+}
+
+func TestGetUsersByIDsSuccess(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	objectID, _ := bson.ObjectIDFromHex("683ecde861d005de5ec0907d")
+	ids := []string{"683ecde861d005de5ec0907d", "683ecde861d005de5ec0907e"}
+	mockUserRepository.On("GetUsersByIds", ctx, ids).Return([]entity.User{
+		{ID: objectID, Name: "Test User", Email: "test@gmail.com"},
+	}, nil)
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	users, err := userService.GetUsersByIDs(ctx, ids)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.UserListGetResponseItem{
+		{ID: "683ecde861d005de5ec0907d", Name: "Test User", Email: "test@gmail.com"},
+	}, users)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
-	"github.com/taninchot-work/backend-challenge/internal/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"testing"
 )
@@ -35,8 +34,8 @@ func TestGetUserListSuccess(t *testing.T) {
 		Page: req.Page,
 	}
 
-	mockUserRepository.On("GetUserList", ctx, offset, req.Limit).Return(usersEntity, nil)
-	userService := service.NewUserService(mockUserRepository)
+	mockUserRepository.On("GetUserList", ctx, entity.UserFilter{}, offset, req.Limit).Return(usersEntity, nil)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserList(ctx, req)
@@ -58,15 +57,15 @@ func TestGetUserListError(t *testing.T) {
 	offset := (req.Page - 1) * req.Limit
 	expectedError := errors.New("repository error")
 
-	mockUserRepository.On("GetUserList", ctx, offset, req.Limit).Return([]entity.User{}, expectedError)
-	userService := service.NewUserService(mockUserRepository)
+	mockUserRepository.On("GetUserList", ctx, entity.UserFilter{}, offset, req.Limit).Return([]entity.User{}, expectedError)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.GetUserList(ctx, req)
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserListGetResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
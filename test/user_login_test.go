@@ -3,15 +3,14 @@ package test
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
 	"github.com/taninchot-work/backend-challenge/internal/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
 )
@@ -20,7 +19,7 @@ func TestLoginUserSuccess(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	req := dto.UserLoginRequest{
 		Email:    "test@example.com",
@@ -36,7 +35,7 @@ func TestLoginUserSuccess(t *testing.T) {
 		Password: string(hashedPassword),
 	}
 
-	expectedAccessToken, _ := jwt.GenerateJwt(userID.Hex())
+	expectedAccessToken, _ := jwt.GenerateJwt(userID.Hex(), userEntity.Locale, userEntity.Role)
 	expectedResponse := dto.UserLoginResponse{
 		ID:          userID.Hex(),
 		Name:        userEntity.Name,
@@ -58,19 +57,19 @@ func TestLoginUserSuccess(t *testing.T) {
 	mockUserRepository.AssertExpectations(t)
 }
 
-func TestLoginUserFailGetUserByEmailMongoErrNoDocuments(t *testing.T) {
+func TestLoginUserFailGetUserByEmailNotFound(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	req := dto.UserLoginRequest{
 		Email:    "nonexistent@example.com",
 		Password: "password123",
 	}
-	expectedError := fmt.Errorf("invalid email or password")
+	expectedError := service.ErrInvalidCredentials
 
-	mockUserRepository.On("GetUserByEmail", ctx, req.Email).Return(entity.User{}, mongo.ErrNoDocuments)
+	mockUserRepository.On("GetUserByEmail", ctx, req.Email).Return(entity.User{}, repository.ErrNotFound)
 
 	// When
 	resp, err := userService.LoginUser(ctx, req)
@@ -86,7 +85,7 @@ func TestLoginUserFailGetUserByEmailGenericError(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	req := dto.UserLoginRequest{
 		Email:    "test@example.com",
@@ -101,7 +100,7 @@ func TestLoginUserFailGetUserByEmailGenericError(t *testing.T) {
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserLoginResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -110,7 +109,7 @@ func TestLoginUserFailUserNotFoundByEmptyStruct(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	req := dto.UserLoginRequest{
 		Email:    "test@example.com",
@@ -132,7 +131,7 @@ func TestLoginUserFailPasswordMismatch(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	req := dto.UserLoginRequest{
 		Email:    "test@example.com",
@@ -148,7 +147,7 @@ func TestLoginUserFailPasswordMismatch(t *testing.T) {
 		Email:    req.Email,
 		Password: string(hashedPassword),
 	}
-	expectedError := fmt.Errorf("invalid email or password")
+	expectedError := service.ErrInvalidCredentials
 
 	mockUserRepository.On("GetUserByEmail", ctx, req.Email).Return(userEntity, nil)
 
@@ -0,0 +1,312 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/constant"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/util/patch"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
+	mock_user_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/user_service_mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPatchUserEntity(userID string) entity.User {
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	return entity.User{
+		ID:        objectID,
+		Name:      "Original Name",
+		Email:     "original@example.com",
+		Password:  "hashed-password",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPatchUserMergePatchSuccess(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	userEntity := newPatchUserEntity(userID)
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeMergePatch,
+		Patch:       []byte(`{"name":"Patched Name"}`),
+	}
+	patchedName := "Patched Name"
+	updatedUserEntity := userEntity
+	updatedUserEntity.Name = patchedName
+
+	expectedResponse := dto.UserUpdateResponse{
+		ID:    userID,
+		Name:  patchedName,
+		Email: userEntity.Email,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), entity.UserUpdate{Name: &patchedName}).Return(updatedUserEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, resp)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestPatchUserJSONPatchSuccess(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	userEntity := newPatchUserEntity(userID)
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeJSONPatch,
+		Patch:       []byte(`[{"op":"test","path":"/email","value":"original@example.com"},{"op":"replace","path":"/email","value":"Patched@Example.com"}]`),
+	}
+	patchedEmail := "patched@example.com"
+	updatedUserEntity := userEntity
+	updatedUserEntity.Email = patchedEmail
+
+	expectedResponse := dto.UserUpdateResponse{
+		ID:    userID,
+		Name:  userEntity.Name,
+		Email: patchedEmail,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), entity.UserUpdate{Email: &patchedEmail}).Return(updatedUserEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, resp)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestPatchUserMergePatchNullClearsLocale(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	userEntity := newPatchUserEntity(userID)
+	userEntity.Locale = "th"
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeMergePatch,
+		Patch:       []byte(`{"locale":null}`),
+	}
+	cleared := ""
+	updatedUserEntity := userEntity
+	updatedUserEntity.Locale = ""
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), entity.UserUpdate{Locale: &cleared}).Return(updatedUserEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Locale)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestPatchUserFailImmutableField(t *testing.T) {
+	testCases := []struct {
+		name          string
+		req           dto.UserPatchRequest
+		expectedField apperror.FieldError
+	}{
+		{
+			name: "merge patch id",
+			req: dto.UserPatchRequest{
+				ContentType: patch.ContentTypeMergePatch,
+				Patch:       []byte(`{"id":"000000000000000000000000"}`),
+			},
+			expectedField: apperror.FieldError{
+				Field:   "id",
+				Code:    apperror.CodeFieldImmutable,
+				Message: "field id is immutable",
+			},
+		},
+		{
+			name: "json patch remove createdAt",
+			req: dto.UserPatchRequest{
+				ContentType: patch.ContentTypeJSONPatch,
+				Patch:       []byte(`[{"op":"remove","path":"/createdAt"}]`),
+			},
+			expectedField: apperror.FieldError{
+				Field:   "createdAt",
+				Code:    apperror.CodeFieldImmutable,
+				Message: "field createdAt is immutable",
+			},
+		},
+		{
+			name: "merge patch password",
+			req: dto.UserPatchRequest{
+				ContentType: patch.ContentTypeMergePatch,
+				Patch:       []byte(`{"password":"new-password"}`),
+			},
+			expectedField: apperror.FieldError{
+				Field:   "password",
+				Code:    apperror.CodeFieldImmutable,
+				Message: "field password is immutable",
+			},
+		},
+		{
+			name: "json patch unknown field",
+			req: dto.UserPatchRequest{
+				ContentType: patch.ContentTypeJSONPatch,
+				Patch:       []byte(`[{"op":"add","path":"/role","value":"admin"}]`),
+			},
+			expectedField: apperror.FieldError{
+				Field:   "role",
+				Code:    apperror.CodeFieldUnknown,
+				Message: "unknown field role",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			ctx := context.Background()
+			mockUserRepository := mock_user_repository.NewUserRepository(t)
+			userID := "683ecde861d005de5ec0907d"
+
+			mockUserRepository.On("GetUserById", ctx, userID).Return(newPatchUserEntity(userID), nil)
+
+			userService := newUserService(t, mockUserRepository)
+
+			// When
+			resp, err := userService.PatchUser(ctx, userID, tc.req)
+
+			// Then
+			expectedError := apperror.Validation(apperror.CodeInvalidPatch, "invalid patch: patch changes fields that cannot be changed", tc.expectedField).
+				WithParam("reason", "patch changes fields that cannot be changed")
+			assert.Equal(t, expectedError, err)
+			assert.Equal(t, dto.UserUpdateResponse{}, resp)
+			mockUserRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPatchUserFailValidation(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeMergePatch,
+		Patch:       []byte(`{"email":"not-an-email","name":null}`),
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(newPatchUserEntity(userID), nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeValidationFailed, appErr.Code)
+	assert.ElementsMatch(t, []apperror.FieldError{
+		{Field: "name", Code: apperror.CodeFieldRequired, Message: "name is required"},
+		{Field: "email", Code: apperror.CodeFieldInvalidEmail, Message: "email must be a valid email address"},
+	}, appErr.Fields)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+	mockUserRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchUserFailInvalidPatch(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeJSONPatch,
+		Patch:       []byte(`[{"op":"replace","path":"/missing","value":"x"}]`),
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(newPatchUserEntity(userID), nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeInvalidPatch, appErr.Code)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+}
+
+func TestPatchUserFailUserNotFound(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	req := dto.UserPatchRequest{
+		ContentType: patch.ContentTypeMergePatch,
+		Patch:       []byte(`{"name":"Patched Name"}`),
+	}
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", userID)).WithParam("id", userID)
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, repository.ErrNotFound)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.PatchUser(ctx, userID, req)
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, expectedError, err)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestPatchUserFailBodyTooLarge(t *testing.T) {
+	// Given
+	limited := *cfg
+	limited.RestServer.MaxBodySize = 16
+	config.SetConfig(&limited)
+	t.Cleanup(func() { config.SetConfig(cfg) })
+	mockUserService := mock_user_service.NewUserService(t)
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/users/update/me", strings.NewReader(`{"name":"A name that is far too long"}`))
+	r.Header.Set("Content-Type", patch.ContentTypeMergePatch)
+	r = r.WithContext(context.WithValue(r.Context(), constant.CONTEXT_KEY_USER_ID, "683ecde861d005de5ec0907d"))
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewUserController(mockUserService).UserPatch(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), apperror.CodeRequestBodyTooLarge)
+	assert.Contains(t, w.Body.String(), "must not be larger than 16 bytes")
+	mockUserService.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -6,13 +6,13 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
 	"github.com/taninchot-work/backend-challenge/internal/core/util/jwt"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
-	"github.com/taninchot-work/backend-challenge/internal/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"golang.org/x/crypto/bcrypt"
 	"strings"
 	"testing"
@@ -40,7 +40,7 @@ func TestRegisterUserSuccess(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	expectedAccessToken, err := jwt.GenerateJwt(userID.Hex())
+	expectedAccessToken, err := jwt.GenerateJwt(userID.Hex(), userEntity.Locale, userEntity.Role)
 	if err != nil {
 		t.Fatalf("Failed to generate JWT: %v", err)
 	}
@@ -55,7 +55,7 @@ func TestRegisterUserSuccess(t *testing.T) {
 		return u.Name == req.Name && u.Email == req.Email && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)) == nil
 	})).Return(userEntity, nil)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.RegisterUser(ctx, req)
@@ -73,7 +73,7 @@ func TestRegisterHashPasswordError(t *testing.T) {
 	// Given
 	ctx := context.Background()
 	mockUserRepository := mock_user_repository.NewUserRepository(t)
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	longPassword := strings.Repeat("a", 73)
 
@@ -90,7 +90,7 @@ func TestRegisterHashPasswordError(t *testing.T) {
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserRegisterResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -104,14 +104,13 @@ func TestRegisterUserDuplicateEmail(t *testing.T) {
 		Email:    "test@example.com",
 		Password: "password123",
 	}
-	expectedError := fmt.Errorf("email %s is already registered", req.Email)
-	duplicateKeyError := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	expectedError := apperror.Conflict(apperror.CodeEmailAlreadyExists, fmt.Sprintf("email %s is already registered", req.Email)).WithParam("email", req.Email)
 
 	mockUserRepository.On("SaveUser", ctx, mock.MatchedBy(func(u entity.User) bool {
 		return u.Name == req.Name && u.Email == req.Email && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)) == nil
-	})).Return(entity.User{}, duplicateKeyError)
+	})).Return(entity.User{}, repository.ErrDuplicateEmail)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.RegisterUser(ctx, req)
@@ -138,14 +137,14 @@ func TestRegisterUserSaveUserError(t *testing.T) {
 		return u.Name == req.Name && u.Email == req.Email && bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(req.Password)) == nil
 	})).Return(entity.User{}, expectedError)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.RegisterUser(ctx, req)
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserRegisterResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -0,0 +1,324 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taninchot-work/backend-challenge/internal/core/config"
+	"github.com/taninchot-work/backend-challenge/internal/core/db"
+	"github.com/taninchot-work/backend-challenge/internal/core/migration"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newUserStore returns an empty user store of a test, transactor is nil when
+// the store has no transactions.
+type newUserStore func(t *testing.T) (userRepository repository.UserRepository, transactor repository.Transactor)
+
+func TestSQLiteUserRepositoryConformance(t *testing.T) {
+	testUserRepository(t, func(t *testing.T) (repository.UserRepository, repository.Transactor) {
+		database := openSQLUserStore(t, config.UserStoreConfig{
+			Backend: config.UserStoreSQLite,
+			SQLite:  config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "users.db")},
+		})
+		return repository.NewSQLiteUserRepository(database), repository.NewSQLTransactor(database)
+	})
+}
+
+// TestPostgresUserRepositoryConformance runs against the database of
+// TEST_POSTGRES_URL, whose users table it drops.
+func TestPostgresUserRepositoryConformance(t *testing.T) {
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	testUserRepository(t, func(t *testing.T) (repository.UserRepository, repository.Transactor) {
+		database := openSQLUserStore(t, config.UserStoreConfig{
+			Backend:  config.UserStorePostgres,
+			Postgres: config.PostgresConfig{URL: url, MaxOpenConns: 4},
+		})
+		t.Cleanup(func() {
+			_, err := database.Exec("DROP TABLE IF EXISTS users, outbox, schema_migrations")
+			assert.NoError(t, err)
+		})
+		return repository.NewPostgresUserRepository(database), repository.NewSQLTransactor(database)
+	})
+}
+
+// TestMongoUserRepositoryConformance runs against a database of
+// TEST_MONGO_URI that it drops.
+func TestMongoUserRepositoryConformance(t *testing.T) {
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI is not set")
+	}
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	testUserRepository(t, func(t *testing.T) (repository.UserRepository, repository.Transactor) {
+		database := client.Database("conformance_" + bson.NewObjectID().Hex())
+		t.Cleanup(func() { assert.NoError(t, database.Drop(context.Background())) })
+		collection := database.Collection("users")
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("unique_email_index"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a standalone server has no transactions
+		return repository.NewUserRepository(collection), nil
+	})
+}
+
+// openSQLUserStore opens the database of cfg with its migrations applied.
+func openSQLUserStore(t *testing.T, cfg config.UserStoreConfig) *sql.DB {
+	ctx := context.Background()
+	database, err := db.OpenSQL(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	migrator, err := migration.NewSQLMigrator(database, cfg.Backend, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// testUserRepository checks that the user store of newStore keeps the
+// contract of UserRepository, every backend runs it.
+func testUserRepository(t *testing.T, newStore newUserStore) {
+	ctx := context.Background()
+	// the stores keep milliseconds at least
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newUser := func(name string, email string) entity.User {
+		return entity.User{
+			ID:        bson.NewObjectID(),
+			Name:      name,
+			Email:     email,
+			Password:  "hash",
+			Locale:    "th",
+			Role:      entity.RoleAdmin,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Version:   1,
+		}
+	}
+
+	t.Run("saves and finds a user", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		user := newUser("Alice", "alice@example.com")
+
+		// When
+		saved, err := userRepository.SaveUser(ctx, user)
+		byID, errByID := userRepository.GetUserById(ctx, user.ID.Hex())
+		byEmail, errByEmail := userRepository.GetUserByEmail(ctx, "alice@example.com")
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, user, saved)
+		assert.NoError(t, errByID)
+		assert.Equal(t, user, byID)
+		assert.NoError(t, errByEmail)
+		assert.Equal(t, user, byEmail)
+	})
+
+	t.Run("returns ErrNotFound for unknown users", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+
+		// When
+		_, errByID := userRepository.GetUserById(ctx, bson.NewObjectID().Hex())
+		_, errByEmail := userRepository.GetUserByEmail(ctx, "nobody@example.com")
+		_, errUpdate := userRepository.UpdateUser(ctx, bson.NewObjectID().Hex(), 1, entity.UserUpdate{})
+		errDelete := userRepository.DeleteUser(ctx, bson.NewObjectID().Hex(), 1)
+
+		// Then
+		assert.ErrorIs(t, errByID, repository.ErrNotFound)
+		assert.ErrorIs(t, errByEmail, repository.ErrNotFound)
+		assert.ErrorIs(t, errUpdate, repository.ErrNotFound)
+		assert.ErrorIs(t, errDelete, repository.ErrNotFound)
+	})
+
+	t.Run("returns ErrInvalidID for malformed ids", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+
+		// When
+		_, errByID := userRepository.GetUserById(ctx, "not-an-id")
+		_, errUpdate := userRepository.UpdateUser(ctx, "not-an-id", 1, entity.UserUpdate{})
+		errDelete := userRepository.DeleteUser(ctx, "not-an-id", 1)
+
+		// Then
+		assert.ErrorIs(t, errByID, repository.ErrInvalidID)
+		assert.ErrorIs(t, errUpdate, repository.ErrInvalidID)
+		assert.ErrorIs(t, errDelete, repository.ErrInvalidID)
+	})
+
+	t.Run("returns ErrDuplicateEmail for a taken email or id", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		alice, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		assert.NoError(t, err)
+		bob, err := userRepository.SaveUser(ctx, newUser("Bob", "bob@example.com"))
+		assert.NoError(t, err)
+		taken := "alice@example.com"
+		sameID := newUser("Carol", "carol@example.com")
+		sameID.ID = alice.ID
+
+		// When
+		_, errSave := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		_, errSameID := userRepository.SaveUser(ctx, sameID)
+		_, errUpdate := userRepository.UpdateUser(ctx, bob.ID.Hex(), bob.Version, entity.UserUpdate{Email: &taken})
+
+		// Then
+		assert.ErrorIs(t, errSave, repository.ErrDuplicateEmail)
+		assert.ErrorIs(t, errSameID, repository.ErrDuplicateEmail)
+		assert.ErrorIs(t, errUpdate, repository.ErrDuplicateEmail)
+	})
+
+	t.Run("updates the changed fields and the version", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		user, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		assert.NoError(t, err)
+		name := "Alice Smith"
+		revokedAt := now.Add(time.Minute)
+
+		// When
+		updated, err := userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, entity.UserUpdate{Name: &name, SessionsRevokedAt: &revokedAt})
+		found, errFound := userRepository.GetUserById(ctx, user.ID.Hex())
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, "Alice Smith", updated.Name)
+		assert.Equal(t, "alice@example.com", updated.Email)
+		assert.Equal(t, user.Version+1, updated.Version)
+		assert.True(t, revokedAt.Equal(updated.SessionsRevokedAt))
+		assert.False(t, updated.UpdatedAt.Before(user.UpdatedAt))
+		assert.NoError(t, errFound)
+		assert.Equal(t, updated, found)
+	})
+
+	t.Run("returns ErrVersionConflict for a stale version", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		user, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		assert.NoError(t, err)
+		name := "Alice Smith"
+		_, err = userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, entity.UserUpdate{Name: &name})
+		assert.NoError(t, err)
+
+		// When
+		_, errUpdate := userRepository.UpdateUser(ctx, user.ID.Hex(), user.Version, entity.UserUpdate{Name: &name})
+		errDelete := userRepository.DeleteUser(ctx, user.ID.Hex(), user.Version)
+
+		// Then
+		assert.ErrorIs(t, errUpdate, repository.ErrVersionConflict)
+		assert.ErrorIs(t, errDelete, repository.ErrVersionConflict)
+	})
+
+	t.Run("deletes a user", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		user, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		assert.NoError(t, err)
+
+		// When
+		err = userRepository.DeleteUser(ctx, user.ID.Hex(), user.Version)
+		_, errFound := userRepository.GetUserById(ctx, user.ID.Hex())
+
+		// Then
+		assert.NoError(t, err)
+		assert.ErrorIs(t, errFound, repository.ErrNotFound)
+	})
+
+	t.Run("lists, filters, pages and counts users", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		var users []entity.User
+		for _, name := range []string{"Alice", "Bob", "alicia", "Carol", "100%_Al"} {
+			user, err := userRepository.SaveUser(ctx, newUser(name, name+"@example.com"))
+			assert.NoError(t, err)
+			users = append(users, user)
+		}
+
+		// When
+		firstPage, errFirst := userRepository.GetUserList(ctx, entity.UserFilter{}, 0, 2)
+		secondPage, errSecond := userRepository.GetUserList(ctx, entity.UserFilter{}, 2, 2)
+		byName, errByName := userRepository.GetUserList(ctx, entity.UserFilter{Name: "ALI"}, 0, 10)
+		byWildcard, errByWildcard := userRepository.GetUserList(ctx, entity.UserFilter{Name: "%_"}, 0, 10)
+		byEmail, errByEmail := userRepository.GetUserList(ctx, entity.UserFilter{Email: "Bob@example.com"}, 0, 10)
+		count, errCount := userRepository.CountUsers(ctx)
+
+		// Then
+		assert.NoError(t, errors.Join(errFirst, errSecond, errByName, errByWildcard, errByEmail, errCount))
+		assert.Equal(t, users[:2], firstPage, "users are ordered by id")
+		assert.Equal(t, users[2:4], secondPage)
+		assert.Equal(t, []entity.User{users[0], users[2]}, byName, "the name matches ignoring case")
+		assert.Equal(t, []entity.User{users[4]}, byWildcard, "the name has no wildcards")
+		assert.Equal(t, []entity.User{users[1]}, byEmail)
+		assert.Equal(t, int64(5), count)
+	})
+
+	t.Run("gets users by ids skipping unknown ones", func(t *testing.T) {
+		// Given
+		userRepository, _ := newStore(t)
+		alice, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com"))
+		assert.NoError(t, err)
+		bob, err := userRepository.SaveUser(ctx, newUser("Bob", "bob@example.com"))
+		assert.NoError(t, err)
+
+		// When
+		users, err := userRepository.GetUsersByIds(ctx, []string{bob.ID.Hex(), "not-an-id", bson.NewObjectID().Hex(), alice.ID.Hex()})
+		none, errNone := userRepository.GetUsersByIds(ctx, []string{"not-an-id"})
+
+		// Then
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []entity.User{alice, bob}, users)
+		assert.NoError(t, errNone)
+		assert.Empty(t, none)
+	})
+
+	t.Run("rolls back a failed transaction", func(t *testing.T) {
+		// Given
+		userRepository, transactor := newStore(t)
+		if transactor == nil {
+			t.Skip("the store has no transactions")
+		}
+		failure := errors.New("failure")
+
+		// When
+		err := transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			if _, err := userRepository.SaveUser(ctx, newUser("Alice", "alice@example.com")); err != nil {
+				return err
+			}
+			// the transaction sees its own writes
+			if _, err := userRepository.GetUserByEmail(ctx, "alice@example.com"); err != nil {
+				return err
+			}
+			return failure
+		})
+		_, errFound := userRepository.GetUserByEmail(ctx, "alice@example.com")
+
+		// Then
+		assert.ErrorIs(t, err, failure)
+		assert.ErrorIs(t, errFound, repository.ErrNotFound)
+	})
+}
@@ -6,12 +6,13 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
 	"github.com/taninchot-work/backend-challenge/internal/dto"
 	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
 	mock_user_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/user_repository_mock"
 	"github.com/taninchot-work/backend-challenge/internal/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"testing"
 )
 
@@ -42,10 +43,15 @@ func TestUpdateUserSuccess(t *testing.T) {
 		Email: req.Email,
 	}
 
+	userUpdate := entity.UserUpdate{
+		Name:  &req.Name,
+		Email: &req.Email,
+	}
+
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
-	mockUserRepository.On("UpdateUser", ctx, updatedUserEntity).Return(updatedUserEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), userUpdate).Return(updatedUserEntity, nil)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
@@ -65,11 +71,11 @@ func TestUpdateUserFailUserNotFound(t *testing.T) {
 		Name:  "Updated Name",
 		Email: "updated@example.com",
 	}
-	expectedError := fmt.Errorf("user with id %s not found", userID)
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", userID)).WithParam("id", userID)
 
-	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, mongo.ErrNoDocuments)
+	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, repository.ErrNotFound)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
@@ -96,13 +102,12 @@ func TestUpdateUserFailDuplicateEmail(t *testing.T) {
 		Name:  "Original Name",
 		Email: "original@example.com",
 	}
-	expectedError := fmt.Errorf("email %s is already exists", req.Email)
-	duplicateKeyError := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	expectedError := apperror.Conflict(apperror.CodeEmailAlreadyExists, fmt.Sprintf("email %s is already registered", req.Email)).WithParam("email", req.Email)
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
-	mockUserRepository.On("UpdateUser", ctx, mock.Anything).Return(entity.User{}, duplicateKeyError)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), mock.Anything).Return(entity.User{}, repository.ErrDuplicateEmail)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
@@ -114,6 +119,40 @@ func TestUpdateUserFailDuplicateEmail(t *testing.T) {
 	mockUserRepository.AssertExpectations(t)
 }
 
+func TestUpdateUserSuccessNothingChanged(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:  "Original Name",
+		Email: "original@example.com",
+	}
+	userEntity := entity.User{
+		ID:    objectID,
+		Name:  req.Name,
+		Email: req.Email,
+	}
+	expectedResponse := dto.UserUpdateResponse{
+		ID:    userID,
+		Name:  req.Name,
+		Email: req.Email,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, resp)
+	mockUserRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUpdateUserFailUpdateUserError(t *testing.T) {
 	// Given
 	ctx := context.Background()
@@ -132,16 +171,16 @@ func TestUpdateUserFailUpdateUserError(t *testing.T) {
 	expectedError := errors.New("repository update error")
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
-	mockUserRepository.On("UpdateUser", ctx, mock.Anything).Return(entity.User{}, expectedError)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(0), mock.Anything).Return(entity.User{}, expectedError)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserUpdateResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -165,14 +204,14 @@ func TestUpdateUserFailGetUserByIdError(t *testing.T) {
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, expectedError)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
 
 	// Then
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.ErrorIs(t, err, expectedError)
 	assert.Equal(t, dto.UserUpdateResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
@@ -187,11 +226,11 @@ func TestUpdateUserFailGetUserByIdAndUserEmpty(t *testing.T) {
 		Email: "updated@example.com",
 	}
 
-	expectedError := fmt.Errorf("user with id %s not found", userID)
+	expectedError := apperror.NotFound(apperror.CodeUserNotFound, fmt.Sprintf("user with id %s not found", userID)).WithParam("id", userID)
 
 	mockUserRepository.On("GetUserById", ctx, userID).Return(entity.User{}, nil)
 
-	userService := service.NewUserService(mockUserRepository)
+	userService := newUserService(t, mockUserRepository)
 
 	// When
 	resp, err := userService.UpdateUser(ctx, userID, req)
@@ -202,3 +241,176 @@ func TestUpdateUserFailGetUserByIdAndUserEmpty(t *testing.T) {
 	assert.Equal(t, dto.UserUpdateResponse{}, resp)
 	mockUserRepository.AssertExpectations(t)
 }
+
+func TestUpdateUserSuccessIfMatch(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:    "Updated Name",
+		Email:   "original@example.com",
+		IfMatch: `"3"`,
+	}
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Original Name",
+		Email:   "original@example.com",
+		Version: 3,
+	}
+	updatedUserEntity := entity.User{
+		ID:      objectID,
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: 4,
+	}
+	expectedResponse := dto.UserUpdateResponse{
+		ID:      userID,
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: 4,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(3), entity.UserUpdate{Name: &req.Name}).Return(updatedUserEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, resp)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestUpdateUserFailPreconditionFailed(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:    "Updated Name",
+		Email:   "updated@example.com",
+		IfMatch: `"2"`,
+	}
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Original Name",
+		Email:   "original@example.com",
+		Version: 3,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+	mockUserRepository.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserAcceptsIfMatchOfAnyRepresentation(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:    "Updated Name",
+		Email:   "original@example.com",
+		IfMatch: `"3-msgpack"`,
+	}
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Original Name",
+		Email:   "original@example.com",
+		Version: 3,
+	}
+	updatedUserEntity := userEntity
+	updatedUserEntity.Name = req.Name
+	updatedUserEntity.Version = 4
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(3), entity.UserUpdate{Name: &req.Name}).Return(updatedUserEntity, nil)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), resp.Version)
+}
+
+func TestUpdateUserFailConcurrentModification(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:  "Updated Name",
+		Email: "updated@example.com",
+	}
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Original Name",
+		Email:   "original@example.com",
+		Version: 3,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(3), mock.Anything).Return(entity.User{}, repository.ErrVersionConflict)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	assert.ErrorIs(t, err, service.ErrConcurrentModification)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+	mockUserRepository.AssertExpectations(t)
+}
+
+func TestUpdateUserFailUserDeletedBeforeUpdate(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockUserRepository := mock_user_repository.NewUserRepository(t)
+	userID := "683ecde861d005de5ec0907d"
+	objectID, _ := bson.ObjectIDFromHex(userID)
+	req := dto.UserUpdateRequest{
+		Name:  "Updated Name",
+		Email: "updated@example.com",
+	}
+	userEntity := entity.User{
+		ID:      objectID,
+		Name:    "Original Name",
+		Email:   "original@example.com",
+		Version: 3,
+	}
+
+	mockUserRepository.On("GetUserById", ctx, userID).Return(userEntity, nil)
+	mockUserRepository.On("UpdateUser", ctx, userID, int64(3), mock.Anything).Return(entity.User{}, repository.ErrNotFound)
+
+	userService := newUserService(t, mockUserRepository)
+
+	// When
+	resp, err := userService.UpdateUser(ctx, userID, req)
+
+	// Then
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperror.CodeUserNotFound, appErr.Code)
+	assert.Equal(t, dto.UserUpdateResponse{}, resp)
+	mockUserRepository.AssertExpectations(t)
+}
@@ -0,0 +1,384 @@
+package test
+
+import (
+	"context"
+	encodingjson "encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/taninchot-work/backend-challenge/internal/controller"
+	"github.com/taninchot-work/backend-challenge/internal/core/apperror"
+	"github.com/taninchot-work/backend-challenge/internal/core/webhook"
+	"github.com/taninchot-work/backend-challenge/internal/dto"
+	"github.com/taninchot-work/backend-challenge/internal/entity"
+	"github.com/taninchot-work/backend-challenge/internal/repository"
+	mock_webhook_repository "github.com/taninchot-work/backend-challenge/internal/repository/mocks/webhook_repository_mock"
+	"github.com/taninchot-work/backend-challenge/internal/service"
+	mock_webhook_service "github.com/taninchot-work/backend-challenge/internal/service/mocks/webhook_service_mock"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const webhookSecret = "test-webhook-secret"
+
+func TestWebhookSignature(t *testing.T) {
+	// Given
+	body := []byte(`{"id":"1"}`)
+	now := time.Unix(1700000000, 0)
+	signature := webhook.Sign(webhookSecret, now, body)
+	timestamp := "1700000000"
+
+	// Then
+	assert.True(t, strings.HasPrefix(signature, "v1="))
+	assert.True(t, webhook.Verify(webhookSecret, signature, timestamp, body, 5*time.Minute, now.Add(time.Minute)))
+	assert.False(t, webhook.Verify("other-secret", signature, timestamp, body, 5*time.Minute, now))
+	assert.False(t, webhook.Verify(webhookSecret, signature, timestamp, []byte(`{"id":"2"}`), 5*time.Minute, now))
+	assert.False(t, webhook.Verify(webhookSecret, signature, timestamp, body, 5*time.Minute, now.Add(10*time.Minute)))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, webhook.Backoff(1, time.Second, time.Minute))
+	assert.Equal(t, 2*time.Second, webhook.Backoff(2, time.Second, time.Minute))
+	assert.Equal(t, 8*time.Second, webhook.Backoff(4, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, webhook.Backoff(20, time.Second, time.Minute))
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	// Given
+	received := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+	client := webhook.NewClient(time.Second, false)
+
+	// When
+	_, err := client.Post(receiver.URL, "application/json", strings.NewReader(`{}`))
+
+	// Then
+	assert.ErrorIs(t, err, webhook.ErrPrivateAddress)
+	assert.False(t, received)
+}
+
+func TestWebhookClientDoesNotFollowRedirects(t *testing.T) {
+	// Given
+	redirected := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+	client := webhook.NewClient(time.Second, true)
+
+	// When
+	response, err := client.Post(receiver.URL+"/hooks", "application/json", strings.NewReader(`{}`))
+
+	// Then
+	if assert.NoError(t, err) {
+		response.Body.Close()
+		assert.Equal(t, http.StatusTemporaryRedirect, response.StatusCode)
+	}
+	assert.False(t, redirected)
+}
+
+func TestPublishQueuesDeliveryPerWebhook(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	webhooks := []entity.Webhook{{ID: bson.NewObjectID()}, {ID: bson.NewObjectID()}}
+	event := entity.Event{ID: "event-1", Type: entity.EventUserCreated, Data: entity.UserSnapshot{ID: "683ecde861d005de5ec0907d"}}
+	mockWebhookRepository.On("GetWebhooksByEvent", ctx, entity.EventUserCreated).Return(webhooks, nil)
+	mockWebhookRepository.On("SaveDeliveries", ctx, mock.MatchedBy(func(deliveries []entity.WebhookDelivery) bool {
+		if len(deliveries) != 2 {
+			return false
+		}
+		for i, delivery := range deliveries {
+			var payload entity.Event
+			if encodingjson.Unmarshal(delivery.Payload, &payload) != nil || payload.ID != "event-1" {
+				return false
+			}
+			if delivery.WebhookID != webhooks[i].ID || delivery.EventID != "event-1" || delivery.Status != entity.DeliveryStatusPending {
+				return false
+			}
+		}
+		return true
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{})
+
+	// When
+	err := webhookService.Publish(ctx, event)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestDeliverDueSendsSignedDelivery(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	hook := entity.Webhook{ID: bson.NewObjectID(), URL: receiver.URL, Secret: webhookSecret}
+	delivery := newPendingDelivery(hook.ID, 0)
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, 20*time.Second).Return(delivery, nil).Once()
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, 20*time.Second).Return(entity.WebhookDelivery{}, repository.ErrNotFound).Once()
+	mockWebhookRepository.On("GetWebhookById", ctx, hook.ID.Hex()).Return(hook, nil)
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryStatusSucceeded && d.Attempts == 1 && len(d.History) == 1 && d.History[0].StatusCode == http.StatusNoContent
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{AllowPrivateNetworks: true})
+
+	// When
+	sent, err := webhookService.DeliverDue(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, delivery.Payload, receivedBody)
+		assert.Equal(t, entity.EventUserCreated, received.Header.Get(webhook.HeaderEvent))
+		assert.Equal(t, "event-1", received.Header.Get(webhook.HeaderEventID))
+		assert.Equal(t, delivery.ID.Hex(), received.Header.Get(webhook.HeaderDelivery))
+		assert.True(t, webhook.Verify(webhookSecret, received.Header.Get(webhook.HeaderSignature), received.Header.Get(webhook.HeaderTimestamp), receivedBody, time.Minute, time.Now()))
+	}
+}
+
+func TestDeliverDueRetriesWithBackoff(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	hook := entity.Webhook{ID: bson.NewObjectID(), URL: receiver.URL, Secret: webhookSecret}
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(newPendingDelivery(hook.ID, 2), nil).Once()
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(entity.WebhookDelivery{}, repository.ErrNotFound).Once()
+	mockWebhookRepository.On("GetWebhookById", ctx, hook.ID.Hex()).Return(hook, nil)
+	start := time.Now()
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		// the third failed attempt waits 4 times the initial backoff
+		delay := d.NextAttemptAt.Sub(start)
+		return d.Status == entity.DeliveryStatusPending && d.Attempts == 3 &&
+			d.History[0].Error == "unexpected status 503" && delay >= 4*time.Minute && delay < 4*time.Minute+5*time.Second
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{AllowPrivateNetworks: true, MaxAttempts: 5, InitialBackoff: time.Minute})
+
+	// When
+	sent, err := webhookService.DeliverDue(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestDeliverDueFailsForPrivateAddress(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	hook := entity.Webhook{ID: bson.NewObjectID(), URL: receiver.URL, Secret: webhookSecret}
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(newPendingDelivery(hook.ID, 0), nil).Once()
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(entity.WebhookDelivery{}, repository.ErrNotFound).Once()
+	mockWebhookRepository.On("GetWebhookById", ctx, hook.ID.Hex()).Return(hook, nil)
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryStatusPending && d.Attempts == 1 &&
+			strings.Contains(d.History[0].Error, webhook.ErrPrivateAddress.Error())
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{})
+
+	// When
+	sent, err := webhookService.DeliverDue(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestDeliverDueDeadLettersAfterMaxAttempts(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	hook := entity.Webhook{ID: bson.NewObjectID(), URL: receiver.URL, Secret: webhookSecret}
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(newPendingDelivery(hook.ID, 4), nil).Once()
+	mockWebhookRepository.On("GetWebhookById", ctx, hook.ID.Hex()).Return(hook, nil)
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryStatusDead && d.Attempts == 5
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{AllowPrivateNetworks: true, MaxAttempts: 5, BatchSize: 1})
+
+	// When
+	sent, err := webhookService.DeliverDue(ctx)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestDeliverDueDeadLettersDeletedWebhook(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	webhookID := bson.NewObjectID()
+	mockWebhookRepository.On("ClaimDueDelivery", ctx, mock.Anything, mock.Anything).Return(newPendingDelivery(webhookID, 0), nil).Once()
+	mockWebhookRepository.On("GetWebhookById", ctx, webhookID.Hex()).Return(entity.Webhook{}, repository.ErrNotFound)
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryStatusDead && d.History[0].Error == "webhook was deleted"
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{BatchSize: 1})
+
+	// When
+	_, err := webhookService.DeliverDue(ctx)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestRedeliverDeadDelivery(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	hook := entity.Webhook{ID: bson.NewObjectID()}
+	delivery := newPendingDelivery(hook.ID, 8)
+	delivery.Status = entity.DeliveryStatusDead
+	delivery.History = []entity.WebhookAttempt{{At: time.Now(), StatusCode: 500, Error: "unexpected status 500"}}
+	mockWebhookRepository.On("GetDeliveryById", ctx, delivery.ID.Hex()).Return(delivery, nil)
+	mockWebhookRepository.On("GetWebhookById", ctx, hook.ID.Hex()).Return(hook, nil)
+	mockWebhookRepository.On("UpdateDelivery", ctx, mock.MatchedBy(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryStatusPending && d.Attempts == 0 && len(d.History) == 1
+	})).Return(nil)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{})
+
+	// When
+	resp, err := webhookService.RedeliverDelivery(ctx, delivery.ID.Hex())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, entity.DeliveryStatusPending, resp.Status)
+	assert.NotNil(t, resp.NextAttemptAt)
+	assert.Equal(t, 500, resp.History[0].StatusCode)
+}
+
+func TestRedeliverUnknownDelivery(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	mockWebhookRepository.On("GetDeliveryById", ctx, "unknown").Return(entity.WebhookDelivery{}, repository.ErrNotFound)
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{})
+
+	// When
+	_, err := webhookService.RedeliverDelivery(ctx, "unknown")
+
+	// Then
+	assert.Equal(t, apperror.CodeDeliveryNotFound, apperror.From(err).Code)
+}
+
+func TestCreateWebhookGeneratesSecret(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	mockWebhookRepository := mock_webhook_repository.NewWebhookRepository(t)
+	mockWebhookRepository.On("SaveWebhook", ctx, mock.MatchedBy(func(hook entity.Webhook) bool {
+		return strings.HasPrefix(hook.Secret, "whsec_") && hook.URL == "https://example.com/hooks"
+	})).Return(func(ctx context.Context, hook entity.Webhook) (entity.Webhook, error) {
+		return hook, nil
+	})
+	webhookService := service.NewWebhookService(mockWebhookRepository, service.WebhookOptions{})
+
+	// When
+	resp, err := webhookService.CreateWebhook(ctx, dto.WebhookCreateRequest{URL: "https://example.com/hooks", Events: []string{entity.EventUserCreated}})
+
+	// Then
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Secret, "whsec_"))
+	assert.Equal(t, []string{entity.EventUserCreated}, resp.Events)
+}
+
+func TestWebhookCreateRejectsUnknownEvent(t *testing.T) {
+	// Given
+	mockWebhookService := mock_webhook_service.NewWebhookService(t)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url":"ftp://example.com","events":["user.created","user.banned"]}`))
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewWebhookController(mockWebhookService).WebhookCreate(w, r)
+
+	// Then
+	var problem struct {
+		Errors []apperror.FieldError `json:"errors"`
+	}
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, encodingjson.NewDecoder(w.Body).Decode(&problem))
+	fields := map[string]string{}
+	for _, field := range problem.Errors {
+		fields[field.Field] = field.Code
+	}
+	assert.Equal(t, map[string]string{"url": apperror.CodeFieldInvalid, "events[1]": apperror.CodeFieldNotAllowed}, fields)
+	mockWebhookService.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func TestDeliveryListGetReadsQuery(t *testing.T) {
+	// Given
+	mockWebhookService := mock_webhook_service.NewWebhookService(t)
+	mockWebhookService.On("GetDeliveryList", mock.Anything, dto.WebhookDeliveryListRequest{Status: entity.DeliveryStatusDead, Page: 2, Limit: 20}).
+		Return(dto.WebhookDeliveryListResponse{Deliveries: []dto.WebhookDeliveryResponse{}, Page: 2}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries?status=dead&page=2", nil)
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewWebhookController(mockWebhookService).DeliveryListGet(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDeliveryListGetRejectsInvalidQuery(t *testing.T) {
+	// Given
+	mockWebhookService := mock_webhook_service.NewWebhookService(t)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/deliveries?limit=abc", nil)
+	w := httptest.NewRecorder()
+
+	// When
+	controller.NewWebhookController(mockWebhookService).DeliveryListGet(w, r)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockWebhookService.AssertNotCalled(t, "GetDeliveryList", mock.Anything, mock.Anything)
+}
+
+func newPendingDelivery(webhookID bson.ObjectID, attempts int) entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ID:            bson.NewObjectID(),
+		WebhookID:     webhookID,
+		EventID:       "event-1",
+		EventType:     entity.EventUserCreated,
+		Payload:       []byte(`{"id":"event-1","type":"user.created"}`),
+		Status:        entity.DeliveryStatusPending,
+		Attempts:      attempts,
+		NextAttemptAt: time.Now(),
+		History:       []entity.WebhookAttempt{},
+	}
+}